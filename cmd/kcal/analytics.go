@@ -77,6 +77,86 @@ var analyticsRangeCmd = &cobra.Command{
 	},
 }
 
+var (
+	exerciseAnalyticsFrom string
+	exerciseAnalyticsTo   string
+	exerciseAnalyticsJSON bool
+)
+
+var analyticsExerciseCmd = &cobra.Command{
+	Use:   "exercise",
+	Short: "Exercise breakdown by type with distance and pace trends",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if exerciseAnalyticsFrom == "" || exerciseAnalyticsTo == "" {
+			return fmt.Errorf("--from and --to are required")
+		}
+		start, err := time.ParseInLocation("2006-01-02", exerciseAnalyticsFrom, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid --from date (expected YYYY-MM-DD)")
+		}
+		end, err := time.ParseInLocation("2006-01-02", exerciseAnalyticsTo, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid --to date (expected YYYY-MM-DD)")
+		}
+		return withDB(func(sqldb *sql.DB) error {
+			report, err := service.AnalyticsExercise(sqldb, start, end)
+			if err != nil {
+				return err
+			}
+			if exerciseAnalyticsJSON {
+				b, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return fmt.Errorf("marshal exercise analytics json: %w", err)
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(b))
+				return nil
+			}
+			printExerciseAnalytics(cmd.OutOrStdout(), report)
+			return nil
+		})
+	},
+}
+
+var (
+	nutrientAnalyticsFrom string
+	nutrientAnalyticsTo   string
+	nutrientAnalyticsJSON bool
+)
+
+var analyticsNutrientsCmd = &cobra.Command{
+	Use:   "nutrients",
+	Short: "Micronutrient intake compared with reference daily intakes",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if nutrientAnalyticsFrom == "" || nutrientAnalyticsTo == "" {
+			return fmt.Errorf("--from and --to are required")
+		}
+		start, err := time.ParseInLocation("2006-01-02", nutrientAnalyticsFrom, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid --from date (expected YYYY-MM-DD)")
+		}
+		end, err := time.ParseInLocation("2006-01-02", nutrientAnalyticsTo, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid --to date (expected YYYY-MM-DD)")
+		}
+		return withDB(func(sqldb *sql.DB) error {
+			report, err := service.AnalyticsNutrients(sqldb, start, end)
+			if err != nil {
+				return err
+			}
+			if nutrientAnalyticsJSON {
+				b, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return fmt.Errorf("marshal nutrient analytics json: %w", err)
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(b))
+				return nil
+			}
+			printNutrientAnalytics(cmd.OutOrStdout(), report)
+			return nil
+		})
+	},
+}
+
 var analyticsInsightsCmd = &cobra.Command{
 	Use:   "insights",
 	Short: "Premium-style insights with trends and charts",
@@ -201,6 +281,9 @@ func runAnalyticsInsights(cmd *cobra.Command, from, to time.Time) error {
 func printAnalyticsTable(cmd *cobra.Command, r *service.AnalyticsReport) {
 	out := cmd.OutOrStdout()
 	fmt.Fprintf(out, "Range: %s to %s\n", r.FromDate, r.ToDate)
+	if r.Phase != nil {
+		fmt.Fprintf(out, "Phase: %s\n", formatPhaseStatus(r.Phase))
+	}
 	fmt.Fprintf(out, "Totals: intake=%d exercise=%d net=%d P=%.1f C=%.1f F=%.1f\n", r.TotalIntakeCalories, r.TotalExerciseCalories, r.TotalNetCalories, r.TotalProtein, r.TotalCarbs, r.TotalFat)
 	fmt.Fprintf(out, "Averages/day: intake=%.1f exercise=%.1f net=%.1f P=%.1f C=%.1f F=%.1f\n", r.AverageIntakeCaloriesPerDay, r.AverageExerciseCaloriesPerDay, r.AverageNetCaloriesPerDay, r.AverageProteinPerDay, r.AverageCarbsPerDay, r.AverageFatPerDay)
 	if r.HighestDay != nil && r.LowestDay != nil {
@@ -208,6 +291,15 @@ func printAnalyticsTable(cmd *cobra.Command, r *service.AnalyticsReport) {
 		fmt.Fprintf(out, "Lowest day: %s (net %d kcal)\n", r.LowestDay.Date, r.LowestDay.NetCalories)
 	}
 	fmt.Fprintf(out, "Adherence: %d/%d days within goals (%.1f%%), %d days without goal\n", r.Adherence.WithinGoalDays, r.Adherence.EvaluatedDays, r.Adherence.PercentWithin, r.Adherence.SkippedGoalDays)
+	fmt.Fprintf(out, "Exercise eat-back: %s\n", r.Adherence.EatBackPolicy)
+	if r.WeeklyAdherence.EvaluatedWeeks > 0 {
+		fmt.Fprintf(out, "Weekly adherence: %d/%d weeks within calorie budget (%.1f%%)\n", r.WeeklyAdherence.WithinBudgetWeeks, r.WeeklyAdherence.EvaluatedWeeks, r.WeeklyAdherence.PercentWithin)
+		fmt.Fprintln(out, "\nWeekly Budget")
+		fmt.Fprintln(out, "WEEK\tDAYS\tBUDGET\tINTAKE\tBALANCE")
+		for _, w := range r.WeeklyAdherence.Weeks {
+			fmt.Fprintf(out, "%s\t%d\t%d\t%d\t%+d\n", w.WeekStart, w.EvaluatedDays, w.BudgetCalories, w.IntakeCalories, w.BalanceCalories)
+		}
+	}
 
 	fmt.Fprintln(out, "\nBy Category")
 	fmt.Fprintln(out, "CATEGORY\tKCAL\tP\tC\tF")
@@ -232,9 +324,10 @@ func printAnalyticsTable(cmd *cobra.Command, r *service.AnalyticsReport) {
 	fmt.Fprintln(out, "\nBody")
 	fmt.Fprintf(out, "Measurements: %d\n", r.Body.MeasurementsCount)
 	if r.Body.MeasurementsCount > 0 {
-		fmt.Fprintf(out, "Weight: start=%.2fkg end=%.2fkg change=%.2fkg\n", r.Body.StartWeightKg, r.Body.EndWeightKg, r.Body.WeightChangeKg)
+		fmt.Fprintf(out, "Weight: start=%.2fkg end=%.2fkg\n", r.Body.StartWeightKg, r.Body.EndWeightKg)
+		fmt.Fprintf(out, "Trend weight: start=%.2fkg end=%.2fkg change=%.2fkg\n", r.Body.StartTrendWeightKg, r.Body.EndTrendWeightKg, r.Body.WeightChangeKg)
 		if r.Body.AvgWeeklyChangeKg != 0 {
-			fmt.Fprintf(out, "Avg weekly weight change: %.2fkg\n", r.Body.AvgWeeklyChangeKg)
+			fmt.Fprintf(out, "Avg weekly trend change: %.2fkg\n", r.Body.AvgWeeklyChangeKg)
 		}
 		if r.Body.StartBodyFatPct != nil && r.Body.EndBodyFatPct != nil {
 			fmt.Fprintf(out, "Body fat: start=%.2f%% end=%.2f%% change=%.2f%%\n", *r.Body.StartBodyFatPct, *r.Body.EndBodyFatPct, *r.Body.BodyFatChangePct)
@@ -242,10 +335,132 @@ func printAnalyticsTable(cmd *cobra.Command, r *service.AnalyticsReport) {
 		if r.Body.StartLeanMassKg != nil && r.Body.EndLeanMassKg != nil {
 			fmt.Fprintf(out, "Lean mass: start=%.2fkg end=%.2fkg change=%.2fkg\n", *r.Body.StartLeanMassKg, *r.Body.EndLeanMassKg, *r.Body.LeanMassChangeKg)
 		}
+		if start, end := r.Body.StartMetrics, r.Body.EndMetrics; start != nil && end != nil {
+			fmt.Fprintf(out, "BMI: start=%.1f end=%.1f\n", *start.BMI, *end.BMI)
+			if start.FFMI != nil && end.FFMI != nil {
+				fmt.Fprintf(out, "FFMI: start=%.1f end=%.1f (normalized %.1f -> %.1f)\n", *start.FFMI, *end.FFMI, *start.NormalizedFFMI, *end.NormalizedFFMI)
+			}
+			if end.WaistToHeight != nil {
+				fmt.Fprintf(out, "Waist-to-height: %.3f\n", *end.WaistToHeight)
+			}
+		}
+		for _, c := range r.Body.Circumferences {
+			fmt.Fprintf(out, "%s: start=%.1fcm end=%.1fcm change=%.1fcm (n=%d)\n", strings.ToUpper(c.Site[:1])+c.Site[1:], c.StartCm, c.EndCm, c.ChangeCm, c.Measurements)
+		}
 		if r.Body.GoalProgress != nil {
 			fmt.Fprintf(out, "Body goal progress: target %.2fkg, latest %.2fkg (delta %.2fkg)\n", r.Body.GoalProgress.TargetWeightKg, r.Body.GoalProgress.LatestWeightKg, r.Body.GoalProgress.WeightDeltaKg)
 		}
+		if f := r.Body.Forecast; f != nil {
+			fmt.Fprintf(out, "Body goal forecast: %s\n", formatForecastSummary(f))
+		}
+	}
+
+	if e := r.Exercise; e.Sessions > 0 {
+		fmt.Fprintln(out, "\nExercise")
+		fmt.Fprintf(out, "Sessions: %d kcal=%d duration=%dmin distance=%.2fkm\n", e.Sessions, e.Calories, e.DurationMin, e.DistanceKm)
+		for _, t := range e.ByType {
+			fmt.Fprintf(out, "%s: sessions=%d kcal=%d duration=%dmin distance=%.2fkm%s\n", t.ExerciseType, t.Sessions, t.Calories, t.DurationMin, t.DistanceKm, formatPaceSuffix(t.AvgPaceMinPerKm))
+		}
+	}
+
+	if s := r.Strength; s.Sessions > 0 {
+		fmt.Fprintln(out, "\nStrength")
+		fmt.Fprintf(out, "Sessions: %d sets=%d reps=%d volume=%.0fkg\n", s.Sessions, s.Sets, s.Reps, s.VolumeKg)
+		for _, g := range s.MuscleGroups {
+			fmt.Fprintf(out, "%s: sets=%d (%.1f/week) volume=%.0fkg\n", g.MuscleGroup, g.Sets, g.WeeklySets, g.VolumeKg)
+		}
+		for _, l := range s.Lifts {
+			fmt.Fprintf(out, "%s e1RM: start=%.1fkg end=%.1fkg best=%.1fkg change=%+.1fkg (n=%d)\n", l.Lift, l.StartE1RMKg, l.EndE1RMKg, l.BestE1RMKg, l.ChangeKg, l.Sessions)
+		}
+	}
+}
+
+func printExerciseAnalytics(out anyWriter, r *service.ExerciseAnalyticsReport) {
+	fmt.Fprintf(out, "Range: %s to %s\n", r.FromDate, r.ToDate)
+	fmt.Fprintf(out, "Totals: sessions=%d kcal=%d duration=%dmin distance=%.2fkm\n", r.Sessions, r.Calories, r.DurationMin, r.DistanceKm)
+	if r.Sessions == 0 {
+		return
+	}
+
+	fmt.Fprintln(out, "\nBy Type")
+	fmt.Fprintln(out, "TYPE\tSESSIONS\tKCAL\tDURATION_MIN\tDISTANCE_KM\tAVG_PACE")
+	for _, t := range r.ByType {
+		fmt.Fprintf(out, "%s\t%d\t%d\t%d\t%.2f\t%s\n", t.ExerciseType, t.Sessions, t.Calories, t.DurationMin, t.DistanceKm, formatPace(t.AvgPaceMinPerKm))
+	}
+
+	fmt.Fprintln(out, "\nWeekly")
+	fmt.Fprintln(out, "WEEK\tSESSIONS\tKCAL\tDURATION_MIN\tDISTANCE_KM\tBY_TYPE")
+	for _, w := range r.Weeks {
+		parts := make([]string, 0, len(w.ByType))
+		for _, t := range w.ByType {
+			part := t.ExerciseType
+			if t.DistanceKm > 0 {
+				part += fmt.Sprintf(" %.2fkm", t.DistanceKm)
+			}
+			if t.AvgPaceMinPerKm != nil {
+				part += " @" + formatPace(t.AvgPaceMinPerKm)
+			}
+			parts = append(parts, part)
+		}
+		fmt.Fprintf(out, "%s\t%d\t%d\t%d\t%.2f\t%s\n", w.WeekStart, w.Sessions, w.Calories, w.DurationMin, w.DistanceKm, strings.Join(parts, ", "))
+	}
+
+	fmt.Fprintln(out, "\nLongest Sessions")
+	fmt.Fprintln(out, "ID\tDATE\tTYPE\tDURATION_MIN\tDISTANCE_KM\tPACE\tKCAL")
+	for _, s := range r.LongestSessions {
+		fmt.Fprintf(out, "%d\t%s\t%s\t%d\t%s\t%s\t%d\n", s.ID, s.Date, s.ExerciseType, s.DurationMin, formatOptional(s.DistanceKm, "%.2f"), formatPace(s.PaceMinPerKm), s.Calories)
+	}
+}
+
+func printNutrientAnalytics(out anyWriter, r *service.NutrientAnalyticsReport) {
+	fmt.Fprintf(out, "Range: %s to %s\n", r.FromDate, r.ToDate)
+	if r.ReferenceGroup != "" {
+		fmt.Fprintf(out, "Reference intakes: %s\n", r.ReferenceGroup)
+	}
+	fmt.Fprintf(out, "Logged days: %d\n", r.LoggedDays)
+	for _, w := range r.Warnings {
+		fmt.Fprintf(out, "warning: %s\n", w)
+	}
+	if len(r.Nutrients) == 0 {
+		fmt.Fprintln(out, "No micronutrient data in range")
+		return
+	}
+
+	fmt.Fprintln(out, "\nNUTRIENT\tUNIT\tAVG/DAY\tDAYS\tTARGET\tPCT_TARGET\tUL\tBELOW\tOVER_UL\tSTATUS")
+	for _, n := range r.Nutrients {
+		target, ul := "", ""
+		if n.Reference != nil {
+			target = fmt.Sprintf("%g %s", n.Reference.Target, strings.ToUpper(n.Reference.Kind))
+			ul = formatOptional(n.Reference.UL, "%g")
+		}
+		pct := formatOptional(n.PercentOfTarget, "%.0f%%")
+		fmt.Fprintf(out, "%s\t%s\t%.2f\t%d\t%s\t%s\t%s\t%d\t%d\t%s\n", n.Nutrient, n.Unit, n.AvgPerDay, n.DaysWithData, target, pct, ul, n.DaysBelowTarget, n.DaysAboveUL, n.Status)
+	}
+
+	for _, n := range r.Nutrients {
+		switch n.Status {
+		case service.NutrientStatusShortfall:
+			fmt.Fprintf(out, "Chronic shortfall: %s avg %.2f%s/day is %.0f%% of the %s (%g%s), below on %d/%d days with data (data on %d/%d logged days)\n", n.Nutrient, n.AvgPerDay, n.Unit, *n.PercentOfTarget, strings.ToUpper(n.Reference.Kind), n.Reference.Target, n.Unit, n.DaysBelowTarget, n.DaysWithData, n.DaysWithData, r.LoggedDays)
+		case service.NutrientStatusExcess:
+			fmt.Fprintf(out, "Chronic excess: %s avg %.2f%s/day, above the upper level (%g%s) on %d/%d days with data (data on %d/%d logged days)\n", n.Nutrient, n.AvgPerDay, n.Unit, *n.Reference.UL, n.Unit, n.DaysAboveUL, n.DaysWithData, n.DaysWithData, r.LoggedDays)
+		}
+	}
+}
+
+// formatPace renders minutes per km as m:ss/km.
+func formatPace(minPerKm *float64) string {
+	if minPerKm == nil {
+		return ""
+	}
+	seconds := int(math.Round(*minPerKm * 60))
+	return fmt.Sprintf("%d:%02d/km", seconds/60, seconds%60)
+}
+
+func formatPaceSuffix(minPerKm *float64) string {
+	if minPerKm == nil {
+		return ""
 	}
+	return " pace=" + formatPace(minPerKm)
 }
 
 func printAnalyticsInsightsTable(out anyWriter, r *service.InsightsReport, noCharts bool) {
@@ -270,6 +485,7 @@ func printAnalyticsInsightsTable(out anyWriter, r *service.InsightsReport, noCha
 
 	fmt.Fprintln(out, "\nAdherence + Activity")
 	fmt.Fprintf(out, "Goal adherence: %d/%d (%.1f%%, %s)\n", r.Current.Adherence.WithinGoalDays, r.Current.Adherence.EvaluatedDays, r.Current.Adherence.PercentWithin, formatDelta(r.Deltas.AdherencePercent, "pp"))
+	fmt.Fprintf(out, "Exercise eat-back: %s\n", r.Current.Adherence.EatBackPolicy)
 	fmt.Fprintf(out, "Intake active days: %d/%d (%.1f%%)\n", r.Current.IntakeActiveDays, r.Current.TotalDays, r.Current.IntakeActiveRate*100)
 	fmt.Fprintf(out, "Exercise active days: %d/%d (%.1f%%)\n", r.Current.ExerciseActiveDays, r.Current.TotalDays, r.Current.ExerciseActiveRate*100)
 
@@ -300,6 +516,7 @@ func printAnalyticsInsightsTable(out anyWriter, r *service.InsightsReport, noCha
 	fmt.Fprintf(out, "Logging streak: current=%d longest=%d\n", r.Streaks.Logging.Current, r.Streaks.Logging.Longest)
 	fmt.Fprintf(out, "Exercise streak: current=%d longest=%d\n", r.Streaks.Exercise.Current, r.Streaks.Exercise.Longest)
 	fmt.Fprintf(out, "Within-goal streak: current=%d longest=%d\n", r.Streaks.WithinGoal.Current, r.Streaks.WithinGoal.Longest)
+	fmt.Fprintf(out, "Steps streak: current=%d longest=%d%s\n", r.Streaks.Steps.Current, r.Streaks.Steps.Longest, formatStepsGoal(r.Streaks.StepsGoal))
 
 	fmt.Fprintln(out, "\nRolling Windows")
 	if r.RollingWindows.Window7.Latest != nil {
@@ -473,7 +690,8 @@ func renderAnalyticsInsightsMarkdown(r *service.InsightsReport) string {
 	fmt.Fprintf(&b, "## Streaks\n")
 	fmt.Fprintf(&b, "- Logging: current=%d, longest=%d\n", r.Streaks.Logging.Current, r.Streaks.Logging.Longest)
 	fmt.Fprintf(&b, "- Exercise: current=%d, longest=%d\n", r.Streaks.Exercise.Current, r.Streaks.Exercise.Longest)
-	fmt.Fprintf(&b, "- Within-goal: current=%d, longest=%d\n\n", r.Streaks.WithinGoal.Current, r.Streaks.WithinGoal.Longest)
+	fmt.Fprintf(&b, "- Within-goal: current=%d, longest=%d\n", r.Streaks.WithinGoal.Current, r.Streaks.WithinGoal.Longest)
+	fmt.Fprintf(&b, "- Steps: current=%d, longest=%d%s\n\n", r.Streaks.Steps.Current, r.Streaks.Steps.Longest, formatStepsGoal(r.Streaks.StepsGoal))
 
 	fmt.Fprintf(&b, "## Rolling Windows\n")
 	if r.RollingWindows.Window7.Latest != nil {
@@ -582,7 +800,7 @@ func weeksInISOYear(year int) int {
 
 func init() {
 	rootCmd.AddCommand(analyticsCmd)
-	analyticsCmd.AddCommand(analyticsWeekCmd, analyticsMonthCmd, analyticsRangeCmd, analyticsInsightsCmd)
+	analyticsCmd.AddCommand(analyticsWeekCmd, analyticsMonthCmd, analyticsRangeCmd, analyticsExerciseCmd, analyticsNutrientsCmd, analyticsInsightsCmd)
 	analyticsInsightsCmd.AddCommand(analyticsInsightsWeekCmd, analyticsInsightsMonthCmd, analyticsInsightsRangeCmd)
 
 	for _, c := range []*cobra.Command{analyticsWeekCmd, analyticsMonthCmd, analyticsRangeCmd} {
@@ -593,6 +811,12 @@ func init() {
 	analyticsMonthCmd.Flags().StringVar(&monthArg, "month", "", "Month in format YYYY-MM")
 	analyticsRangeCmd.Flags().StringVar(&rangeFrom, "from", "", "Start date YYYY-MM-DD")
 	analyticsRangeCmd.Flags().StringVar(&rangeTo, "to", "", "End date YYYY-MM-DD")
+	analyticsExerciseCmd.Flags().StringVar(&exerciseAnalyticsFrom, "from", "", "Start date YYYY-MM-DD")
+	analyticsExerciseCmd.Flags().StringVar(&exerciseAnalyticsTo, "to", "", "End date YYYY-MM-DD")
+	analyticsExerciseCmd.Flags().BoolVar(&exerciseAnalyticsJSON, "json", false, "Output as JSON")
+	analyticsNutrientsCmd.Flags().StringVar(&nutrientAnalyticsFrom, "from", "", "Start date YYYY-MM-DD")
+	analyticsNutrientsCmd.Flags().StringVar(&nutrientAnalyticsTo, "to", "", "End date YYYY-MM-DD")
+	analyticsNutrientsCmd.Flags().BoolVar(&nutrientAnalyticsJSON, "json", false, "Output as JSON")
 
 	for _, c := range []*cobra.Command{analyticsInsightsWeekCmd, analyticsInsightsMonthCmd, analyticsInsightsRangeCmd} {
 		c.Flags().BoolVar(&insightsJSON, "json", false, "Output as JSON")
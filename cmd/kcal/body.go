@@ -2,7 +2,11 @@ package kcal
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/saadjs/kcal-cli/internal/service"
 	"github.com/spf13/cobra"
@@ -10,7 +14,7 @@ import (
 
 var bodyCmd = &cobra.Command{
 	Use:   "body",
-	Short: "Manage body measurements (weight and body-fat)",
+	Short: "Manage body measurements (weight, body-fat, and circumferences)",
 }
 
 var (
@@ -20,6 +24,14 @@ var (
 	bodyDate   string
 	bodyTime   string
 	bodyNotes  string
+
+	bodyLengthUnit string
+	bodyWaist      float64
+	bodyHip        float64
+	bodyNeck       float64
+	bodyChest      float64
+	bodyArm        float64
+	bodyThigh      float64
 )
 
 var bodyAddCmd = &cobra.Command{
@@ -31,11 +43,13 @@ var bodyAddCmd = &cobra.Command{
 			return err
 		}
 		in := service.BodyMeasurementInput{
-			Weight:     bodyWeight,
-			Unit:       bodyUnit,
-			BodyFatPct: optionalBodyFat(bodyFat),
-			MeasuredAt: measuredAt,
-			Notes:      bodyNotes,
+			Weight:         bodyWeight,
+			Unit:           bodyUnit,
+			BodyFatPct:     optionalBodyFat(bodyFat),
+			MeasuredAt:     measuredAt,
+			Notes:          bodyNotes,
+			Circumferences: bodyCircumferences(cmd),
+			LengthUnit:     bodyLengthUnit,
 		}
 		return withDB(func(sqldb *sql.DB) error {
 			id, err := service.AddBodyMeasurement(sqldb, in)
@@ -54,6 +68,8 @@ var (
 	bodyTo       string
 	bodyLimit    int
 	bodyOutUnit  string
+	bodyOutLen   string
+	bodyMetrics  bool
 )
 
 var bodyListCmd = &cobra.Command{
@@ -69,17 +85,48 @@ var bodyListCmd = &cobra.Command{
 			if bodyOutUnit == "" {
 				bodyOutUnit = "kg"
 			}
-			fmt.Fprintln(cmd.OutOrStdout(), "ID\tDATE\tWEIGHT\tUNIT\tBODY_FAT%\tNOTES")
+			profile, err := service.GetProfile(sqldb)
+			if err != nil {
+				return err
+			}
+			header := "ID\tDATE\tWEIGHT\tUNIT\tBODY_FAT%\tWAIST\tHIP\tNECK\tCHEST\tARM\tTHIGH"
+			if bodyMetrics {
+				if profile == nil || profile.HeightCm == nil {
+					return fmt.Errorf("--metrics needs a height (kcal profile set --height)")
+				}
+				header += "\tBMI\tFFMI\tFFMI_NORM\tWAIST_HEIGHT"
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), header+"\tNOTES")
 			for _, m := range items {
 				w, err := service.WeightFromKg(m.WeightKg, bodyOutUnit)
 				if err != nil {
 					return err
 				}
 				bf := ""
-				if m.BodyFatPct != nil {
-					bf = fmt.Sprintf("%.2f", *m.BodyFatPct)
+				if v, source := service.ResolveBodyFat(profile, m); v != nil {
+					bf = fmt.Sprintf("%.2f", *v)
+					if source == service.BodyFatSourceNavy {
+						bf += " (navy)"
+					}
+				}
+				sites := make([]string, 0, len(service.CircumferenceSites))
+				c := service.MeasurementCircumferences(m)
+				for _, v := range []*float64{c.Waist, c.Hip, c.Neck, c.Chest, c.Arm, c.Thigh} {
+					text := ""
+					if v != nil {
+						length, err := service.CircumferenceFromCm(*v, bodyOutLen)
+						if err != nil {
+							return err
+						}
+						text = fmt.Sprintf("%.1f", length)
+					}
+					sites = append(sites, text)
+				}
+				if bodyMetrics {
+					metrics := service.MeasurementMetrics(profile, m)
+					sites = append(sites, formatOptional(metrics.BMI, "%.1f"), formatOptional(metrics.FFMI, "%.1f"), formatOptional(metrics.NormalizedFFMI, "%.1f"), formatOptional(metrics.WaistToHeight, "%.3f"))
 				}
-				fmt.Fprintf(cmd.OutOrStdout(), "%d\t%s\t%.2f\t%s\t%s\t%s\n", m.ID, m.MeasuredAt.Local().Format("2006-01-02 15:04"), w, bodyOutUnit, bf, m.Notes)
+				fmt.Fprintf(cmd.OutOrStdout(), "%d\t%s\t%.2f\t%s\t%s\t%s\t%s\n", m.ID, m.MeasuredAt.Local().Format("2006-01-02 15:04"), w, bodyOutUnit, bf, strings.Join(sites, "\t"), m.Notes)
 			}
 			return nil
 		})
@@ -102,11 +149,13 @@ var bodyUpdateCmd = &cobra.Command{
 		in := service.UpdateBodyMeasurementInput{
 			ID: id,
 			BodyMeasurementInput: service.BodyMeasurementInput{
-				Weight:     bodyWeight,
-				Unit:       bodyUnit,
-				BodyFatPct: optionalBodyFat(bodyFat),
-				MeasuredAt: measuredAt,
-				Notes:      bodyNotes,
+				Weight:         bodyWeight,
+				Unit:           bodyUnit,
+				BodyFatPct:     optionalBodyFat(bodyFat),
+				MeasuredAt:     measuredAt,
+				Notes:          bodyNotes,
+				Circumferences: bodyCircumferences(cmd),
+				LengthUnit:     bodyLengthUnit,
 			},
 		}
 		return withDB(func(sqldb *sql.DB) error {
@@ -138,6 +187,170 @@ var bodyDeleteCmd = &cobra.Command{
 	},
 }
 
+var (
+	bodyTrendFrom      string
+	bodyTrendTo        string
+	bodyTrendSmoothing float64
+	bodyTrendUnit      string
+	bodyTrendJSON      bool
+)
+
+var bodyTrendCmd = &cobra.Command{
+	Use:   "trend",
+	Short: "Compare raw weigh-ins with the smoothed trend weight",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		end := time.Now()
+		if bodyTrendTo != "" {
+			parsed, err := time.ParseInLocation("2006-01-02", bodyTrendTo, time.Local)
+			if err != nil {
+				return fmt.Errorf("invalid --to date (expected YYYY-MM-DD)")
+			}
+			end = parsed
+		}
+		start := end.AddDate(0, 0, -29)
+		if bodyTrendFrom != "" {
+			parsed, err := time.ParseInLocation("2006-01-02", bodyTrendFrom, time.Local)
+			if err != nil {
+				return fmt.Errorf("invalid --from date (expected YYYY-MM-DD)")
+			}
+			start = parsed
+		}
+		if _, err := service.WeightFromKg(1, bodyTrendUnit); err != nil {
+			return err
+		}
+		return withDB(func(sqldb *sql.DB) error {
+			report, err := service.BodyWeightTrend(sqldb, start, end, bodyTrendSmoothing)
+			if err != nil {
+				return err
+			}
+			if bodyTrendJSON {
+				b, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(b))
+				return nil
+			}
+			out := cmd.OutOrStdout()
+			conv := func(kg float64) float64 {
+				v, _ := service.WeightFromKg(kg, bodyTrendUnit)
+				return v
+			}
+			fmt.Fprintf(out, "Range: %s to %s (smoothing %.2f)\n", report.FromDate, report.ToDate, report.Smoothing)
+			if len(report.Points) == 0 {
+				fmt.Fprintln(out, "No weigh-ins in range")
+				return nil
+			}
+			fmt.Fprintf(out, "Trend: %.2f -> %.2f %s (%+.2f, %+.2f %s/week); raw change %+.2f\n", conv(report.StartTrendWeightKg), conv(report.EndTrendWeightKg), bodyTrendUnit, conv(report.TrendChangeKg), conv(report.TrendKgPerWeek), bodyTrendUnit, conv(report.RawChangeKg))
+			fmt.Fprintln(out, "DATE\tRAW\tTREND\tDIFF")
+			for _, p := range report.Points {
+				fmt.Fprintf(out, "%s\t%.2f\t%.2f\t%+.2f\n", p.Date, conv(p.WeightKg), conv(p.TrendWeightKg), conv(p.DeviationKg))
+			}
+			return nil
+		})
+	},
+}
+
+var (
+	bodyImportIn       string
+	bodyImportFormat   string
+	bodyImportUnit     string
+	bodyImportTimezone string
+	bodyImportMap      []string
+	bodyImportDryRun   bool
+	bodyImportJSON     bool
+)
+
+var bodyImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import body measurements from a smart-scale CSV export",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if strings.TrimSpace(bodyImportIn) == "" {
+			return fmt.Errorf("--in is required")
+		}
+		columns := map[string]string{}
+		for _, spec := range bodyImportMap {
+			field, header, ok := strings.Cut(spec, "=")
+			if !ok || strings.TrimSpace(field) == "" || strings.TrimSpace(header) == "" {
+				return fmt.Errorf("invalid --map %q (expected FIELD=HEADER)", spec)
+			}
+			columns[strings.ToLower(strings.TrimSpace(field))] = strings.TrimSpace(header)
+		}
+		f, err := os.Open(bodyImportIn)
+		if err != nil {
+			return fmt.Errorf("open body import csv: %w", err)
+		}
+		defer f.Close()
+		return withDB(func(sqldb *sql.DB) error {
+			report, err := service.ImportBodyCSV(sqldb, f, service.BodyImportOptions{
+				Format:   bodyImportFormat,
+				Unit:     bodyImportUnit,
+				Timezone: bodyImportTimezone,
+				Columns:  columns,
+				DryRun:   bodyImportDryRun,
+			})
+			if err != nil {
+				return err
+			}
+			if bodyImportJSON {
+				b, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(b))
+				return nil
+			}
+			printBodyImportReport(cmd, report)
+			return nil
+		})
+	},
+}
+
+func printBodyImportReport(cmd *cobra.Command, r *service.BodyImportReport) {
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Format: %s\n", r.Format)
+	fmt.Fprintf(out, "Columns: %s\n", strings.Join(r.Columns, ", "))
+	fmt.Fprintf(out, "Unit: %s (%s)\n", r.Unit, r.UnitSource)
+	fmt.Fprintf(out, "Timezone: %s\n", r.Timezone)
+	for _, w := range r.Warnings {
+		fmt.Fprintf(out, "warning: %s\n", w)
+	}
+	fmt.Fprintf(out, "Body import report: rows=%d inserted=%d duplicates=%d skipped=%d\n", r.Rows, r.Inserted, r.Duplicates, r.Skipped)
+	if r.FirstDate != "" {
+		fmt.Fprintf(out, "Range: %s to %s\n", r.FirstDate, r.LastDate)
+	}
+	if r.DryRun {
+		fmt.Fprintln(out, "Dry run: no measurements written; inserted counts what would be added")
+	}
+}
+
+func formatOptional(v *float64, format string) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf(format, *v)
+}
+
+// bodyCircumferences collects the circumference flags; zero means not measured.
+// bodyCircumferences returns only the sites whose flags were set, so update
+// leaves the others as stored.
+func bodyCircumferences(cmd *cobra.Command) service.Circumferences {
+	site := func(name string, v float64) *float64 {
+		if !cmd.Flags().Changed(name) {
+			return nil
+		}
+		return &v
+	}
+	return service.Circumferences{
+		Waist: site("waist", bodyWaist),
+		Hip:   site("hip", bodyHip),
+		Neck:  site("neck", bodyNeck),
+		Chest: site("chest", bodyChest),
+		Arm:   site("arm", bodyArm),
+		Thigh: site("thigh", bodyThigh),
+	}
+}
+
 func optionalBodyFat(v float64) *float64 {
 	if v < 0 {
 		return nil
@@ -147,7 +360,7 @@ func optionalBodyFat(v float64) *float64 {
 
 func init() {
 	rootCmd.AddCommand(bodyCmd)
-	bodyCmd.AddCommand(bodyAddCmd, bodyListCmd, bodyUpdateCmd, bodyDeleteCmd)
+	bodyCmd.AddCommand(bodyAddCmd, bodyListCmd, bodyUpdateCmd, bodyDeleteCmd, bodyTrendCmd, bodyImportCmd)
 
 	for _, c := range []*cobra.Command{bodyAddCmd, bodyUpdateCmd} {
 		c.Flags().Float64Var(&bodyWeight, "weight", 0, "Weight value")
@@ -156,6 +369,13 @@ func init() {
 		c.Flags().StringVar(&bodyDate, "date", "", "Date YYYY-MM-DD")
 		c.Flags().StringVar(&bodyTime, "time", "", "Time HH:MM")
 		c.Flags().StringVar(&bodyNotes, "notes", "", "Optional notes")
+		c.Flags().StringVar(&bodyLengthUnit, "length-unit", "cm", "Circumference unit: cm or in")
+		c.Flags().Float64Var(&bodyWaist, "waist", 0, "Waist circumference (optional)")
+		c.Flags().Float64Var(&bodyHip, "hip", 0, "Hip circumference (optional)")
+		c.Flags().Float64Var(&bodyNeck, "neck", 0, "Neck circumference (optional)")
+		c.Flags().Float64Var(&bodyChest, "chest", 0, "Chest circumference (optional)")
+		c.Flags().Float64Var(&bodyArm, "arm", 0, "Arm circumference (optional)")
+		c.Flags().Float64Var(&bodyThigh, "thigh", 0, "Thigh circumference (optional)")
 		_ = c.MarkFlagRequired("weight")
 	}
 	_ = bodyUpdateCmd.MarkFlagRequired("date")
@@ -166,4 +386,20 @@ func init() {
 	bodyListCmd.Flags().StringVar(&bodyTo, "to", "", "Filter to date YYYY-MM-DD")
 	bodyListCmd.Flags().IntVar(&bodyLimit, "limit", 50, "Result limit")
 	bodyListCmd.Flags().StringVar(&bodyOutUnit, "unit", "kg", "Output unit: kg or lb")
+	bodyListCmd.Flags().StringVar(&bodyOutLen, "length-unit", "cm", "Circumference output unit: cm or in")
+	bodyListCmd.Flags().BoolVar(&bodyMetrics, "metrics", false, "Show BMI, FFMI, normalized FFMI and waist-to-height ratio (needs profile height)")
+
+	bodyTrendCmd.Flags().StringVar(&bodyTrendFrom, "from", "", "Start date YYYY-MM-DD (default: 30 days before --to)")
+	bodyTrendCmd.Flags().StringVar(&bodyTrendTo, "to", "", "End date YYYY-MM-DD (default: today)")
+	bodyTrendCmd.Flags().Float64Var(&bodyTrendSmoothing, "smoothing", service.DefaultWeightTrendSmoothing, "Share of each day's deviation the trend absorbs (0-1]")
+	bodyTrendCmd.Flags().StringVar(&bodyTrendUnit, "unit", "kg", "Output unit: kg or lb")
+	bodyTrendCmd.Flags().BoolVar(&bodyTrendJSON, "json", false, "Output JSON")
+
+	bodyImportCmd.Flags().StringVar(&bodyImportIn, "in", "", "Input CSV file path")
+	bodyImportCmd.Flags().StringVar(&bodyImportFormat, "format", "generic", "CSV layout: "+strings.Join(service.BodyImportFormats, "|"))
+	bodyImportCmd.Flags().StringVar(&bodyImportUnit, "unit", "", "Weight unit when cells and headers have none: kg, lb, or st (default: detect)")
+	bodyImportCmd.Flags().StringVar(&bodyImportTimezone, "timezone", "", "IANA timezone for timestamps without an offset (default: local)")
+	bodyImportCmd.Flags().StringArrayVar(&bodyImportMap, "map", nil, "Column mapping FIELD=HEADER for "+strings.Join(service.BodyImportFields, ", ")+" (repeatable)")
+	bodyImportCmd.Flags().BoolVar(&bodyImportDryRun, "dry-run", false, "Report what would be imported without writing")
+	bodyImportCmd.Flags().BoolVar(&bodyImportJSON, "json", false, "Output the report as JSON")
 }
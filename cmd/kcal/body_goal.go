@@ -2,7 +2,10 @@ package kcal
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/saadjs/kcal-cli/internal/service"
 	"github.com/spf13/cobra"
@@ -97,9 +100,137 @@ var bodyGoalHistoryCmd = &cobra.Command{
 	},
 }
 
+var (
+	forecastDate    string
+	forecastWindow  string
+	forecastMaxLoss float64
+	forecastMaxGain float64
+	forecastJSON    bool
+)
+
+var bodyGoalForecastCmd = &cobra.Command{
+	Use:   "forecast",
+	Short: "Project when the weight trend reaches the body goal",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		windowDays, err := service.ParseWindowDays(forecastWindow)
+		if err != nil {
+			return err
+		}
+		opts := service.BodyGoalForecastOptions{WindowDays: windowDays, MaxLossPctPerWeek: forecastMaxLoss, MaxGainPctPerWeek: forecastMaxGain}
+		if forecastDate != "" {
+			opts.Date, err = time.ParseInLocation("2006-01-02", forecastDate, time.Local)
+			if err != nil {
+				return fmt.Errorf("invalid --date %q (expected YYYY-MM-DD)", forecastDate)
+			}
+		}
+		return withDB(func(sqldb *sql.DB) error {
+			f, err := service.ForecastBodyGoal(sqldb, opts)
+			if err != nil {
+				return err
+			}
+			if f == nil {
+				fmt.Fprintln(cmd.OutOrStdout(), "No body goal configured")
+				return nil
+			}
+			if forecastJSON {
+				b, err := json.MarshalIndent(f, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(b))
+				return nil
+			}
+			return printBodyGoalForecast(cmd.OutOrStdout(), f, goalWeightUnit)
+		})
+	},
+}
+
+func printBodyGoalForecast(out anyWriter, f *service.BodyGoalForecast, unit string) error {
+	conv := func(kg float64) float64 {
+		v, _ := service.WeightFromKg(kg, unit)
+		return v
+	}
+	if _, err := service.WeightFromKg(1, unit); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "As of: %s (goal effective %s)\n", f.AsOf, f.GoalEffectiveDate)
+	fmt.Fprintf(out, "Target weight: %.2f %s\n", conv(f.TargetWeightKg), unit)
+	fmt.Fprintf(out, "Trend weight: %.2f %s (%d weigh-ins, %+.2f %s to go)\n", conv(f.TrendWeightKg), unit, f.WeighIns, conv(f.RemainingKg), unit)
+	if f.Direction == service.ForecastDirectionReached {
+		fmt.Fprintln(out, "Goal reached")
+		return nil
+	}
+	if f.TrendKgPerWeek != nil {
+		fmt.Fprintf(out, "Trend rate: %+.2f %s/week (95%% range %+.2f to %+.2f)\n", conv(*f.TrendKgPerWeek), unit, conv(*f.TrendKgPerWeekLow), conv(*f.TrendKgPerWeekHi))
+	}
+	if f.ETA != "" {
+		rng := ""
+		if f.ETAEarliest != "" || f.ETALatest != "" {
+			latest := f.ETALatest
+			if latest == "" {
+				latest = "never at worst-case rate"
+			}
+			rng = fmt.Sprintf(" (range %s to %s)", f.ETAEarliest, latest)
+		}
+		fmt.Fprintf(out, "Projected date: %s%s\n", f.ETA, rng)
+	}
+	if f.TargetDate != "" {
+		status := ""
+		if f.OnTrack != nil {
+			status = " (behind pace)"
+			if *f.OnTrack {
+				status = " (on track)"
+			}
+		}
+		fmt.Fprintf(out, "Target date: %s%s\n", f.TargetDate, status)
+	}
+	if f.RequiredKgPerWeek != nil && f.RequiredDailyBalance != nil {
+		kind := "deficit"
+		if *f.RequiredDailyBalance > 0 {
+			kind = "surplus"
+		}
+		fmt.Fprintf(out, "Needed to hit target date: %+.2f %s/week (%d kcal/day %s)\n", conv(*f.RequiredKgPerWeek), unit, absInt(*f.RequiredDailyBalance), kind)
+	}
+	fmt.Fprintf(out, "Safe rate: %+.2f %s/week\n", conv(f.SafeKgPerWeek), unit)
+	if f.Unrealistic {
+		fmt.Fprintln(out, "Warning: goal is unrealistic at the safe rate")
+	}
+	for _, n := range f.Notes {
+		fmt.Fprintf(out, "Note: %s\n", n)
+	}
+	return nil
+}
+
+// formatForecastSummary condenses a forecast to one line for analytics output.
+func formatForecastSummary(f *service.BodyGoalForecast) string {
+	if f.Direction == service.ForecastDirectionReached {
+		return fmt.Sprintf("reached (trend %.2fkg)", f.TrendWeightKg)
+	}
+	parts := []string{fmt.Sprintf("trend %.2fkg, %+.2fkg to go", f.TrendWeightKg, f.RemainingKg)}
+	if f.ETA != "" {
+		parts = append(parts, "ETA "+f.ETA)
+	} else {
+		parts = append(parts, "no ETA")
+	}
+	if f.RequiredDailyBalance != nil {
+		parts = append(parts, fmt.Sprintf("needs %+d kcal/day by %s", *f.RequiredDailyBalance, f.TargetDate))
+	}
+	if f.Unrealistic {
+		parts = append(parts, "unrealistic at safe rate")
+	}
+	return strings.Join(parts, ", ")
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
 func init() {
 	rootCmd.AddCommand(bodyGoalCmd)
-	bodyGoalCmd.AddCommand(bodyGoalSetCmd, bodyGoalCurrentCmd, bodyGoalHistoryCmd)
+	bodyGoalCmd.AddCommand(bodyGoalSetCmd, bodyGoalCurrentCmd, bodyGoalHistoryCmd, bodyGoalForecastCmd)
 
 	bodyGoalSetCmd.Flags().Float64Var(&goalWeightValue, "target-weight", 0, "Target weight value")
 	bodyGoalSetCmd.Flags().StringVar(&goalWeightUnit, "unit", "kg", "Weight unit: kg or lb")
@@ -111,4 +242,11 @@ func init() {
 	bodyGoalCurrentCmd.Flags().StringVar(&bodyGoalCurrentDate, "date", "", "Resolve goal at date YYYY-MM-DD")
 	bodyGoalCurrentCmd.Flags().StringVar(&goalWeightUnit, "unit", "kg", "Weight unit: kg or lb")
 	bodyGoalHistoryCmd.Flags().StringVar(&goalWeightUnit, "unit", "kg", "Weight unit: kg or lb")
+
+	bodyGoalForecastCmd.Flags().StringVar(&forecastDate, "date", "", "Forecast as of YYYY-MM-DD (default today)")
+	bodyGoalForecastCmd.Flags().StringVar(&forecastWindow, "window", "28d", "Trend window, e.g. 28d or 4w")
+	bodyGoalForecastCmd.Flags().Float64Var(&forecastMaxLoss, "max-loss-rate", service.DefaultMaxLossPctPerWeek, "Safe loss rate, percent of body weight per week")
+	bodyGoalForecastCmd.Flags().Float64Var(&forecastMaxGain, "max-gain-rate", service.DefaultMaxGainPctPerWeek, "Safe gain rate, percent of body weight per week")
+	bodyGoalForecastCmd.Flags().StringVar(&goalWeightUnit, "unit", "kg", "Weight unit: kg or lb")
+	bodyGoalForecastCmd.Flags().BoolVar(&forecastJSON, "json", false, "Output as JSON")
 }
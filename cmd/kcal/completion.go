@@ -0,0 +1,70 @@
+package kcal
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/saadjs/kcal-cli/internal/service"
+	"github.com/spf13/cobra"
+)
+
+const completionLimit = 50
+
+func completeSavedFoods(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	out := make([]string, 0)
+	_ = withDB(func(sqldb *sql.DB) error {
+		items, err := service.SuggestSavedFoods(sqldb, service.SuggestOptions{Limit: completionLimit})
+		if err != nil {
+			return err
+		}
+		for _, it := range items {
+			if matchesCompletion(it.Food.Name, toComplete) {
+				out = append(out, fmt.Sprintf("%s\t%d kcal, %s", it.Food.Name, it.Food.Calories, it.Food.DefaultCategory))
+			}
+		}
+		return nil
+	})
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeSavedMeals(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	out := make([]string, 0)
+	_ = withDB(func(sqldb *sql.DB) error {
+		items, err := service.SuggestSavedMeals(sqldb, service.SuggestOptions{Limit: completionLimit})
+		if err != nil {
+			return err
+		}
+		for _, it := range items {
+			if matchesCompletion(it.Meal.Name, toComplete) {
+				out = append(out, fmt.Sprintf("%s\t%d kcal, %s", it.Meal.Name, it.Meal.CaloriesTotal, it.Meal.DefaultCategory))
+			}
+		}
+		return nil
+	})
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeFirstArg(fn cobra.CompletionFunc) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return fn(cmd, args, toComplete)
+	}
+}
+
+func matchesCompletion(name, toComplete string) bool {
+	return strings.HasPrefix(strings.ToLower(name), strings.ToLower(strings.TrimSpace(toComplete)))
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{savedFoodShowCmd, savedFoodUpdateCmd, savedFoodArchiveCmd, savedFoodLogCmd, savedFoodRefreshCmd} {
+		cmd.ValidArgsFunction = completeFirstArg(completeSavedFoods)
+	}
+	savedFoodMergeCmd.ValidArgsFunction = completeSavedFoods
+	for _, cmd := range []*cobra.Command{savedMealShowCmd, savedMealUpdateCmd, savedMealArchiveCmd, savedMealLogCmd, savedMealComponentAddCmd, savedMealComponentListCmd} {
+		cmd.ValidArgsFunction = completeFirstArg(completeSavedMeals)
+	}
+	_ = savedMealComponentAddCmd.RegisterFlagCompletionFunc("saved-food", completeSavedFoods)
+}
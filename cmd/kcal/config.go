@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/saadjs/kcal-cli/internal/service"
 	"github.com/spf13/cobra"
@@ -18,6 +20,14 @@ var (
 	cfgBarcodeProvider      string
 	cfgBarcodeFallbackOrder string
 	cfgAPIKeyHint           string
+	cfgSavedFoodPropagate   bool
+	cfgAdherenceTargets     bool
+	cfgWeeklyBudget         bool
+	cfgEatBack              string
+	cfgEatBackByType        []string
+	cfgStepsCalories        string
+	cfgStrideCm             float64
+	cfgStepsGoal            int
 )
 
 var configSetCmd = &cobra.Command{
@@ -44,6 +54,81 @@ var configSetCmd = &cobra.Command{
 				}
 				updates++
 			}
+			if cmd.Flags().Changed("saved-food-propagate") {
+				if err := service.SetConfig(sqldb, service.ConfigSavedFoodPropagate, strconv.FormatBool(cfgSavedFoodPropagate)); err != nil {
+					return err
+				}
+				updates++
+			}
+			if cmd.Flags().Changed("adherence-nutrient-targets") {
+				if err := service.SetConfig(sqldb, service.ConfigAdherenceNutrientTargets, strconv.FormatBool(cfgAdherenceTargets)); err != nil {
+					return err
+				}
+				updates++
+			}
+			if cmd.Flags().Changed("weekly-budget") {
+				if err := service.SetConfig(sqldb, service.ConfigWeeklyBudget, strconv.FormatBool(cfgWeeklyBudget)); err != nil {
+					return err
+				}
+				updates++
+			}
+			if cmd.Flags().Changed("exercise-eat-back") {
+				if _, err := service.ParseEatBackPolicy(cfgEatBack); err != nil {
+					return err
+				}
+				if err := service.SetConfig(sqldb, service.ConfigExerciseEatBack, cfgEatBack); err != nil {
+					return err
+				}
+				updates++
+			}
+			for _, spec := range cfgEatBackByType {
+				exerciseType, policy, ok := strings.Cut(spec, "=")
+				exerciseType = strings.ToLower(strings.TrimSpace(exerciseType))
+				if !ok || exerciseType == "" {
+					return fmt.Errorf("invalid --exercise-eat-back-type %q (expected TYPE=POLICY)", spec)
+				}
+				if strings.TrimSpace(policy) != "" {
+					if _, err := service.ParseEatBackPolicy(policy); err != nil {
+						return err
+					}
+				}
+				if err := service.SetConfig(sqldb, service.ConfigExerciseEatBackTypePrefix+exerciseType, policy); err != nil {
+					return err
+				}
+				updates++
+			}
+			if cmd.Flags().Changed("steps-calories") {
+				mode, err := service.ParseStepsCaloriesMode(cfgStepsCalories)
+				if err != nil {
+					return err
+				}
+				if err := service.SetConfig(sqldb, service.ConfigStepsCalories, mode); err != nil {
+					return err
+				}
+				updates++
+			}
+			if cmd.Flags().Changed("stride-cm") {
+				if cfgStrideCm < 0 {
+					return fmt.Errorf("--stride-cm must be >= 0")
+				}
+				value := ""
+				if cfgStrideCm > 0 {
+					value = strconv.FormatFloat(cfgStrideCm, 'f', -1, 64)
+				}
+				if err := service.SetConfig(sqldb, service.ConfigStepsStrideCm, value); err != nil {
+					return err
+				}
+				updates++
+			}
+			if cmd.Flags().Changed("steps-goal") {
+				if cfgStepsGoal < 0 {
+					return fmt.Errorf("--steps-goal must be >= 0")
+				}
+				if err := service.SetConfig(sqldb, service.ConfigStepsGoal, strconv.Itoa(cfgStepsGoal)); err != nil {
+					return err
+				}
+				updates++
+			}
 			if updates == 0 {
 				return fmt.Errorf("set at least one flag")
 			}
@@ -83,4 +168,12 @@ func init() {
 	configSetCmd.Flags().StringVar(&cfgBarcodeProvider, "barcode-provider", "", "Default barcode provider")
 	configSetCmd.Flags().StringVar(&cfgBarcodeFallbackOrder, "fallback-order", "", "Default fallback order (comma-separated)")
 	configSetCmd.Flags().StringVar(&cfgAPIKeyHint, "api-key-hint", "", "API key setup hint text (non-secret)")
+	configSetCmd.Flags().BoolVar(&cfgSavedFoodPropagate, "saved-food-propagate", false, "Default for saved-food update --propagate")
+	configSetCmd.Flags().StringVar(&cfgEatBack, "exercise-eat-back", "", "Exercise calories eaten back: none, full, N%, or cap:N")
+	configSetCmd.Flags().StringArrayVar(&cfgEatBackByType, "exercise-eat-back-type", nil, "Per exercise type eat-back TYPE=POLICY; empty POLICY clears (repeatable)")
+	configSetCmd.Flags().BoolVar(&cfgAdherenceTargets, "adherence-nutrient-targets", false, "Require goal nutrient targets to be met for adherence")
+	configSetCmd.Flags().StringVar(&cfgStepsCalories, "steps-calories", "", "Step calorie estimate: none, exercise (adds to exercise calories), or baseline (part of activity level)")
+	configSetCmd.Flags().Float64Var(&cfgStrideCm, "stride-cm", 0, "Walking stride in cm for step estimates; 0 derives it from profile height")
+	configSetCmd.Flags().IntVar(&cfgStepsGoal, "steps-goal", 0, "Daily step goal for today and insights streaks; 0 clears")
+	configSetCmd.Flags().BoolVar(&cfgWeeklyBudget, "weekly-budget", false, "Track a Monday-Sunday calorie budget with banking and borrowing in today")
 }
@@ -2,12 +2,16 @@ package kcal
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/saadjs/kcal-cli/internal/service"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var exerciseCmd = &cobra.Command{
@@ -25,6 +29,7 @@ var (
 	exerciseTime         string
 	exerciseNotes        string
 	exerciseMetadata     string
+	exerciseIntensity    string
 )
 
 var exerciseAddCmd = &cobra.Command{
@@ -40,6 +45,10 @@ var exerciseAddCmd = &cobra.Command{
 			return err
 		}
 		return withDB(func(sqldb *sql.DB) error {
+			in, err := estimateExerciseIfNeeded(cmd, sqldb, in, exerciseIntensity)
+			if err != nil {
+				return err
+			}
 			id, err := service.CreateExerciseLog(sqldb, in)
 			if err != nil {
 				return err
@@ -109,6 +118,10 @@ var exerciseUpdateCmd = &cobra.Command{
 			return err
 		}
 		return withDB(func(sqldb *sql.DB) error {
+			in, err := estimateExerciseIfNeeded(cmd, sqldb, in, exerciseIntensity)
+			if err != nil {
+				return err
+			}
 			if err := service.UpdateExerciseLog(sqldb, service.UpdateExerciseInput{ID: id, ExerciseLogInput: in}); err != nil {
 				return err
 			}
@@ -137,6 +150,95 @@ var exerciseDeleteCmd = &cobra.Command{
 	},
 }
 
+var (
+	exerciseImportIn           string
+	exerciseImportFormat       string
+	exerciseImportType         string
+	exerciseImportDistanceUnit string
+	exerciseImportIntensity    string
+	exerciseImportDryRun       bool
+	exerciseImportJSON         bool
+)
+
+var exerciseImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import exercise logs from GPX or TCX activity files",
+	Example: `  kcal exercise import --in run.gpx
+  kcal exercise import --in ride.tcx --distance-unit mi --dry-run`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if strings.TrimSpace(exerciseImportIn) == "" {
+			return fmt.Errorf("--in is required")
+		}
+		format := exerciseImportFormat
+		if strings.TrimSpace(format) == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(exerciseImportIn)), ".")
+			if format != "gpx" && format != "tcx" {
+				format = ""
+			}
+		}
+		f, err := os.Open(exerciseImportIn)
+		if err != nil {
+			return fmt.Errorf("open exercise import file: %w", err)
+		}
+		defer f.Close()
+		return withDB(func(sqldb *sql.DB) error {
+			report, err := service.ImportExerciseFile(sqldb, f, service.ExerciseImportOptions{
+				Format:       format,
+				FileName:     filepath.Base(exerciseImportIn),
+				ExerciseType: exerciseImportType,
+				DistanceUnit: exerciseImportDistanceUnit,
+				Intensity:    exerciseImportIntensity,
+				DryRun:       exerciseImportDryRun,
+			})
+			if err != nil {
+				return err
+			}
+			if exerciseImportJSON {
+				b, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(b))
+				return nil
+			}
+			printExerciseImportReport(cmd, report)
+			return nil
+		})
+	},
+}
+
+func printExerciseImportReport(cmd *cobra.Command, r *service.ExerciseImportReport) {
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Format: %s\n", r.Format)
+	for _, w := range r.Warnings {
+		fmt.Fprintf(out, "warning: %s\n", w)
+	}
+	fmt.Fprintln(out, "START\tTYPE\tDURATION_MIN\tDISTANCE\tUNIT\tAVG_HR\tKCAL_BURNED\tKCAL_SOURCE\tSTATUS\tID")
+	for _, it := range r.Items {
+		start := it.StartTime
+		if t, err := time.Parse(time.RFC3339, it.StartTime); err == nil {
+			start = t.Local().Format("2006-01-02 15:04")
+		}
+		hr := ""
+		if it.AvgHeartRate != nil {
+			hr = fmt.Sprintf("%d", *it.AvgHeartRate)
+		}
+		kcal := ""
+		if it.CalorieSource != "" {
+			kcal = fmt.Sprintf("%d", it.Calories)
+		}
+		id := ""
+		if it.ExerciseLogID > 0 {
+			id = fmt.Sprintf("%d", it.ExerciseLogID)
+		}
+		fmt.Fprintf(out, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", start, it.ExerciseType, it.DurationMin, formatOptional(it.Distance, "%.2f"), it.DistanceUnit, hr, kcal, it.CalorieSource, it.Status, id)
+	}
+	fmt.Fprintf(out, "Exercise import report: activities=%d inserted=%d duplicates=%d skipped=%d\n", r.Activities, r.Inserted, r.Duplicates, r.Skipped)
+	if r.DryRun {
+		fmt.Fprintln(out, "Dry run: no exercise logs written; inserted counts what would be added")
+	}
+}
+
 func buildExerciseInput(cmd *cobra.Command, performedAt time.Time) (service.ExerciseLogInput, error) {
 	var duration *int
 	if cmd.Flags().Changed("duration-min") {
@@ -162,22 +264,51 @@ func buildExerciseInput(cmd *cobra.Command, performedAt time.Time) (service.Exer
 	}, nil
 }
 
+// estimateExerciseIfNeeded computes calories from the MET table when
+// --calories is omitted.
+func estimateExerciseIfNeeded(cmd *cobra.Command, sqldb *sql.DB, in service.ExerciseLogInput, intensity string) (service.ExerciseLogInput, error) {
+	if cmd.Flags().Changed("calories") {
+		if cmd.Flags().Changed("intensity") {
+			return in, fmt.Errorf("--intensity is only used to estimate calories; omit --calories")
+		}
+		return in, nil
+	}
+	if in.DurationMin == nil {
+		return in, fmt.Errorf("--calories is required unless --duration-min is set for a MET estimate")
+	}
+	in, estimate, err := service.EstimateExerciseCalories(sqldb, in, intensity)
+	if err != nil {
+		return in, err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Estimated %d kcal (MET %.1f, %s from %s, %.1fkg on %s)\n", estimate.Calories, estimate.MET, estimate.Intensity, estimate.IntensitySource, estimate.WeightKg, estimate.WeightDate)
+	return in, nil
+}
+
+// exerciseFlagAliases accepts --duration for --duration-min.
+func exerciseFlagAliases(_ *pflag.FlagSet, name string) pflag.NormalizedName {
+	if name == "duration" {
+		name = "duration-min"
+	}
+	return pflag.NormalizedName(name)
+}
+
 func init() {
 	rootCmd.AddCommand(exerciseCmd)
-	exerciseCmd.AddCommand(exerciseAddCmd, exerciseListCmd, exerciseUpdateCmd, exerciseDeleteCmd)
+	exerciseCmd.AddCommand(exerciseAddCmd, exerciseListCmd, exerciseUpdateCmd, exerciseDeleteCmd, exerciseImportCmd)
 
 	for _, c := range []*cobra.Command{exerciseAddCmd, exerciseUpdateCmd} {
 		c.Flags().StringVar(&exerciseType, "type", "", "Exercise type (running, cycling, strength, etc.)")
-		c.Flags().IntVar(&exerciseCalories, "calories", 0, "Calories burned")
-		c.Flags().IntVar(&exerciseDurationMin, "duration-min", 0, "Duration in minutes (optional)")
+		c.Flags().IntVar(&exerciseCalories, "calories", 0, "Calories burned (estimated from MET values and latest body weight when omitted)")
+		c.Flags().IntVar(&exerciseDurationMin, "duration-min", 0, "Duration in minutes (required without --calories; alias --duration)")
+		c.Flags().StringVar(&exerciseIntensity, "intensity", "", "Intensity for MET estimates: light, moderate or vigorous (running/cycling use pace from --distance)")
 		c.Flags().Float64Var(&exerciseDistance, "distance", 0, "Distance (optional)")
 		c.Flags().StringVar(&exerciseDistanceUnit, "distance-unit", "", "Distance unit: km or mi (required with --distance)")
 		c.Flags().StringVar(&exerciseDate, "date", "", "Date YYYY-MM-DD")
 		c.Flags().StringVar(&exerciseTime, "time", "", "Time HH:MM")
 		c.Flags().StringVar(&exerciseNotes, "notes", "", "Optional notes")
 		c.Flags().StringVar(&exerciseMetadata, "metadata-json", "", "Optional metadata JSON object")
+		c.Flags().SetNormalizeFunc(exerciseFlagAliases)
 		_ = c.MarkFlagRequired("type")
-		_ = c.MarkFlagRequired("calories")
 	}
 	_ = exerciseUpdateCmd.MarkFlagRequired("date")
 	_ = exerciseUpdateCmd.MarkFlagRequired("time")
@@ -187,4 +318,12 @@ func init() {
 	exerciseListCmd.Flags().StringVar(&exerciseToDate, "to", "", "Filter to date YYYY-MM-DD")
 	exerciseListCmd.Flags().StringVar(&exerciseListType, "type", "", "Filter by exercise type")
 	exerciseListCmd.Flags().IntVar(&exerciseLimit, "limit", 50, "Result limit")
+
+	exerciseImportCmd.Flags().StringVar(&exerciseImportIn, "in", "", "Input GPX or TCX file path")
+	exerciseImportCmd.Flags().StringVar(&exerciseImportFormat, "format", "", "File format: "+strings.Join(service.ExerciseImportFormats, "|")+" (default: detect)")
+	exerciseImportCmd.Flags().StringVar(&exerciseImportType, "type", "", "Exercise type override (default: the file's sport)")
+	exerciseImportCmd.Flags().StringVar(&exerciseImportDistanceUnit, "distance-unit", "km", "Distance unit for imported logs: km or mi")
+	exerciseImportCmd.Flags().StringVar(&exerciseImportIntensity, "intensity", "", "Intensity for MET estimates when the file has no calories")
+	exerciseImportCmd.Flags().BoolVar(&exerciseImportDryRun, "dry-run", false, "Report what would be imported without writing")
+	exerciseImportCmd.Flags().BoolVar(&exerciseImportJSON, "json", false, "Output the report as JSON")
 }
@@ -17,22 +17,48 @@ var goalCmd = &cobra.Command{
 
 var (
 	goalCalories int
-	goalProtein  float64
-	goalCarbs    float64
-	goalFat      float64
+	goalProtein  string
+	goalCarbs    string
+	goalFat      string
 	goalDate     string
+
+	goalMinTargets   []string
+	goalMaxTargets   []string
+	goalClearTargets bool
 )
 
 var goalSetCmd = &cobra.Command{
 	Use:   "set",
 	Short: "Set daily goals with an effective date",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		targets, err := parseGoalTargetFlags(goalMinTargets, goalMaxTargets)
+		if err != nil {
+			return err
+		}
+		if goalClearTargets && len(targets) > 0 {
+			return fmt.Errorf("--clear-targets cannot be combined with --min or --max")
+		}
 		in := service.SetGoalInput{
-			Calories:      goalCalories,
-			ProteinG:      goalProtein,
-			CarbsG:        goalCarbs,
-			FatG:          goalFat,
-			EffectiveDate: goalDate,
+			Calories:        goalCalories,
+			NutrientTargets: targets,
+			InheritTargets:  !goalClearTargets,
+			EffectiveDate:   goalDate,
+		}
+		for _, m := range []struct {
+			spec  string
+			grams *float64
+			rule  **service.MacroRule
+		}{
+			{goalProtein, &in.ProteinG, &in.MacroRules.Protein},
+			{goalCarbs, &in.CarbsG, &in.MacroRules.Carbs},
+			{goalFat, &in.FatG, &in.MacroRules.Fat},
+		} {
+			grams, rule, err := service.ParseMacroSpec(m.spec)
+			if err != nil {
+				return err
+			}
+			*m.grams = grams
+			*m.rule = rule
 		}
 		return withDB(func(sqldb *sql.DB) error {
 			if err := service.SetGoal(sqldb, in); err != nil {
@@ -62,7 +88,31 @@ var goalCurrentCmd = &cobra.Command{
 				fmt.Fprintln(cmd.OutOrStdout(), "No goal configured")
 				return nil
 			}
-			fmt.Fprintf(cmd.OutOrStdout(), "Effective: %s\nCalories: %d\nProtein: %.1fg\nCarbs: %.1fg\nFat: %.1fg\n", goal.EffectiveDate, goal.Calories, goal.ProteinG, goal.CarbsG, goal.FatG)
+			rules, err := service.ParseMacroRulesJSON(goal.MacroRules)
+			if err != nil {
+				return err
+			}
+			macros, err := service.ResolveMacroRules(sqldb, *goal, currentGoalDate)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Effective: %s\nCalories: %d\n", goal.EffectiveDate, goal.Calories)
+			fmt.Fprintf(cmd.OutOrStdout(), "Protein: %s\n", formatMacroGoal(rules.Protein, macros.ProteinG))
+			fmt.Fprintf(cmd.OutOrStdout(), "Carbs: %s\n", formatMacroGoal(rules.Carbs, macros.CarbsG))
+			fmt.Fprintf(cmd.OutOrStdout(), "Fat: %s\n", formatMacroGoal(rules.Fat, macros.FatG))
+			if macros.WeightKg != nil {
+				fmt.Fprintf(cmd.OutOrStdout(), "Body weight: %.1f kg (%s)\n", *macros.WeightKg, macros.MeasuredOn)
+			}
+			for _, note := range macros.Notes {
+				fmt.Fprintf(cmd.OutOrStdout(), "Note: %s\n", note)
+			}
+			targets, err := service.ParseNutrientTargetsJSON(goal.NutrientTargets)
+			if err != nil {
+				return err
+			}
+			for _, t := range targets {
+				fmt.Fprintf(cmd.OutOrStdout(), "Target: %s\n", formatNutrientTarget(t))
+			}
 			return nil
 		})
 	},
@@ -94,54 +144,139 @@ var (
 	suggestProteinPerKg float64
 	suggestApply        bool
 	suggestDate         string
+	suggestAuto         bool
+	suggestAutoWindow   string
+	suggestFormula      string
 )
 
 var goalSuggestCmd = &cobra.Command{
 	Use:   "suggest",
-	Short: "Suggest a maintain/cut/bulk goal",
+	Short: "Suggest a goal from maintenance calories and a target weekly weight change",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if suggestWeight <= 0 {
-			return fmt.Errorf("--weight must be > 0")
+		manualMaintenance := cmd.Flags().Changed("maintenance-calories")
+		if suggestAuto && manualMaintenance {
+			return fmt.Errorf("--auto-maintenance cannot be combined with --maintenance-calories")
+		}
+		if cmd.Flags().Changed("formula") && (suggestAuto || manualMaintenance) {
+			return fmt.Errorf("--formula cannot be combined with --maintenance-calories or --auto-maintenance")
 		}
-		if suggestMaintenance <= 0 {
+		if manualMaintenance && suggestMaintenance <= 0 {
 			return fmt.Errorf("--maintenance-calories must be > 0")
 		}
 		if suggestProteinPerKg <= 0 {
 			return fmt.Errorf("--protein-per-kg must be > 0")
 		}
-		weightKg, err := service.ToKg(suggestWeight, suggestWeightUnit)
+		formula, err := service.NormalizeFormula(suggestFormula)
 		if err != nil {
 			return err
 		}
-		targetCalories := suggestMaintenance
-		switch strings.ToLower(strings.TrimSpace(suggestPace)) {
-		case "maintain":
-		case "cut":
-			targetCalories -= 500
-		case "bulk":
-			targetCalories += 300
-		default:
-			return fmt.Errorf("--pace must be maintain, cut, or bulk")
-		}
-		protein := weightKg * suggestProteinPerKg
-		fat := weightKg * 0.8
-		remainingCalories := float64(targetCalories) - ((protein * 4) + (fat * 9))
-		if remainingCalories < 0 {
-			return fmt.Errorf("calorie target too low for selected protein/fat heuristics")
-		}
-		carbs := remainingCalories / 4
-
-		fmt.Fprintf(cmd.OutOrStdout(), "Suggested goal (%s):\n", strings.ToLower(strings.TrimSpace(suggestPace)))
-		fmt.Fprintf(cmd.OutOrStdout(), "Calories: %d\nProtein: %.1fg\nCarbs: %.1fg\nFat: %.1fg\n", targetCalories, protein, carbs, fat)
-
-		if !suggestApply {
-			return nil
+		paceKg, err := service.ParseWeeklyPace(suggestPace)
+		if err != nil {
+			return err
 		}
 		effectiveDate := strings.TrimSpace(suggestDate)
 		if effectiveDate == "" {
 			effectiveDate = time.Now().Format("2006-01-02")
 		}
+		asOf, err := time.ParseInLocation("2006-01-02", effectiveDate, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid --effective-date %q (expected YYYY-MM-DD)", effectiveDate)
+		}
+
 		return withDB(func(sqldb *sql.DB) error {
+			out := cmd.OutOrStdout()
+			latest, err := service.LatestBodyMeasurement(sqldb, effectiveDate)
+			if err != nil {
+				return err
+			}
+			var weightKg float64
+			var bodyFatPct *float64
+			switch {
+			case suggestWeight > 0:
+				weightKg, err = service.ToKg(suggestWeight, suggestWeightUnit)
+				if err != nil {
+					return err
+				}
+			case latest != nil:
+				weightKg = latest.WeightKg
+				fmt.Fprintf(out, "Using body weight %.1f kg from %s\n", weightKg, latest.MeasuredAt.Format("2006-01-02"))
+			default:
+				return fmt.Errorf("--weight must be > 0 (no body measurements logged)")
+			}
+			if latest != nil {
+				bodyFatPct = latest.BodyFatPct
+			}
+
+			profile, err := service.GetProfile(sqldb)
+			if err != nil {
+				return err
+			}
+			var estimates []service.MaintenanceEstimate
+			if profile != nil {
+				estimates, err = service.EstimateMaintenance(*profile, weightKg, bodyFatPct, asOf)
+				if err != nil && !manualMaintenance && !suggestAuto {
+					return err
+				}
+			}
+
+			maintenance := suggestMaintenance
+			source := "manual"
+			switch {
+			case manualMaintenance:
+			case suggestAuto:
+				opts, err := tdeeEstimateOptions(suggestAutoWindow, "")
+				if err != nil {
+					return err
+				}
+				est, err := service.EstimateTDEE(sqldb, opts)
+				if err != nil {
+					return fmt.Errorf("estimate maintenance: %w", err)
+				}
+				maintenance = est.EstimatedTDEE
+				source = fmt.Sprintf("TDEE estimate %s to %s, 95%% band %d-%d, %s confidence", est.FromDate, est.ToDate, est.Low, est.High, est.Confidence)
+			case profile == nil:
+				return fmt.Errorf("--maintenance-calories is required (or use --auto-maintenance, or set a profile with kcal profile set)")
+			default:
+				for _, e := range estimates {
+					if e.Formula != formula {
+						continue
+					}
+					if !e.Available {
+						return fmt.Errorf("%s unavailable: %s", e.Name, e.Note)
+					}
+					maintenance = e.TDEE
+					source = fmt.Sprintf("%s, %s activity", e.Name, profile.ActivityLevel)
+				}
+			}
+
+			targetCalories := maintenance + service.PaceCalorieDelta(paceKg)
+			protein := weightKg * suggestProteinPerKg
+			fat := weightKg * 0.8
+			remainingCalories := float64(targetCalories) - ((protein * 4) + (fat * 9))
+			if remainingCalories < 0 {
+				return fmt.Errorf("calorie target too low for selected protein/fat heuristics")
+			}
+			carbs := remainingCalories / 4
+
+			fmt.Fprintf(out, "Maintenance: %d kcal/day (%s)\n", maintenance, source)
+			fmt.Fprintf(out, "Pace: %+.2f kg/week (%+d kcal/day)\n", paceKg, service.PaceCalorieDelta(paceKg))
+			if len(estimates) > 0 {
+				fmt.Fprintf(out, "Formula comparison (%s activity, x%.3g):\n", profile.ActivityLevel, service.ActivityMultipliers[profile.ActivityLevel])
+				fmt.Fprintln(out, "FORMULA\tBMR\tMAINTENANCE\tTARGET")
+				for _, e := range estimates {
+					if !e.Available {
+						fmt.Fprintf(out, "%s\t-\t-\t- (%s)\n", e.Name, e.Note)
+						continue
+					}
+					fmt.Fprintf(out, "%s\t%d\t%d\t%d\n", e.Name, e.BMR, e.TDEE, e.TDEE+service.PaceCalorieDelta(paceKg))
+				}
+			}
+			fmt.Fprintf(out, "Suggested goal (%s):\n", strings.ToLower(strings.TrimSpace(suggestPace)))
+			fmt.Fprintf(out, "Calories: %d\nProtein: %.1fg\nCarbs: %.1fg\nFat: %.1fg\n", targetCalories, protein, carbs, fat)
+
+			if !suggestApply {
+				return nil
+			}
 			if err := service.SetGoal(sqldb, service.SetGoalInput{
 				Calories:      targetCalories,
 				ProteinG:      protein,
@@ -151,21 +286,58 @@ var goalSuggestCmd = &cobra.Command{
 			}); err != nil {
 				return err
 			}
-			fmt.Fprintf(cmd.OutOrStdout(), "Applied suggested goal effective %s\n", effectiveDate)
+			fmt.Fprintf(out, "Applied suggested goal effective %s\n", effectiveDate)
 			return nil
 		})
 	},
 }
 
+func parseGoalTargetFlags(mins, maxes []string) ([]service.NutrientTarget, error) {
+	out := make([]service.NutrientTarget, 0, len(mins)+len(maxes))
+	for _, spec := range mins {
+		t, err := service.ParseNutrientTarget(service.NutrientTargetMin, spec)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	for _, spec := range maxes {
+		t, err := service.ParseNutrientTarget(service.NutrientTargetMax, spec)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func formatMacroGoal(rule *service.MacroRule, grams float64) string {
+	if rule == nil {
+		return fmt.Sprintf("%.1fg", grams)
+	}
+	return fmt.Sprintf("%.1fg (%s)", grams, rule.String())
+}
+
+func formatNutrientTarget(t service.NutrientTarget) string {
+	op := ">="
+	if t.Kind == service.NutrientTargetMax {
+		op = "<="
+	}
+	return fmt.Sprintf("%s %s %g %s", t.Nutrient, op, t.Amount, t.Unit)
+}
+
 func init() {
 	rootCmd.AddCommand(goalCmd)
 	goalCmd.AddCommand(goalSetCmd, goalCurrentCmd, goalHistoryCmd, goalSuggestCmd)
 
 	goalSetCmd.Flags().IntVar(&goalCalories, "calories", 0, "Daily calorie target")
-	goalSetCmd.Flags().Float64Var(&goalProtein, "protein", 0, "Daily protein target grams")
-	goalSetCmd.Flags().Float64Var(&goalCarbs, "carbs", 0, "Daily carbs target grams")
-	goalSetCmd.Flags().Float64Var(&goalFat, "fat", 0, "Daily fat target grams")
+	goalSetCmd.Flags().StringVar(&goalProtein, "protein", "", "Daily protein grams, or a rule: 2g/kg, 0.9g/lb, 2.4g/kg-lean, 30%, remainder")
+	goalSetCmd.Flags().StringVar(&goalCarbs, "carbs", "", "Daily carbs grams, or a rule: 45%, remainder")
+	goalSetCmd.Flags().StringVar(&goalFat, "fat", "", "Daily fat grams, or a rule: 0.8g/kg, 25%, remainder")
 	goalSetCmd.Flags().StringVar(&goalDate, "effective-date", "", "Effective date YYYY-MM-DD (default today)")
+	goalSetCmd.Flags().StringArrayVar(&goalMinTargets, "min", nil, "Minimum nutrient target NAME=AMOUNT[UNIT], e.g. fiber=30g (repeatable)")
+	goalSetCmd.Flags().StringArrayVar(&goalMaxTargets, "max", nil, "Maximum nutrient cap NAME=AMOUNT[UNIT], e.g. sodium=2300mg (repeatable)")
+	goalSetCmd.Flags().BoolVar(&goalClearTargets, "clear-targets", false, "Drop nutrient targets instead of carrying forward the previous goal's")
 	_ = goalSetCmd.MarkFlagRequired("calories")
 	_ = goalSetCmd.MarkFlagRequired("protein")
 	_ = goalSetCmd.MarkFlagRequired("carbs")
@@ -173,11 +345,14 @@ func init() {
 
 	goalCurrentCmd.Flags().StringVar(&currentGoalDate, "date", "", "Resolve goal at date YYYY-MM-DD (default today)")
 
-	goalSuggestCmd.Flags().Float64Var(&suggestWeight, "weight", 0, "Body weight for macro heuristic")
+	goalSuggestCmd.Flags().Float64Var(&suggestWeight, "weight", 0, "Body weight (default latest body measurement)")
 	goalSuggestCmd.Flags().StringVar(&suggestWeightUnit, "unit", "kg", "Weight unit: kg or lb")
-	goalSuggestCmd.Flags().IntVar(&suggestMaintenance, "maintenance-calories", 0, "Estimated maintenance calories")
-	goalSuggestCmd.Flags().StringVar(&suggestPace, "pace", "maintain", "Target pace: maintain, cut, bulk")
+	goalSuggestCmd.Flags().IntVar(&suggestMaintenance, "maintenance-calories", 0, "Maintenance calories (default from profile formula)")
+	goalSuggestCmd.Flags().StringVar(&suggestPace, "pace", "maintain", "Target weekly weight change, e.g. -0.5kg, +0.25kg, -1lb (or maintain, cut, bulk)")
+	goalSuggestCmd.Flags().StringVar(&suggestFormula, "formula", service.FormulaMifflinStJeor, "Maintenance formula with a profile: mifflin, harris, katch")
 	goalSuggestCmd.Flags().Float64Var(&suggestProteinPerKg, "protein-per-kg", 2.0, "Protein heuristic grams per kg")
 	goalSuggestCmd.Flags().BoolVar(&suggestApply, "apply", false, "Apply suggestion as a goal")
 	goalSuggestCmd.Flags().StringVar(&suggestDate, "effective-date", "", "Effective date YYYY-MM-DD (default today)")
+	goalSuggestCmd.Flags().BoolVar(&suggestAuto, "auto-maintenance", false, "Use the TDEE estimate from logged intake and weight trend as maintenance")
+	goalSuggestCmd.Flags().StringVar(&suggestAutoWindow, "window", "28d", "Window for --auto-maintenance, e.g. 28d or 4w")
 }
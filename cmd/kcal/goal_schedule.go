@@ -0,0 +1,167 @@
+package kcal
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/saadjs/kcal-cli/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var goalScheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Manage weekday and training/rest-day overrides for a goal",
+}
+
+var (
+	goalScheduleDate     string
+	goalScheduleDay      string
+	goalScheduleCalories int
+	goalScheduleProtein  float64
+	goalScheduleCarbs    float64
+	goalScheduleFat      float64
+)
+
+var goalScheduleSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Set targets for a weekday or day type on the goal in effect at --date",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withDB(func(sqldb *sql.DB) error {
+			if err := service.SetGoalSchedule(sqldb, service.SetGoalScheduleInput{
+				Date:     goalScheduleDate,
+				DayKey:   goalScheduleDay,
+				Calories: goalScheduleCalories,
+				ProteinG: goalScheduleProtein,
+				CarbsG:   goalScheduleCarbs,
+				FatG:     goalScheduleFat,
+			}); err != nil {
+				return err
+			}
+			key, _ := service.NormalizeGoalDayKey(goalScheduleDay)
+			fmt.Fprintf(cmd.OutOrStdout(), "Set %s schedule\n", key)
+			return nil
+		})
+	},
+}
+
+var goalScheduleListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List overrides on the goal in effect at --date",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withDB(func(sqldb *sql.DB) error {
+			goal, entries, err := service.GoalSchedule(sqldb, goalScheduleDate)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "DAY\tKCAL\tP\tC\tF")
+			fmt.Fprintf(cmd.OutOrStdout(), "default\t%d\t%.1f\t%.1f\t%.1f\n", goal.Calories, goal.ProteinG, goal.CarbsG, goal.FatG)
+			for _, e := range entries {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s\t%d\t%.1f\t%.1f\t%.1f\n", e.DayKey, e.Calories, e.ProteinG, e.CarbsG, e.FatG)
+			}
+			return nil
+		})
+	},
+}
+
+var goalScheduleClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove a weekday or day-type override",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withDB(func(sqldb *sql.DB) error {
+			if err := service.DeleteGoalSchedule(sqldb, goalScheduleDate, goalScheduleDay); err != nil {
+				return err
+			}
+			key, _ := service.NormalizeGoalDayKey(goalScheduleDay)
+			fmt.Fprintf(cmd.OutOrStdout(), "Cleared %s schedule\n", key)
+			return nil
+		})
+	},
+}
+
+var goalDayTypeCmd = &cobra.Command{
+	Use:   "day-type",
+	Short: "Mark days as training or rest days",
+}
+
+var goalDayTypeDate string
+
+var goalDayTypeSetCmd = &cobra.Command{
+	Use:   "set <training|rest>",
+	Short: "Mark a date as a training or rest day",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withDB(func(sqldb *sql.DB) error {
+			if err := service.SetDayType(sqldb, goalDayTypeDate, args[0]); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Marked %s as %s\n", dateOrToday(goalDayTypeDate), args[0])
+			return nil
+		})
+	},
+}
+
+var goalDayTypeClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove a manual day type so it is inferred from exercise logs",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withDB(func(sqldb *sql.DB) error {
+			if err := service.ClearDayType(sqldb, goalDayTypeDate); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Cleared day type for %s\n", dateOrToday(goalDayTypeDate))
+			return nil
+		})
+	},
+}
+
+var goalDayTypeShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the day type for a date",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withDB(func(sqldb *sql.DB) error {
+			dayType, inferred, err := service.DayTypeFor(sqldb, goalDayTypeDate)
+			if err != nil {
+				return err
+			}
+			source := "marked"
+			if inferred {
+				source = "inferred from exercise logs"
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s: %s (%s)\n", dateOrToday(goalDayTypeDate), dayType, source)
+			return nil
+		})
+	},
+}
+
+func dateOrToday(date string) string {
+	if date == "" {
+		return "today"
+	}
+	return date
+}
+
+func init() {
+	goalCmd.AddCommand(goalScheduleCmd, goalDayTypeCmd)
+	goalScheduleCmd.AddCommand(goalScheduleSetCmd, goalScheduleListCmd, goalScheduleClearCmd)
+	goalDayTypeCmd.AddCommand(goalDayTypeSetCmd, goalDayTypeClearCmd, goalDayTypeShowCmd)
+
+	for _, c := range []*cobra.Command{goalScheduleSetCmd, goalScheduleListCmd, goalScheduleClearCmd} {
+		c.Flags().StringVar(&goalScheduleDate, "date", "", "Select the goal version in effect at YYYY-MM-DD (default today)")
+	}
+	for _, c := range []*cobra.Command{goalScheduleSetCmd, goalScheduleClearCmd} {
+		c.Flags().StringVar(&goalScheduleDay, "day", "", "Weekday (monday..sunday) or day type (training, rest)")
+		_ = c.MarkFlagRequired("day")
+	}
+	goalScheduleSetCmd.Flags().IntVar(&goalScheduleCalories, "calories", 0, "Calorie target for the day")
+	goalScheduleSetCmd.Flags().Float64Var(&goalScheduleProtein, "protein", 0, "Protein target grams for the day")
+	goalScheduleSetCmd.Flags().Float64Var(&goalScheduleCarbs, "carbs", 0, "Carbs target grams for the day")
+	goalScheduleSetCmd.Flags().Float64Var(&goalScheduleFat, "fat", 0, "Fat target grams for the day")
+	_ = goalScheduleSetCmd.MarkFlagRequired("calories")
+	_ = goalScheduleSetCmd.MarkFlagRequired("protein")
+	_ = goalScheduleSetCmd.MarkFlagRequired("carbs")
+	_ = goalScheduleSetCmd.MarkFlagRequired("fat")
+
+	for _, c := range []*cobra.Command{goalDayTypeSetCmd, goalDayTypeClearCmd, goalDayTypeShowCmd} {
+		c.Flags().StringVar(&goalDayTypeDate, "date", "", "Date YYYY-MM-DD (default today)")
+	}
+}
@@ -0,0 +1,148 @@
+package kcal
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/saadjs/kcal-cli/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var phaseCmd = &cobra.Command{
+	Use:   "phase",
+	Short: "Plan diet phases that set goals automatically",
+}
+
+var (
+	phaseName        string
+	phaseStart       string
+	phaseDuration    string
+	phaseCalories    string
+	phaseMaintenance int
+	phaseProtein     string
+	phaseCarbs       string
+	phaseFat         string
+	phaseDate        string
+	phaseJSON        bool
+)
+
+var phaseAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add a phase and write its goal version",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		days, err := service.ParseWindowDays(phaseDuration)
+		if err != nil {
+			return fmt.Errorf("invalid --duration: %w", err)
+		}
+		return withDB(func(sqldb *sql.DB) error {
+			p, err := service.AddPhase(sqldb, service.PhaseInput{
+				Name:                phaseName,
+				StartDate:           phaseStart,
+				DurationDays:        days,
+				CalorieStrategy:     phaseCalories,
+				MaintenanceCalories: phaseMaintenance,
+				Protein:             phaseProtein,
+				Carbs:               phaseCarbs,
+				Fat:                 phaseFat,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Added phase %d %q: %s to %s, %d kcal/day (goal effective %s)\n", p.ID, p.Name, p.StartDate, service.PhaseEndDate(*p), p.Calories, p.StartDate)
+			return nil
+		})
+	},
+}
+
+var phaseListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List phases in order",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withDB(func(sqldb *sql.DB) error {
+			items, err := service.ListPhases(sqldb)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "ID\tNAME\tSTART\tEND\tWEEKS\tSTRATEGY\tKCAL\tP\tC\tF")
+			for _, p := range items {
+				fmt.Fprintf(cmd.OutOrStdout(), "%d\t%s\t%s\t%s\t%.1f\t%s\t%d\t%s\t%s\t%s\n", p.ID, p.Name, p.StartDate, service.PhaseEndDate(p), float64(p.DurationDays)/7, p.CalorieStrategy, p.Calories, p.ProteinSpec, p.CarbsSpec, p.FatSpec)
+			}
+			return nil
+		})
+	},
+}
+
+var phaseCurrentCmd = &cobra.Command{
+	Use:   "current",
+	Short: "Show the phase and week for a date",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withDB(func(sqldb *sql.DB) error {
+			status, err := service.PhaseForDate(sqldb, phaseDate)
+			if err != nil {
+				return err
+			}
+			if phaseJSON {
+				b, err := json.MarshalIndent(status, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(b))
+				return nil
+			}
+			if status == nil {
+				fmt.Fprintf(cmd.OutOrStdout(), "No phase on %s\n", dateOrToday(phaseDate))
+				return nil
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Phase: %s\n", formatPhaseStatus(status))
+			fmt.Fprintf(cmd.OutOrStdout(), "Dates: %s to %s (day %d of %d)\n", status.StartDate, status.EndDate, status.Day, status.TotalDays)
+			return nil
+		})
+	},
+}
+
+var phaseDeleteCmd = &cobra.Command{
+	Use:   "delete <id>",
+	Short: "Delete a phase and the goal version it wrote",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseInt64Arg("phase id", args[0])
+		if err != nil {
+			return err
+		}
+		return withDB(func(sqldb *sql.DB) error {
+			if err := service.DeletePhase(sqldb, id); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Deleted phase %d\n", id)
+			return nil
+		})
+	},
+}
+
+func formatPhaseStatus(p *service.PhaseStatus) string {
+	return fmt.Sprintf("%s (week %d of %d)", p.Name, p.Week, p.TotalWeeks)
+}
+
+func init() {
+	rootCmd.AddCommand(phaseCmd)
+	phaseCmd.AddCommand(phaseAddCmd, phaseListCmd, phaseCurrentCmd, phaseDeleteCmd)
+
+	phaseAddCmd.Flags().StringVar(&phaseName, "name", "", "Phase name, e.g. cut, diet-break, bulk")
+	phaseAddCmd.Flags().StringVar(&phaseStart, "start", "", "Start date YYYY-MM-DD (default: day after the last phase, or today)")
+	phaseAddCmd.Flags().StringVar(&phaseDuration, "duration", "", "Duration, e.g. 12w or 14d")
+	phaseAddCmd.Flags().StringVar(&phaseCalories, "calories", "", "Calorie strategy: kcal (2200), maintenance, offset (-500, +10%), or pace (-0.5kg/week, cut, bulk)")
+	phaseAddCmd.Flags().IntVar(&phaseMaintenance, "maintenance-calories", 0, "Maintenance calories for relative strategies (default: profile estimate)")
+	phaseAddCmd.Flags().StringVar(&phaseProtein, "protein", "", "Protein: grams, g/kg, g/lb, percent, or remainder")
+	phaseAddCmd.Flags().StringVar(&phaseCarbs, "carbs", "", "Carbs: grams, g/kg, g/lb, percent, or remainder")
+	phaseAddCmd.Flags().StringVar(&phaseFat, "fat", "", "Fat: grams, g/kg, g/lb, percent, or remainder")
+	_ = phaseAddCmd.MarkFlagRequired("name")
+	_ = phaseAddCmd.MarkFlagRequired("duration")
+	_ = phaseAddCmd.MarkFlagRequired("calories")
+	_ = phaseAddCmd.MarkFlagRequired("protein")
+	_ = phaseAddCmd.MarkFlagRequired("carbs")
+	_ = phaseAddCmd.MarkFlagRequired("fat")
+
+	phaseCurrentCmd.Flags().StringVar(&phaseDate, "date", "", "Date YYYY-MM-DD (default: today)")
+	phaseCurrentCmd.Flags().BoolVar(&phaseJSON, "json", false, "Output JSON")
+}
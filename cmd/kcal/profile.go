@@ -0,0 +1,118 @@
+package kcal
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/model"
+	"github.com/saadjs/kcal-cli/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage the personal profile used for BMR and maintenance formulas",
+}
+
+var (
+	profileSex        string
+	profileBirthDate  string
+	profileHeight     string
+	profileHeightUnit string
+	profileActivity   string
+)
+
+var profileSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Set profile fields (unset flags keep their current value)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		heightChanged := cmd.Flags().Changed("height")
+		if profileSex == "" && profileBirthDate == "" && !heightChanged && profileActivity == "" {
+			return fmt.Errorf("set at least one of --sex, --birth-date, --height, --activity")
+		}
+		in := service.ProfileInput{
+			Sex:           profileSex,
+			BirthDate:     profileBirthDate,
+			HeightUnit:    profileHeightUnit,
+			ActivityLevel: profileActivity,
+		}
+		if heightChanged {
+			height := 0.0
+			if raw := strings.TrimSpace(profileHeight); raw != "" {
+				v, err := strconv.ParseFloat(raw, 64)
+				if err != nil {
+					return fmt.Errorf("invalid --height %q (expected a number, or 0 to unset)", profileHeight)
+				}
+				height = v
+			}
+			in.Height = height
+			in.ClearHeight = height == 0
+		}
+		return withDB(func(sqldb *sql.DB) error {
+			p, err := service.SetProfile(sqldb, in)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "Updated profile")
+			printProfile(cmd, *p)
+			return nil
+		})
+	},
+}
+
+var profileShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the profile",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withDB(func(sqldb *sql.DB) error {
+			p, err := service.GetProfile(sqldb)
+			if err != nil {
+				return err
+			}
+			if p == nil {
+				fmt.Fprintln(cmd.OutOrStdout(), "No profile configured")
+				return nil
+			}
+			printProfile(cmd, *p)
+			return nil
+		})
+	},
+}
+
+func printProfile(cmd *cobra.Command, p model.Profile) {
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Sex: %s\n", valueOrUnset(p.Sex))
+	if p.BirthDate != "" {
+		age, _ := service.AgeOn(p.BirthDate, time.Now())
+		fmt.Fprintf(out, "Birth date: %s (age %d)\n", p.BirthDate, age)
+	} else {
+		fmt.Fprintln(out, "Birth date: (unset)")
+	}
+	if p.HeightCm != nil {
+		fmt.Fprintf(out, "Height: %.1f cm\n", *p.HeightCm)
+	} else {
+		fmt.Fprintln(out, "Height: (unset)")
+	}
+	fmt.Fprintf(out, "Activity level: %s\n", valueOrUnset(p.ActivityLevel))
+}
+
+func valueOrUnset(v string) string {
+	if v == "" {
+		return "(unset)"
+	}
+	return v
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileSetCmd, profileShowCmd)
+
+	profileSetCmd.Flags().StringVar(&profileSex, "sex", "", "Sex for BMR formulas: male or female")
+	profileSetCmd.Flags().StringVar(&profileBirthDate, "birth-date", "", "Birth date YYYY-MM-DD")
+	profileSetCmd.Flags().StringVar(&profileHeight, "height", "", "Height value (0 or empty unsets it)")
+	profileSetCmd.Flags().StringVar(&profileHeightUnit, "height-unit", "cm", "Height unit: cm or in")
+	profileSetCmd.Flags().StringVar(&profileActivity, "activity", "", "Activity level: sedentary, light, moderate, active, very_active")
+}
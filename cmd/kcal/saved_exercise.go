@@ -0,0 +1,150 @@
+package kcal
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/saadjs/kcal-cli/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var savedExerciseCmd = &cobra.Command{
+	Use:   "saved-exercise",
+	Short: "Manage saved exercise templates",
+}
+
+var (
+	savedExerciseName         string
+	savedExerciseType         string
+	savedExerciseDurationMin  int
+	savedExerciseDistanceUnit string
+	savedExerciseFormula      string
+	savedExerciseIntensity    string
+	savedExerciseNotes        string
+	savedExerciseLimit        int
+	savedExerciseQuery        string
+	savedExerciseDistance     float64
+	savedExerciseDate         string
+	savedExerciseTime         string
+)
+
+var savedExerciseAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add saved exercise template",
+	Example: `  kcal saved-exercise add --name "Morning run" --type running --duration 30 --distance-unit km --formula met
+  kcal saved-exercise add --name "Spin class" --type cycling --duration 45 --formula per-minute:11
+  kcal saved-exercise add --name "Climbing" --type climbing --formula fixed:450`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var duration *int
+		if cmd.Flags().Changed("duration-min") {
+			v := savedExerciseDurationMin
+			duration = &v
+		}
+		return withDB(func(sqldb *sql.DB) error {
+			id, err := service.CreateSavedExercise(sqldb, service.CreateSavedExerciseInput{
+				Name:               savedExerciseName,
+				ExerciseType:       savedExerciseType,
+				DefaultDurationMin: duration,
+				DistanceUnit:       savedExerciseDistanceUnit,
+				Formula:            savedExerciseFormula,
+				Intensity:          savedExerciseIntensity,
+				Notes:              savedExerciseNotes,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Added saved exercise %d\n", id)
+			return nil
+		})
+	},
+}
+
+var savedExerciseListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved exercises",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withDB(func(sqldb *sql.DB) error {
+			items, err := service.ListSavedExercises(sqldb, service.ListSavedExercisesFilter{
+				Limit: savedExerciseLimit,
+				Query: savedExerciseQuery,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "ID\tNAME\tTYPE\tDURATION_MIN\tDISTANCE_UNIT\tFORMULA\tINTENSITY\tUSAGE")
+			for _, it := range items {
+				duration := ""
+				if it.DefaultDurationMin != nil {
+					duration = fmt.Sprintf("%d", *it.DefaultDurationMin)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%d\n", it.ID, it.Name, it.ExerciseType, duration, it.DistanceUnit, service.FormatCalorieFormula(it.CalorieFormula, it.CalorieValue), it.Intensity, it.UsageCount)
+			}
+			return nil
+		})
+	},
+}
+
+var savedExerciseLogCmd = &cobra.Command{
+	Use:   "log <id|name>",
+	Short: "Log a saved exercise as an exercise log",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		performedAt, err := parseDateTimeOrNow(savedExerciseDate, savedExerciseTime)
+		if err != nil {
+			return err
+		}
+		in := service.LogSavedExerciseInput{
+			Identifier:  args[0],
+			Intensity:   savedExerciseIntensity,
+			PerformedAt: performedAt,
+			Notes:       savedExerciseNotes,
+		}
+		if cmd.Flags().Changed("duration-min") {
+			v := savedExerciseDurationMin
+			in.DurationMin = &v
+		}
+		if cmd.Flags().Changed("distance") {
+			v := savedExerciseDistance
+			in.Distance = &v
+		}
+		return withDB(func(sqldb *sql.DB) error {
+			res, err := service.LogSavedExercise(sqldb, in)
+			if err != nil {
+				return err
+			}
+			if e := res.Estimate; e != nil {
+				fmt.Fprintf(cmd.OutOrStdout(), "Estimated %d kcal (MET %.1f, %.1fkg on %s)\n", e.Calories, e.MET, e.WeightKg, e.WeightDate)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Logged saved exercise as exercise log %d (%d kcal)\n", res.ExerciseLogID, res.Calories)
+			return nil
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(savedExerciseCmd)
+	savedExerciseCmd.AddCommand(savedExerciseAddCmd, savedExerciseListCmd, savedExerciseLogCmd)
+
+	savedExerciseAddCmd.Flags().StringVar(&savedExerciseName, "name", "", "Saved exercise name")
+	savedExerciseAddCmd.Flags().StringVar(&savedExerciseType, "type", "", "Exercise type (running, cycling, strength, etc.)")
+	savedExerciseAddCmd.Flags().IntVar(&savedExerciseDurationMin, "duration-min", 0, "Default duration in minutes (alias --duration)")
+	savedExerciseAddCmd.Flags().StringVar(&savedExerciseDistanceUnit, "distance-unit", "", "Distance unit for logged distances: km or mi")
+	savedExerciseAddCmd.Flags().StringVar(&savedExerciseFormula, "formula", "", "Calorie formula: fixed:KCAL, per-minute:KCAL or met[:VALUE] (met alone uses the bundled MET table)")
+	savedExerciseAddCmd.Flags().StringVar(&savedExerciseIntensity, "intensity", "", "Default intensity for MET table lookups: light, moderate or vigorous")
+	savedExerciseAddCmd.Flags().StringVar(&savedExerciseNotes, "notes", "", "Notes")
+	savedExerciseAddCmd.Flags().SetNormalizeFunc(exerciseFlagAliases)
+	_ = savedExerciseAddCmd.MarkFlagRequired("name")
+	_ = savedExerciseAddCmd.MarkFlagRequired("type")
+	_ = savedExerciseAddCmd.MarkFlagRequired("formula")
+
+	savedExerciseListCmd.Flags().IntVar(&savedExerciseLimit, "limit", 100, "Result limit")
+	savedExerciseListCmd.Flags().StringVar(&savedExerciseQuery, "query", "", "Filter by name")
+
+	savedExerciseLogCmd.Flags().IntVar(&savedExerciseDurationMin, "duration-min", 0, "Duration in minutes (defaults to the template duration; alias --duration)")
+	savedExerciseLogCmd.Flags().Float64Var(&savedExerciseDistance, "distance", 0, "Distance in the template's distance unit")
+	savedExerciseLogCmd.Flags().StringVar(&savedExerciseIntensity, "intensity", "", "Intensity override for MET table lookups")
+	savedExerciseLogCmd.Flags().StringVar(&savedExerciseDate, "date", "", "Date in YYYY-MM-DD")
+	savedExerciseLogCmd.Flags().StringVar(&savedExerciseTime, "time", "", "Time in HH:MM")
+	savedExerciseLogCmd.Flags().StringVar(&savedExerciseNotes, "notes", "", "Optional notes")
+	savedExerciseLogCmd.Flags().SetNormalizeFunc(exerciseFlagAliases)
+}
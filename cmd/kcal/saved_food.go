@@ -2,7 +2,10 @@ package kcal
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"strings"
 
 	"github.com/saadjs/kcal-cli/internal/service"
@@ -15,37 +18,46 @@ var savedFoodCmd = &cobra.Command{
 }
 
 var (
-	savedFoodName        string
-	savedFoodBrand       string
-	savedFoodCategory    string
-	savedFoodCalories    int
-	savedFoodProtein     float64
-	savedFoodCarbs       float64
-	savedFoodFat         float64
-	savedFoodFiber       float64
-	savedFoodSugar       float64
-	savedFoodSodium      float64
-	savedFoodMicros      string
-	savedFoodServingAmt  float64
-	savedFoodServingUnit string
-	savedFoodSourceType  string
-	savedFoodSourceProv  string
-	savedFoodSourceRef   string
-	savedFoodNotes       string
-	savedFoodMetadata    string
-	savedFoodLimit       int
-	savedFoodIncludeArch bool
-	savedFoodQuery       string
-	savedFoodDate        string
-	savedFoodTime        string
-	savedFoodServings    float64
-	savedFoodEntryID     int64
+	savedFoodName         string
+	savedFoodBrand        string
+	savedFoodCategory     string
+	savedFoodCalories     int
+	savedFoodProtein      float64
+	savedFoodCarbs        float64
+	savedFoodFat          float64
+	savedFoodFiber        float64
+	savedFoodSugar        float64
+	savedFoodSodium       float64
+	savedFoodMicros       string
+	savedFoodServingAmt   float64
+	savedFoodServingUnit  string
+	savedFoodSourceType   string
+	savedFoodSourceProv   string
+	savedFoodSourceRef    string
+	savedFoodNotes        string
+	savedFoodMetadata     string
+	savedFoodLimit        int
+	savedFoodIncludeArch  bool
+	savedFoodQuery        string
+	savedFoodSuggest      bool
+	savedFoodPropagate    bool
+	savedFoodRefreshAll   bool
+	savedFoodRefreshApply bool
+	savedFoodJSON         bool
+	savedFoodLabelIn      string
+	savedFoodDate         string
+	savedFoodTime         string
+	savedFoodServings     float64
+	savedFoodEntryID      int64
 
 	savedFoodProvider      string
 	savedFoodAPIKey        string
 	savedFoodAPIKeyType    string
 	savedFoodFallback      bool
 	savedFoodFallbackOrder string
+
+	savedFoodNameThreshold     float64
+	savedFoodNutrientTolerance float64
 )
 
 var savedFoodAddCmd = &cobra.Command{
@@ -146,10 +158,70 @@ var savedFoodAddFromBarcodeCmd = &cobra.Command{
 	},
 }
 
+var savedFoodAddFromLabelCmd = &cobra.Command{
+	Use:   "add-from-label",
+	Short: "Create saved food from pasted Nutrition Facts label text",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var raw []byte
+		var err error
+		if in := strings.TrimSpace(savedFoodLabelIn); in == "" || in == "-" {
+			raw, err = io.ReadAll(cmd.InOrStdin())
+		} else {
+			raw, err = os.ReadFile(in)
+		}
+		if err != nil {
+			return fmt.Errorf("read label text: %w", err)
+		}
+		label, err := service.ParseNutritionLabel(string(raw))
+		if err != nil {
+			return err
+		}
+		input, err := service.SavedFoodInputFromLabel(label)
+		if err != nil {
+			return err
+		}
+		input.Name = savedFoodName
+		input.Brand = savedFoodBrand
+		input.Category = savedFoodCategory
+		input.Notes = savedFoodNotes
+		return withDB(func(sqldb *sql.DB) error {
+			id, err := service.CreateSavedFood(sqldb, input)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Added saved food %d from %s label: %d kcal, P %.1f, C %.1f, F %.1f per %.2f %s\n", id, strings.ToUpper(label.Format), input.Calories, input.ProteinG, input.CarbsG, input.FatG, input.ServingAmt, input.ServingUnit)
+			for _, note := range label.Notes {
+				fmt.Fprintf(cmd.OutOrStdout(), "note: %s\n", note)
+			}
+			return nil
+		})
+	},
+}
+
 var savedFoodListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List saved foods",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if savedFoodSuggest {
+			if savedFoodIncludeArch {
+				return fmt.Errorf("--suggest cannot be combined with --include-archived")
+			}
+			return withDB(func(sqldb *sql.DB) error {
+				items, err := service.SuggestSavedFoods(sqldb, service.SuggestOptions{
+					Category: savedFoodCategory,
+					Query:    savedFoodQuery,
+					Limit:    savedFoodLimit,
+				})
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), "ID\tNAME\tCATEGORY\tKCAL\tP\tC\tF\tUSAGE\tSCORE")
+				for _, it := range items {
+					fmt.Fprintf(cmd.OutOrStdout(), "%d\t%s\t%s\t%d\t%.1f\t%.1f\t%.1f\t%d\t%.3f\n", it.Food.ID, it.Food.Name, it.Food.DefaultCategory, it.Food.Calories, it.Food.ProteinG, it.Food.CarbsG, it.Food.FatG, it.Food.UsageCount, it.Score)
+				}
+				return nil
+			})
+		}
 		return withDB(func(sqldb *sql.DB) error {
 			items, err := service.ListSavedFoods(sqldb, service.ListSavedFoodsFilter{
 				IncludeArchived: savedFoodIncludeArch,
@@ -202,7 +274,15 @@ var savedFoodUpdateCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return withDB(func(sqldb *sql.DB) error {
-			err := service.UpdateSavedFood(sqldb, args[0], service.UpdateSavedFoodInput{
+			propagate := savedFoodPropagate
+			if !cmd.Flags().Changed("propagate") {
+				configured, err := service.SavedFoodPropagateDefault(sqldb)
+				if err != nil {
+					return err
+				}
+				propagate = configured
+			}
+			report, err := service.UpdateSavedFoodWithOptions(sqldb, args[0], service.UpdateSavedFoodInput{
 				Name:        savedFoodName,
 				Brand:       savedFoodBrand,
 				Category:    savedFoodCategory,
@@ -221,11 +301,22 @@ var savedFoodUpdateCmd = &cobra.Command{
 				SourceRef:   savedFoodSourceRef,
 				Notes:       savedFoodNotes,
 				Metadata:    savedFoodMetadata,
-			})
+			}, service.UpdateSavedFoodOptions{Propagate: propagate})
 			if err != nil {
 				return err
 			}
 			fmt.Fprintf(cmd.OutOrStdout(), "Updated saved food %s\n", args[0])
+			if !propagate {
+				return nil
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Propagated to %d component(s); %d saved meal(s) changed\n", report.ComponentsUpdated, len(report.Meals))
+			if len(report.Meals) == 0 {
+				return nil
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "MEAL_ID\tNAME\tKCAL_BEFORE\tKCAL_AFTER\tKCAL_DELTA\tP_DELTA\tC_DELTA\tF_DELTA")
+			for _, m := range report.Meals {
+				fmt.Fprintf(cmd.OutOrStdout(), "%d\t%s\t%d\t%d\t%+d\t%+.1f\t%+.1f\t%+.1f\n", m.MealID, m.Name, m.CaloriesBefore, m.CaloriesAfter, m.CaloriesDelta, m.ProteinDeltaG, m.CarbsDeltaG, m.FatDeltaG)
+			}
 			return nil
 		})
 	},
@@ -287,6 +378,119 @@ var savedFoodLogCmd = &cobra.Command{
 	},
 }
 
+var savedFoodDedupeCmd = &cobra.Command{
+	Use:   "dedupe",
+	Short: "Find likely duplicate saved foods",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withDB(func(sqldb *sql.DB) error {
+			items, err := service.FindSavedFoodDuplicates(sqldb, service.DedupeSavedFoodsOptions{
+				IncludeArchived:   savedFoodIncludeArch,
+				NameThreshold:     savedFoodNameThreshold,
+				NutrientTolerance: savedFoodNutrientTolerance,
+			})
+			if err != nil {
+				return err
+			}
+			if len(items) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No duplicate saved foods found")
+				return nil
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "KEEP\tDROP\tKEEP_NAME\tDROP_NAME\tSCORE\tREASONS")
+			for _, it := range items {
+				dropName := it.Second.Name
+				if it.SuggestedDropID == it.First.ID {
+					dropName = it.First.Name
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%d\t%d\t%s\t%s\t%.2f\t%s\n", it.SuggestedKeepID, it.SuggestedDropID, it.SuggestedKeepName, dropName, it.Score, strings.Join(it.Reasons, "; "))
+			}
+			return nil
+		})
+	},
+}
+
+var savedFoodMergeCmd = &cobra.Command{
+	Use:   "merge <keep-id|name> <drop-id|name>...",
+	Short: "Merge duplicate saved foods into one",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withDB(func(sqldb *sql.DB) error {
+			res, err := service.MergeSavedFoods(sqldb, args[0], args[1:])
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Merged %d saved food(s) into %d (%s): %d component(s) repointed, usage %d\n", len(res.DroppedIDs), res.KeepID, res.KeepName, res.ComponentsRepointed, res.UsageCount)
+			return nil
+		})
+	},
+}
+
+var savedFoodRefreshCmd = &cobra.Command{
+	Use:   "refresh [id|name]",
+	Short: "Re-fetch saved foods from their source provider and show changes",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		identifier := ""
+		if len(args) == 1 {
+			identifier = args[0]
+		}
+		return withDB(func(sqldb *sql.DB) error {
+			propagate := savedFoodPropagate
+			if !cmd.Flags().Changed("propagate") {
+				configured, err := service.SavedFoodPropagateDefault(sqldb)
+				if err != nil {
+					return err
+				}
+				propagate = configured
+			}
+			results, err := service.RefreshSavedFoods(sqldb, identifier, service.SavedFoodRefreshOptions{
+				All:       savedFoodRefreshAll,
+				Apply:     savedFoodRefreshApply,
+				Propagate: propagate,
+				Credentials: func(provider string) (service.BarcodeLookupOptions, error) {
+					apiKey, err := resolveProviderAPIKey(provider, savedFoodAPIKey)
+					if err != nil {
+						return service.BarcodeLookupOptions{}, err
+					}
+					return service.BarcodeLookupOptions{APIKey: apiKey, APIKeyType: resolveProviderAPIKeyType(provider, savedFoodAPIKeyType)}, nil
+				},
+			})
+			if err != nil {
+				return err
+			}
+			if savedFoodJSON {
+				b, err := json.MarshalIndent(results, "", "  ")
+				if err != nil {
+					return fmt.Errorf("marshal saved food refresh json: %w", err)
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(b))
+				return nil
+			}
+			counts := map[string]int{}
+			fmt.Fprintln(cmd.OutOrStdout(), "ID\tNAME\tSTATUS\tFIELD\tOLD\tNEW")
+			for _, r := range results {
+				counts[r.Status]++
+				if len(r.Diffs) == 0 {
+					fmt.Fprintf(cmd.OutOrStdout(), "%d\t%s\t%s\t%s\t\t\n", r.SavedFoodID, r.Name, r.Status, r.Message)
+					continue
+				}
+				for _, d := range r.Diffs {
+					fmt.Fprintf(cmd.OutOrStdout(), "%d\t%s\t%s\t%s\t%s\t%s\n", r.SavedFoodID, r.Name, r.Status, d.Field, d.Old, d.New)
+				}
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Checked %d saved food(s): %d changed, %d unchanged, %d vanished, %d skipped, %d failed\n",
+				len(results), counts[service.SavedFoodRefreshChanged], counts[service.SavedFoodRefreshUnchanged], counts[service.SavedFoodRefreshVanished], counts[service.SavedFoodRefreshSkipped], counts[service.SavedFoodRefreshFailed])
+			if counts[service.SavedFoodRefreshChanged] > 0 {
+				if savedFoodRefreshApply {
+					fmt.Fprintln(cmd.OutOrStdout(), "Applied provider changes")
+				} else {
+					fmt.Fprintln(cmd.OutOrStdout(), "Dry run: re-run with --apply to save changes")
+				}
+			}
+			return nil
+		})
+	},
+}
+
 func mustEncodeMicros(m service.Micronutrients) string {
 	out, err := service.EncodeMicronutrientsJSON(m)
 	if err != nil {
@@ -318,7 +522,7 @@ func addSavedFoodTemplateFlags(cmd *cobra.Command) {
 
 func init() {
 	rootCmd.AddCommand(savedFoodCmd)
-	savedFoodCmd.AddCommand(savedFoodAddCmd, savedFoodAddFromEntryCmd, savedFoodAddFromBarcodeCmd, savedFoodListCmd, savedFoodShowCmd, savedFoodUpdateCmd, savedFoodArchiveCmd, savedFoodRestoreCmd, savedFoodLogCmd)
+	savedFoodCmd.AddCommand(savedFoodAddCmd, savedFoodAddFromEntryCmd, savedFoodAddFromBarcodeCmd, savedFoodAddFromLabelCmd, savedFoodListCmd, savedFoodShowCmd, savedFoodUpdateCmd, savedFoodArchiveCmd, savedFoodRestoreCmd, savedFoodLogCmd, savedFoodDedupeCmd, savedFoodMergeCmd, savedFoodRefreshCmd)
 
 	addSavedFoodTemplateFlags(savedFoodAddCmd)
 	_ = savedFoodAddCmd.MarkFlagRequired("name")
@@ -336,12 +540,22 @@ func init() {
 	savedFoodAddFromBarcodeCmd.Flags().BoolVar(&savedFoodFallback, "fallback", true, "Try providers in fallback order")
 	savedFoodAddFromBarcodeCmd.Flags().StringVar(&savedFoodFallbackOrder, "fallback-order", "", "Comma-separated fallback provider order")
 
+	savedFoodAddFromLabelCmd.Flags().StringVar(&savedFoodLabelIn, "in", "", "Label text file path (reads stdin when empty or -)")
+	savedFoodAddFromLabelCmd.Flags().StringVar(&savedFoodName, "name", "", "Saved food name")
+	savedFoodAddFromLabelCmd.Flags().StringVar(&savedFoodBrand, "brand", "", "Brand")
+	savedFoodAddFromLabelCmd.Flags().StringVar(&savedFoodCategory, "category", "", "Default category (defaults to snacks)")
+	savedFoodAddFromLabelCmd.Flags().StringVar(&savedFoodNotes, "notes", "", "Notes")
+	_ = savedFoodAddFromLabelCmd.MarkFlagRequired("name")
+
 	savedFoodListCmd.Flags().IntVar(&savedFoodLimit, "limit", 100, "Result limit")
 	savedFoodListCmd.Flags().BoolVar(&savedFoodIncludeArch, "include-archived", false, "Include archived saved foods")
 	savedFoodListCmd.Flags().StringVar(&savedFoodQuery, "query", "", "Filter by name")
+	savedFoodListCmd.Flags().BoolVar(&savedFoodSuggest, "suggest", false, "Rank by frecency, time of day, and category history")
+	savedFoodListCmd.Flags().StringVar(&savedFoodCategory, "category", "", "Category to rank for with --suggest (inferred from time of day by default)")
 
 	addSavedFoodTemplateFlags(savedFoodUpdateCmd)
 	_ = savedFoodUpdateCmd.MarkFlagRequired("name")
+	savedFoodUpdateCmd.Flags().BoolVar(&savedFoodPropagate, "propagate", false, "Re-snapshot saved meal components using this food and recalculate their meals (default from config saved_food_propagate)")
 
 	savedFoodLogCmd.Flags().Float64Var(&savedFoodServings, "servings", 1, "Serving multiplier")
 	savedFoodLogCmd.Flags().StringVar(&savedFoodCategory, "category", "", "Optional category override")
@@ -349,5 +563,16 @@ func init() {
 	savedFoodLogCmd.Flags().StringVar(&savedFoodTime, "time", "", "Time in HH:MM")
 	savedFoodLogCmd.Flags().StringVar(&savedFoodNotes, "notes", "", "Optional notes")
 
+	savedFoodDedupeCmd.Flags().BoolVar(&savedFoodIncludeArch, "include-archived", false, "Include archived saved foods")
+	savedFoodDedupeCmd.Flags().Float64Var(&savedFoodNameThreshold, "name-threshold", 0.8, "Minimum name similarity (0-1) to report")
+	savedFoodDedupeCmd.Flags().Float64Var(&savedFoodNutrientTolerance, "nutrient-tolerance", 0.05, "Relative tolerance for near-identical nutrients")
+
+	savedFoodRefreshCmd.Flags().BoolVar(&savedFoodRefreshAll, "all", false, "Refresh every active saved food with a provider source")
+	savedFoodRefreshCmd.Flags().BoolVar(&savedFoodRefreshApply, "apply", false, "Write provider changes to saved foods")
+	savedFoodRefreshCmd.Flags().BoolVar(&savedFoodPropagate, "propagate", false, "With --apply, also update saved meals using refreshed foods (default from config saved_food_propagate)")
+	savedFoodRefreshCmd.Flags().StringVar(&savedFoodAPIKey, "api-key", "", "Provider API key")
+	savedFoodRefreshCmd.Flags().StringVar(&savedFoodAPIKeyType, "api-key-type", "", "Provider API key type (UPCitemdb)")
+	savedFoodRefreshCmd.Flags().BoolVar(&savedFoodJSON, "json", false, "Output JSON")
+
 	_ = savedFoodEntryID
 }
@@ -20,6 +20,7 @@ var (
 	savedMealIncludeArch bool
 	savedMealLimit       int
 	savedMealQuery       string
+	savedMealSuggest     bool
 	savedMealServings    float64
 	savedMealDate        string
 	savedMealTime        string
@@ -83,6 +84,26 @@ var savedMealListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List saved meals",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if savedMealSuggest {
+			if savedMealIncludeArch {
+				return fmt.Errorf("--suggest cannot be combined with --include-archived")
+			}
+			return withDB(func(sqldb *sql.DB) error {
+				items, err := service.SuggestSavedMeals(sqldb, service.SuggestOptions{
+					Category: savedMealCategory,
+					Query:    savedMealQuery,
+					Limit:    savedMealLimit,
+				})
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), "ID\tNAME\tCATEGORY\tKCAL\tP\tC\tF\tUSAGE\tSCORE")
+				for _, it := range items {
+					fmt.Fprintf(cmd.OutOrStdout(), "%d\t%s\t%s\t%d\t%.1f\t%.1f\t%.1f\t%d\t%.3f\n", it.Meal.ID, it.Meal.Name, it.Meal.DefaultCategory, it.Meal.CaloriesTotal, it.Meal.ProteinTotalG, it.Meal.CarbsTotalG, it.Meal.FatTotalG, it.Meal.UsageCount, it.Score)
+				}
+				return nil
+			})
+		}
 		return withDB(func(sqldb *sql.DB) error {
 			items, err := service.ListSavedMeals(sqldb, service.ListSavedMealsFilter{
 				IncludeArchived: savedMealIncludeArch,
@@ -352,6 +373,8 @@ func init() {
 	savedMealListCmd.Flags().BoolVar(&savedMealIncludeArch, "include-archived", false, "Include archived saved meals")
 	savedMealListCmd.Flags().IntVar(&savedMealLimit, "limit", 100, "Result limit")
 	savedMealListCmd.Flags().StringVar(&savedMealQuery, "query", "", "Filter by name")
+	savedMealListCmd.Flags().BoolVar(&savedMealSuggest, "suggest", false, "Rank by frecency, time of day, and category history")
+	savedMealListCmd.Flags().StringVar(&savedMealCategory, "category", "", "Category to rank for with --suggest (inferred from time of day by default)")
 
 	addSavedMealFlags(savedMealUpdateCmd)
 	_ = savedMealUpdateCmd.MarkFlagRequired("name")
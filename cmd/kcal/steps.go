@@ -0,0 +1,178 @@
+package kcal
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/saadjs/kcal-cli/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var stepsCmd = &cobra.Command{
+	Use:   "steps",
+	Short: "Track daily step totals",
+}
+
+var (
+	stepsDate           string
+	stepsCount          int
+	stepsFromDate       string
+	stepsToDate         string
+	stepsJSON           bool
+	stepsImportIn       string
+	stepsImportDateCol  string
+	stepsImportStepsCol string
+	stepsImportDryRun   bool
+)
+
+var stepsSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Set the step total for a day",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withDB(func(sqldb *sql.DB) error {
+			if err := service.SetDailySteps(sqldb, stepsDate, stepsCount); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Set %d steps for %s\n", stepsCount, dateOrToday(stepsDate))
+			return nil
+		})
+	},
+}
+
+var stepsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List daily step totals",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withDB(func(sqldb *sql.DB) error {
+			items, err := service.ListDailySteps(sqldb, stepsFromDate, stepsToDate)
+			if err != nil {
+				return err
+			}
+			if stepsJSON {
+				b, err := json.MarshalIndent(items, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(b))
+				return nil
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "DATE\tSTEPS\tDISTANCE_KM\tKCAL\tCOUNTS_AS\tSOURCE")
+			for _, it := range items {
+				distance, kcal := "", ""
+				if it.CountsAs != "" {
+					distance = fmt.Sprintf("%.2f", it.DistanceKm)
+					kcal = fmt.Sprintf("%d", it.Calories)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%s\t%d\t%s\t%s\t%s\t%s\n", it.Date, it.Steps, distance, kcal, it.CountsAs, it.Source)
+			}
+			return nil
+		})
+	},
+}
+
+var stepsDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete the step total for a day",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withDB(func(sqldb *sql.DB) error {
+			if err := service.DeleteDailySteps(sqldb, stepsDate); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Deleted steps for %s\n", dateOrToday(stepsDate))
+			return nil
+		})
+	},
+}
+
+var stepsImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import daily step totals from a CSV export",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if strings.TrimSpace(stepsImportIn) == "" {
+			return fmt.Errorf("--in is required")
+		}
+		f, err := os.Open(stepsImportIn)
+		if err != nil {
+			return fmt.Errorf("open steps csv: %w", err)
+		}
+		defer f.Close()
+		return withDB(func(sqldb *sql.DB) error {
+			report, err := service.ImportStepsCSV(sqldb, f, service.StepsImportOptions{
+				DateColumn:  stepsImportDateCol,
+				StepsColumn: stepsImportStepsCol,
+				DryRun:      stepsImportDryRun,
+			})
+			if err != nil {
+				return err
+			}
+			if stepsJSON {
+				b, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(b))
+				return nil
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Columns: %s\n", strings.Join(report.Columns, ", "))
+			for _, w := range report.Warnings {
+				fmt.Fprintf(out, "warning: %s\n", w)
+			}
+			fmt.Fprintf(out, "Steps import report: rows=%d days=%d inserted=%d updated=%d unchanged=%d skipped=%d\n", report.Rows, report.Days, report.Inserted, report.Updated, report.Unchanged, report.Skipped)
+			if report.FirstDate != "" {
+				fmt.Fprintf(out, "Range: %s to %s\n", report.FirstDate, report.LastDate)
+			}
+			if report.DryRun {
+				fmt.Fprintln(out, "Dry run: no steps written; counts show what would change")
+			}
+			return nil
+		})
+	},
+}
+
+// formatStepsDay renders a day's steps with goal progress and the calorie
+// estimate, when there is one.
+func formatStepsDay(d service.StepsDay, goal int) string {
+	out := fmt.Sprintf("%d", d.Steps)
+	if goal > 0 {
+		out += fmt.Sprintf("/%d", goal)
+	}
+	switch d.CountsAs {
+	case service.StepsCaloriesExercise:
+		out += fmt.Sprintf(" (~%d kcal, counted as exercise)", d.Calories)
+	case service.StepsCaloriesBaseline:
+		out += fmt.Sprintf(" (~%d kcal, part of activity baseline)", d.Calories)
+	}
+	return out
+}
+
+func formatStepsGoal(goal int) string {
+	if goal <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (goal %d)", goal)
+}
+
+func init() {
+	rootCmd.AddCommand(stepsCmd)
+	stepsCmd.AddCommand(stepsSetCmd, stepsListCmd, stepsDeleteCmd, stepsImportCmd)
+
+	stepsSetCmd.Flags().StringVar(&stepsDate, "date", "", "Date YYYY-MM-DD (default today)")
+	stepsSetCmd.Flags().IntVar(&stepsCount, "count", 0, "Step total for the day")
+	_ = stepsSetCmd.MarkFlagRequired("count")
+
+	stepsListCmd.Flags().StringVar(&stepsFromDate, "from", "", "Filter from date YYYY-MM-DD")
+	stepsListCmd.Flags().StringVar(&stepsToDate, "to", "", "Filter to date YYYY-MM-DD")
+	stepsListCmd.Flags().BoolVar(&stepsJSON, "json", false, "Output JSON")
+
+	stepsDeleteCmd.Flags().StringVar(&stepsDate, "date", "", "Date YYYY-MM-DD (default today)")
+
+	stepsImportCmd.Flags().StringVar(&stepsImportIn, "in", "", "Input CSV file path")
+	stepsImportCmd.Flags().StringVar(&stepsImportDateCol, "date-column", "", "Header of the date column (default: detect)")
+	stepsImportCmd.Flags().StringVar(&stepsImportStepsCol, "steps-column", "", "Header of the step count column (default: detect)")
+	stepsImportCmd.Flags().BoolVar(&stepsImportDryRun, "dry-run", false, "Report what would be imported without writing")
+	stepsImportCmd.Flags().BoolVar(&stepsJSON, "json", false, "Output the report as JSON")
+}
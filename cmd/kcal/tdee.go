@@ -0,0 +1,79 @@
+package kcal
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var tdeeCmd = &cobra.Command{
+	Use:   "tdee",
+	Short: "Estimate total daily energy expenditure",
+}
+
+var (
+	tdeeWindow string
+	tdeeEnd    string
+	tdeeJSON   bool
+)
+
+var tdeeEstimateCmd = &cobra.Command{
+	Use:   "estimate",
+	Short: "Estimate maintenance calories from logged intake and weight trend",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := tdeeEstimateOptions(tdeeWindow, tdeeEnd)
+		if err != nil {
+			return err
+		}
+		return withDB(func(sqldb *sql.DB) error {
+			est, err := service.EstimateTDEE(sqldb, opts)
+			if err != nil {
+				return err
+			}
+			if tdeeJSON {
+				b, err := json.MarshalIndent(est, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(b))
+				return nil
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "TDEE estimate (%s to %s, %d days)\n", est.FromDate, est.ToDate, est.WindowDays)
+			fmt.Fprintf(out, "Estimated TDEE: %d kcal/day (95%% band %d-%d, %s confidence)\n", est.EstimatedTDEE, est.Low, est.High, est.Confidence)
+			fmt.Fprintf(out, "Average intake: %.0f kcal/day\n", est.AvgIntakeCalories)
+			fmt.Fprintf(out, "Weight trend: %.2f -> %.2f kg (%+.2f kg, %+.2f kg/week)\n", est.StartTrendWeightKg, est.EndTrendWeightKg, est.WeightChangeKg, est.WeightChangeKgPerWeek)
+			fmt.Fprintf(out, "Logging completeness: %d/%d days (%.0f%%), %d weigh-ins\n", est.LoggedDays, est.WindowDays, est.LoggingCompleteness*100, est.WeighIns)
+			return nil
+		})
+	},
+}
+
+func tdeeEstimateOptions(window, end string) (service.TDEEEstimateOptions, error) {
+	days, err := service.ParseWindowDays(window)
+	if err != nil {
+		return service.TDEEEstimateOptions{}, err
+	}
+	opts := service.TDEEEstimateOptions{WindowDays: days}
+	if strings.TrimSpace(end) != "" {
+		opts.End, err = time.ParseInLocation("2006-01-02", strings.TrimSpace(end), time.Local)
+		if err != nil {
+			return opts, fmt.Errorf("invalid --end %q (expected YYYY-MM-DD)", end)
+		}
+	}
+	return opts, nil
+}
+
+func init() {
+	rootCmd.AddCommand(tdeeCmd)
+	tdeeCmd.AddCommand(tdeeEstimateCmd)
+
+	tdeeEstimateCmd.Flags().StringVar(&tdeeWindow, "window", "28d", "Window length, e.g. 28d or 4w")
+	tdeeEstimateCmd.Flags().StringVar(&tdeeEnd, "end", "", "Last day included YYYY-MM-DD (default yesterday)")
+	tdeeEstimateCmd.Flags().BoolVar(&tdeeJSON, "json", false, "Output as JSON")
+}
@@ -31,11 +31,31 @@ var todayCmd = &cobra.Command{
 			fmt.Fprintf(cmd.OutOrStdout(), "Date: %s\n", status.Date)
 			fmt.Fprintf(cmd.OutOrStdout(), "Intake: %d kcal\n", status.IntakeCalories)
 			fmt.Fprintf(cmd.OutOrStdout(), "Exercise: %d kcal\n", status.ExerciseCalories)
+			if status.Steps != nil {
+				fmt.Fprintf(cmd.OutOrStdout(), "Steps: %s\n", formatStepsDay(*status.Steps, status.StepsGoal))
+			}
 			fmt.Fprintf(cmd.OutOrStdout(), "Net: %d kcal\n", status.NetCalories)
 			fmt.Fprintf(cmd.OutOrStdout(), "Macros: P %.1fg | C %.1fg | F %.1fg\n", status.ProteinG, status.CarbsG, status.FatG)
+			if status.Phase != nil {
+				fmt.Fprintf(cmd.OutOrStdout(), "Phase: %s\n", formatPhaseStatus(status.Phase))
+			}
 			if status.HasGoal {
-				fmt.Fprintf(cmd.OutOrStdout(), "Goal: %d kcal | P %.1fg | C %.1fg | F %.1fg\n", status.GoalCalories, status.GoalProteinG, status.GoalCarbsG, status.GoalFatG)
+				label := "Goal"
+				if status.GoalSchedule != "" {
+					label = fmt.Sprintf("Goal (%s)", status.GoalSchedule)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%s: %d kcal | P %.1fg | C %.1fg | F %.1fg\n", label, status.GoalCalories, status.GoalProteinG, status.GoalCarbsG, status.GoalFatG)
+				if status.ExerciseCalories > 0 || status.EatBackCalories > 0 {
+					fmt.Fprintf(cmd.OutOrStdout(), "Eat-back: %d kcal (%s)\n", status.EatBackCalories, status.EatBackPolicy)
+				}
 				fmt.Fprintf(cmd.OutOrStdout(), "Remaining: %d kcal | P %.1fg | C %.1fg | F %.1fg\n", status.RemainingCalories, status.RemainingProteinG, status.RemainingCarbsG, status.RemainingFatG)
+				if w := status.WeeklyBudget; w != nil {
+					fmt.Fprintf(cmd.OutOrStdout(), "Weekly budget (%s to %s): %d of %d kcal left, %s\n", w.WeekStart, w.WeekEnd, w.RemainingCalories, w.BudgetCalories, formatBankedCalories(w.BankedCalories))
+					fmt.Fprintf(cmd.OutOrStdout(), "Today's allowance: %d kcal (%d left, %d days left in week)\n", w.DailyAllowance, w.RemainingToday, w.DaysLeft)
+				}
+				for _, t := range status.NutrientTargets {
+					fmt.Fprintf(cmd.OutOrStdout(), "%s\n", formatNutrientTargetStatus(t))
+				}
 			} else {
 				fmt.Fprintln(cmd.OutOrStdout(), "Goal: not set")
 			}
@@ -44,6 +64,31 @@ var todayCmd = &cobra.Command{
 	},
 }
 
+func formatBankedCalories(banked int) string {
+	switch {
+	case banked > 0:
+		return fmt.Sprintf("%d kcal banked", banked)
+	case banked < 0:
+		return fmt.Sprintf("%d kcal borrowed", -banked)
+	default:
+		return "on budget so far"
+	}
+}
+
+func formatNutrientTargetStatus(t service.NutrientTargetStatus) string {
+	progress := fmt.Sprintf("%s: %.1f/%g %s", t.Nutrient, t.Actual, t.Target, t.Unit)
+	switch {
+	case t.Kind == service.NutrientTargetMax && t.Over > 0:
+		return fmt.Sprintf("%s (cap, over by %.1f %s)", progress, t.Over, t.Unit)
+	case t.Kind == service.NutrientTargetMax:
+		return fmt.Sprintf("%s (cap, %.1f %s left)", progress, t.Remaining, t.Unit)
+	case t.Met:
+		return fmt.Sprintf("%s (min, met)", progress)
+	default:
+		return fmt.Sprintf("%s (min, %.1f %s to go)", progress, t.Remaining, t.Unit)
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(todayCmd)
 	todayCmd.Flags().StringVar(&todayDate, "date", "", "Date YYYY-MM-DD (default today)")
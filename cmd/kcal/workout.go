@@ -0,0 +1,213 @@
+package kcal
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/saadjs/kcal-cli/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var workoutCmd = &cobra.Command{
+	Use:   "workout",
+	Short: "Log strength workouts with sets, reps and load",
+}
+
+var (
+	workoutSets        []string
+	workoutMuscles     []string
+	workoutUnit        string
+	workoutExerciseID  int64
+	workoutType        string
+	workoutCalories    int
+	workoutDurationMin int
+	workoutIntensity   string
+	workoutDate        string
+	workoutTime        string
+	workoutNotes       string
+	workoutFromDate    string
+	workoutToDate      string
+	workoutLimit       int
+	workoutJSON        bool
+)
+
+var workoutAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add a workout, or add sets to an existing exercise log",
+	Example: `  kcal workout add --set "squat=5x5@100" --set "bench press=3x8@80 rpe8" --duration 60
+  kcal workout add --exercise-id 12 --set "pull-up=3x10" --muscle pull-up=back`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		groups := map[string]string{}
+		for _, spec := range workoutMuscles {
+			lift, group, ok := strings.Cut(spec, "=")
+			if !ok || strings.TrimSpace(lift) == "" || strings.TrimSpace(group) == "" {
+				return fmt.Errorf("invalid --muscle %q (use LIFT=GROUP)", spec)
+			}
+			groups[strings.ToLower(strings.TrimSpace(lift))] = group
+		}
+		in := service.WorkoutInput{ExerciseLogID: workoutExerciseID}
+		for _, spec := range workoutSets {
+			set, err := service.ParseWorkoutSet(spec, workoutUnit)
+			if err != nil {
+				return err
+			}
+			set.MuscleGroup = groups[set.Lift]
+			in.Sets = append(in.Sets, set)
+		}
+
+		if workoutExerciseID > 0 {
+			for _, name := range []string{"type", "calories", "duration-min", "intensity", "date", "time", "notes"} {
+				if cmd.Flags().Changed(name) {
+					return fmt.Errorf("--%s cannot be combined with --exercise-id", name)
+				}
+			}
+		} else {
+			performedAt, err := parseDateTimeOrNow(workoutDate, workoutTime)
+			if err != nil {
+				return err
+			}
+			in.Log = service.ExerciseLogInput{
+				ExerciseType:   workoutType,
+				CaloriesBurned: workoutCalories,
+				PerformedAt:    performedAt,
+				Notes:          workoutNotes,
+			}
+			if cmd.Flags().Changed("duration-min") {
+				v := workoutDurationMin
+				in.Log.DurationMin = &v
+			}
+		}
+
+		return withDB(func(sqldb *sql.DB) error {
+			if workoutExerciseID == 0 {
+				log, err := estimateExerciseIfNeeded(cmd, sqldb, in.Log, workoutIntensity)
+				if err != nil {
+					return err
+				}
+				in.Log = log
+			}
+			id, err := service.AddWorkout(sqldb, in)
+			if err != nil {
+				return err
+			}
+			w, err := service.GetWorkout(sqldb, id)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Saved workout on exercise log %d: %d sets, %d reps, volume %.1fkg\n", id, w.Sets, w.Reps, w.VolumeKg)
+			return nil
+		})
+	},
+}
+
+var workoutShowCmd = &cobra.Command{
+	Use:   "show <exercise-log-id>",
+	Short: "Show a workout's sets by lift",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseInt64Arg("exercise log id", args[0])
+		if err != nil {
+			return err
+		}
+		perKg, err := service.WeightFromKg(1, workoutUnit)
+		if err != nil {
+			return err
+		}
+		return withDB(func(sqldb *sql.DB) error {
+			w, err := service.GetWorkout(sqldb, id)
+			if err != nil {
+				return err
+			}
+			if workoutJSON {
+				b, err := json.MarshalIndent(w, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(b))
+				return nil
+			}
+			log := w.ExerciseLog
+			header := fmt.Sprintf("Workout %d: %s on %s, %d kcal", log.ID, log.ExerciseType, log.PerformedAt.Local().Format("2006-01-02 15:04"), log.CaloriesBurned)
+			if log.DurationMin != nil {
+				header += fmt.Sprintf(", %d min", *log.DurationMin)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), header)
+			fmt.Fprintln(cmd.OutOrStdout(), "LIFT\tMUSCLE_GROUP\tSET\tREPS\tWEIGHT\tRPE\tE1RM")
+			for _, lift := range w.Lifts {
+				for i, s := range lift.Sets {
+					rpe := ""
+					if s.RPE != nil {
+						rpe = fmt.Sprintf("%.1f", *s.RPE)
+					}
+					e1rm := ""
+					if v := service.EstimatedOneRepMax(s.WeightKg, s.Reps, s.RPE); v > 0 {
+						e1rm = fmt.Sprintf("%.1f", v*perKg)
+					}
+					fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%d\t%d\t%.1f\t%s\t%s\n", lift.Lift, lift.MuscleGroup, i+1, s.Reps, s.WeightKg*perKg, rpe, e1rm)
+				}
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Total: sets=%d reps=%d volume=%.1f%s\n", w.Sets, w.Reps, w.VolumeKg*perKg, workoutUnit)
+			return nil
+		})
+	},
+}
+
+var workoutListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List workouts with set counts and volume",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		perKg, err := service.WeightFromKg(1, workoutUnit)
+		if err != nil {
+			return err
+		}
+		return withDB(func(sqldb *sql.DB) error {
+			items, err := service.ListWorkouts(sqldb, service.WorkoutFilter{FromDate: workoutFromDate, ToDate: workoutToDate, Limit: workoutLimit})
+			if err != nil {
+				return err
+			}
+			if workoutJSON {
+				b, err := json.MarshalIndent(items, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(b))
+				return nil
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "ID\tDATE\tTYPE\tKCAL_BURNED\tLIFTS\tSETS\tREPS\tVOLUME")
+			for _, item := range items {
+				fmt.Fprintf(cmd.OutOrStdout(), "%d\t%s\t%s\t%d\t%d\t%d\t%d\t%.1f%s\n", item.ExerciseLogID, item.PerformedAt.Local().Format("2006-01-02 15:04"), item.ExerciseType, item.CaloriesBurned, item.Lifts, item.Sets, item.Reps, item.VolumeKg*perKg, workoutUnit)
+			}
+			return nil
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(workoutCmd)
+	workoutCmd.AddCommand(workoutAddCmd, workoutShowCmd, workoutListCmd)
+
+	workoutAddCmd.Flags().StringArrayVar(&workoutSets, "set", nil, "Sets as LIFT=SETSxREPS@WEIGHT [rpeN], e.g. \"bench press=3x8@80 rpe8\" (repeatable)")
+	workoutAddCmd.Flags().StringArrayVar(&workoutMuscles, "muscle", nil, "Muscle group for a lift as LIFT=GROUP (repeatable)")
+	workoutAddCmd.Flags().StringVar(&workoutUnit, "unit", "kg", "Weight unit for loads without a suffix: kg or lb")
+	workoutAddCmd.Flags().Int64Var(&workoutExerciseID, "exercise-id", 0, "Add the sets to an existing exercise log")
+	workoutAddCmd.Flags().StringVar(&workoutType, "type", service.DefaultWorkoutType, "Exercise type of the new exercise log")
+	workoutAddCmd.Flags().IntVar(&workoutCalories, "calories", 0, "Calories burned (estimated from MET values and latest body weight when omitted)")
+	workoutAddCmd.Flags().IntVar(&workoutDurationMin, "duration-min", 0, "Duration in minutes (required without --calories; alias --duration)")
+	workoutAddCmd.Flags().StringVar(&workoutIntensity, "intensity", "", "Intensity for MET estimates: light, moderate or vigorous")
+	workoutAddCmd.Flags().StringVar(&workoutDate, "date", "", "Date YYYY-MM-DD")
+	workoutAddCmd.Flags().StringVar(&workoutTime, "time", "", "Time HH:MM")
+	workoutAddCmd.Flags().StringVar(&workoutNotes, "notes", "", "Optional notes")
+	workoutAddCmd.Flags().SetNormalizeFunc(exerciseFlagAliases)
+	_ = workoutAddCmd.MarkFlagRequired("set")
+
+	workoutShowCmd.Flags().StringVar(&workoutUnit, "unit", "kg", "Weight output unit: kg or lb")
+	workoutShowCmd.Flags().BoolVar(&workoutJSON, "json", false, "Output JSON")
+
+	workoutListCmd.Flags().StringVar(&workoutFromDate, "from", "", "Filter from date YYYY-MM-DD")
+	workoutListCmd.Flags().StringVar(&workoutToDate, "to", "", "Filter to date YYYY-MM-DD")
+	workoutListCmd.Flags().IntVar(&workoutLimit, "limit", 50, "Result limit")
+	workoutListCmd.Flags().StringVar(&workoutUnit, "unit", "kg", "Weight output unit: kg or lb")
+	workoutListCmd.Flags().BoolVar(&workoutJSON, "json", false, "Output JSON")
+}
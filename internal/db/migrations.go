@@ -333,6 +333,152 @@ CREATE TABLE IF NOT EXISTS saved_meal_components (
 );
 
 CREATE INDEX IF NOT EXISTS idx_saved_meal_components_meal_position ON saved_meal_components(saved_meal_id, position);
+`,
+	},
+	{
+		version: 12,
+		name:    "goal_nutrient_targets",
+		sql: `
+ALTER TABLE goals ADD COLUMN nutrient_targets_json TEXT NOT NULL DEFAULT '';
+`,
+	},
+	{
+		version: 13,
+		name:    "goal_schedules",
+		sql: `
+CREATE TABLE IF NOT EXISTS goal_schedules (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  goal_id INTEGER NOT NULL,
+  day_key TEXT NOT NULL,
+  calories INTEGER NOT NULL CHECK(calories >= 0),
+  protein_g REAL NOT NULL CHECK(protein_g >= 0),
+  carbs_g REAL NOT NULL CHECK(carbs_g >= 0),
+  fat_g REAL NOT NULL CHECK(fat_g >= 0),
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE(goal_id, day_key),
+  FOREIGN KEY(goal_id) REFERENCES goals(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS day_types (
+  date TEXT PRIMARY KEY,
+  day_type TEXT NOT NULL CHECK(day_type IN ('training', 'rest')),
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+`,
+	},
+	{
+		version: 14,
+		name:    "goal_macro_rules",
+		sql: `
+ALTER TABLE goals ADD COLUMN macro_rules_json TEXT NOT NULL DEFAULT '';
+`,
+	},
+	{
+		version: 15,
+		name:    "user_profile",
+		sql: `
+CREATE TABLE IF NOT EXISTS user_profile (
+  id INTEGER PRIMARY KEY CHECK(id = 1),
+  sex TEXT NOT NULL DEFAULT '' CHECK(sex IN ('', 'male', 'female')),
+  birth_date TEXT NOT NULL DEFAULT '',
+  height_cm REAL CHECK(height_cm IS NULL OR height_cm > 0),
+  activity_level TEXT NOT NULL DEFAULT '',
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+`,
+	},
+	{
+		version: 16,
+		name:    "phases",
+		sql: `
+CREATE TABLE IF NOT EXISTS phases (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name TEXT NOT NULL,
+  start_date TEXT NOT NULL UNIQUE,
+  duration_days INTEGER NOT NULL CHECK(duration_days > 0),
+  calorie_strategy TEXT NOT NULL,
+  maintenance_calories INTEGER CHECK(maintenance_calories IS NULL OR maintenance_calories > 0),
+  calories INTEGER NOT NULL CHECK(calories >= 0),
+  protein_spec TEXT NOT NULL,
+  carbs_spec TEXT NOT NULL,
+  fat_spec TEXT NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+`,
+	},
+	{
+		version: 17,
+		name:    "body_circumferences",
+		sql: `
+ALTER TABLE body_measurements ADD COLUMN waist_cm REAL CHECK(waist_cm IS NULL OR waist_cm > 0);
+ALTER TABLE body_measurements ADD COLUMN hip_cm REAL CHECK(hip_cm IS NULL OR hip_cm > 0);
+ALTER TABLE body_measurements ADD COLUMN neck_cm REAL CHECK(neck_cm IS NULL OR neck_cm > 0);
+ALTER TABLE body_measurements ADD COLUMN chest_cm REAL CHECK(chest_cm IS NULL OR chest_cm > 0);
+ALTER TABLE body_measurements ADD COLUMN arm_cm REAL CHECK(arm_cm IS NULL OR arm_cm > 0);
+ALTER TABLE body_measurements ADD COLUMN thigh_cm REAL CHECK(thigh_cm IS NULL OR thigh_cm > 0);
+`,
+	},
+	{
+		version: 18,
+		name:    "workout_sets",
+		sql: `
+CREATE TABLE IF NOT EXISTS workout_sets (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  exercise_log_id INTEGER NOT NULL,
+  position INTEGER NOT NULL CHECK(position > 0),
+  lift TEXT NOT NULL,
+  muscle_group TEXT NOT NULL,
+  reps INTEGER NOT NULL CHECK(reps > 0),
+  weight_kg REAL NOT NULL DEFAULT 0 CHECK(weight_kg >= 0),
+  rpe REAL CHECK(rpe IS NULL OR (rpe >= 1 AND rpe <= 10)),
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY(exercise_log_id) REFERENCES exercise_logs(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_workout_sets_exercise_log_id ON workout_sets(exercise_log_id, position);
+`,
+	},
+	{
+		version: 19,
+		name:    "saved_exercises",
+		sql: `
+CREATE TABLE IF NOT EXISTS saved_exercises (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name TEXT NOT NULL,
+  name_norm TEXT NOT NULL UNIQUE,
+  exercise_type TEXT NOT NULL,
+  default_duration_min INTEGER CHECK(default_duration_min IS NULL OR default_duration_min > 0),
+  distance_unit TEXT NOT NULL DEFAULT '' CHECK(distance_unit IN ('', 'km', 'mi')),
+  calorie_formula TEXT NOT NULL CHECK(calorie_formula IN ('fixed', 'per_minute', 'met')),
+  calorie_value REAL NOT NULL DEFAULT 0 CHECK(calorie_value >= 0),
+  intensity TEXT NOT NULL DEFAULT '',
+  notes TEXT NOT NULL DEFAULT '',
+  usage_count INTEGER NOT NULL DEFAULT 0 CHECK(usage_count >= 0),
+  last_used_at DATETIME,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+`,
+	},
+	{
+		version: 20,
+		name:    "daily_steps",
+		sql: `
+CREATE TABLE IF NOT EXISTS daily_steps (
+  date TEXT PRIMARY KEY,
+  steps INTEGER NOT NULL CHECK(steps >= 0),
+  source TEXT NOT NULL DEFAULT 'manual',
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+`,
+	},
+	{
+		version: 21,
+		name:    "phase_goal_id",
+		sql: `
+ALTER TABLE phases ADD COLUMN goal_id INTEGER REFERENCES goals(id) ON DELETE SET NULL;
+UPDATE phases SET goal_id = (SELECT g.id FROM goals g WHERE g.effective_date = phases.start_date);
 `,
 	},
 }
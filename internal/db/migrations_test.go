@@ -29,8 +29,8 @@ func TestApplyMigrationsIdempotentAndSeedsDefaults(t *testing.T) {
 	if err := sqldb.QueryRow(`SELECT COUNT(1) FROM schema_migrations`).Scan(&migrationCount); err != nil {
 		t.Fatalf("count migrations: %v", err)
 	}
-	if migrationCount != 11 {
-		t.Fatalf("expected 11 migration versions, got %d", migrationCount)
+	if migrationCount != 21 {
+		t.Fatalf("expected 21 migration versions, got %d", migrationCount)
 	}
 
 	var metadataColCount int
@@ -145,6 +145,40 @@ func TestApplyMigrationsIdempotentAndSeedsDefaults(t *testing.T) {
 		t.Fatalf("expected saved_meal_components table to exist")
 	}
 
+	var goalTargetsColCount int
+	if err := sqldb.QueryRow(`SELECT COUNT(1) FROM pragma_table_info('goals') WHERE name = 'nutrient_targets_json'`).Scan(&goalTargetsColCount); err != nil {
+		t.Fatalf("check goals nutrient_targets_json column: %v", err)
+	}
+	if goalTargetsColCount != 1 {
+		t.Fatalf("expected nutrient_targets_json column in goals table")
+	}
+
+	var goalMacroRulesColCount int
+	if err := sqldb.QueryRow(`SELECT COUNT(1) FROM pragma_table_info('goals') WHERE name = 'macro_rules_json'`).Scan(&goalMacroRulesColCount); err != nil {
+		t.Fatalf("check goals macro_rules_json column: %v", err)
+	}
+	if goalMacroRulesColCount != 1 {
+		t.Fatalf("expected macro_rules_json column in goals table")
+	}
+
+	var waistColCount int
+	if err := sqldb.QueryRow(`SELECT COUNT(1) FROM pragma_table_info('body_measurements') WHERE name = 'waist_cm'`).Scan(&waistColCount); err != nil {
+		t.Fatalf("check body_measurements waist_cm column: %v", err)
+	}
+	if waistColCount != 1 {
+		t.Fatalf("expected waist_cm column in body_measurements table")
+	}
+
+	for _, table := range []string{"goal_schedules", "day_types", "user_profile", "phases", "workout_sets", "saved_exercises", "daily_steps"} {
+		var tableCount int
+		if err := sqldb.QueryRow(`SELECT COUNT(1) FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&tableCount); err != nil {
+			t.Fatalf("check %s table: %v", table, err)
+		}
+		if tableCount != 1 {
+			t.Fatalf("expected %s table to exist", table)
+		}
+	}
+
 	var categoryCount int
 	if err := sqldb.QueryRow(`SELECT COUNT(1) FROM categories`).Scan(&categoryCount); err != nil {
 		t.Fatalf("count categories: %v", err)
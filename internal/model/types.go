@@ -30,13 +30,15 @@ type Entry struct {
 }
 
 type Goal struct {
-	ID            int64
-	Calories      int
-	ProteinG      float64
-	CarbsG        float64
-	FatG          float64
-	EffectiveDate string
-	CreatedAt     time.Time
+	ID              int64
+	Calories        int
+	ProteinG        float64
+	CarbsG          float64
+	FatG            float64
+	NutrientTargets string
+	MacroRules      string
+	EffectiveDate   string
+	CreatedAt       time.Time
 }
 
 type Recipe struct {
@@ -58,6 +60,12 @@ type BodyMeasurement struct {
 	WeightKg   float64
 	BodyFatPct *float64
 	Notes      string
+	WaistCm    *float64
+	HipCm      *float64
+	NeckCm     *float64
+	ChestCm    *float64
+	ArmCm      *float64
+	ThighCm    *float64
 }
 
 type BodyGoal struct {
@@ -69,6 +77,28 @@ type BodyGoal struct {
 	CreatedAt        time.Time
 }
 
+type Profile struct {
+	Sex           string
+	BirthDate     string
+	HeightCm      *float64
+	ActivityLevel string
+	UpdatedAt     time.Time
+}
+
+type Phase struct {
+	ID                  int64
+	Name                string
+	StartDate           string
+	DurationDays        int
+	CalorieStrategy     string
+	MaintenanceCalories *int
+	Calories            int
+	ProteinSpec         string
+	CarbsSpec           string
+	FatSpec             string
+	CreatedAt           time.Time
+}
+
 type RecipeIngredient struct {
 	ID         int64
 	RecipeID   int64
@@ -97,6 +127,26 @@ type ExerciseLog struct {
 	UpdatedAt      time.Time
 }
 
+type WorkoutSet struct {
+	ID            int64
+	ExerciseLogID int64
+	Position      int
+	Lift          string
+	MuscleGroup   string
+	Reps          int
+	WeightKg      float64
+	RPE           *float64
+	CreatedAt     time.Time
+}
+
+type DailySteps struct {
+	Date      string
+	Steps     int
+	Source    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
 type SavedFood struct {
 	ID                int64
 	Name              string
@@ -126,6 +176,23 @@ type SavedFood struct {
 	UpdatedAt         time.Time
 }
 
+type SavedExercise struct {
+	ID                 int64
+	Name               string
+	NameNorm           string
+	ExerciseType       string
+	DefaultDurationMin *int
+	DistanceUnit       string
+	CalorieFormula     string
+	CalorieValue       float64
+	Intensity          string
+	Notes              string
+	UsageCount         int
+	LastUsedAt         *time.Time
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
 type SavedMeal struct {
 	ID                int64
 	Name              string
@@ -3,6 +3,7 @@ package openfoodfacts
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,6 +13,10 @@ import (
 	"time"
 )
 
+// ErrNotFound is returned when the provider has no record for a barcode or
+// query.
+var ErrNotFound = errors.New("no openfoodfacts product found")
+
 const defaultBaseURL = "https://world.openfoodfacts.org"
 
 type FoodLookup struct {
@@ -77,7 +82,7 @@ func (c *Client) LookupBarcode(ctx context.Context, barcode string) (FoodLookup,
 		return FoodLookup{}, body, fmt.Errorf("decode openfoodfacts response: %w", err)
 	}
 	if parsed.Status != 1 || parsed.Product.ProductName == "" {
-		return FoodLookup{}, body, fmt.Errorf("no openfoodfacts product found for barcode %q", barcode)
+		return FoodLookup{}, body, fmt.Errorf("%w for barcode %q", ErrNotFound, barcode)
 	}
 
 	servingAmount, servingUnit := parseServing(parsed.Product)
@@ -146,7 +151,7 @@ func (c *Client) SearchFoods(ctx context.Context, query string, limit int) ([]Fo
 		return nil, body, fmt.Errorf("decode openfoodfacts search response: %w", err)
 	}
 	if len(parsed.Products) == 0 {
-		return nil, body, fmt.Errorf("no openfoodfacts product found for query %q", query)
+		return nil, body, fmt.Errorf("%w for query %q", ErrNotFound, query)
 	}
 	out := make([]FoodLookup, 0, len(parsed.Products))
 	for _, p := range parsed.Products {
@@ -177,7 +182,7 @@ func (c *Client) SearchFoods(ctx context.Context, query string, limit int) ([]Fo
 		})
 	}
 	if len(out) == 0 {
-		return nil, body, fmt.Errorf("no openfoodfacts product found for query %q", query)
+		return nil, body, fmt.Errorf("%w for query %q", ErrNotFound, query)
 	}
 	return out, body, nil
 }
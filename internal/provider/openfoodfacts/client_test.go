@@ -2,6 +2,7 @@ package openfoodfacts
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -50,6 +51,25 @@ func TestLookupBarcodeParsesOpenFoodFactsResponse(t *testing.T) {
 	}
 }
 
+func TestLookupBarcodeReturnsErrNotFound(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status": 0, "status_verbose": "product not found"}`))
+	}))
+	defer ts.Close()
+
+	c := &Client{BaseURL: ts.URL, HTTPClient: ts.Client()}
+	_, _, err := c.LookupBarcode(context.Background(), "12345678")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if err.Error() != `no openfoodfacts product found for barcode "12345678"` {
+		t.Fatalf("unexpected error message %q", err.Error())
+	}
+}
+
 func TestSearchFoodsParsesOpenFoodFactsResponse(t *testing.T) {
 	t.Parallel()
 
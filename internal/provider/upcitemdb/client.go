@@ -3,6 +3,7 @@ package upcitemdb
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,6 +13,10 @@ import (
 	"time"
 )
 
+// ErrNotFound is returned when the provider has no record for a barcode or
+// query.
+var ErrNotFound = errors.New("no upcitemdb product found")
+
 const defaultBaseURL = "https://api.upcitemdb.com"
 
 type FoodLookup struct {
@@ -90,7 +95,7 @@ func (c *Client) LookupBarcode(ctx context.Context, barcode string) (FoodLookup,
 		return FoodLookup{}, body, fmt.Errorf("decode upcitemdb response: %w", err)
 	}
 	if strings.ToUpper(parsed.Code) != "OK" || len(parsed.Items) == 0 {
-		return FoodLookup{}, body, fmt.Errorf("no upcitemdb product found for barcode %q", barcode)
+		return FoodLookup{}, body, fmt.Errorf("%w for barcode %q", ErrNotFound, barcode)
 	}
 	item := parsed.Items[0]
 	amount, unit := parseServing(item.Size)
@@ -170,7 +175,7 @@ func (c *Client) SearchFoods(ctx context.Context, query string, limit int) ([]Fo
 		return nil, body, fmt.Errorf("decode upcitemdb search response: %w", err)
 	}
 	if strings.ToUpper(parsed.Code) != "OK" || len(parsed.Items) == 0 {
-		return nil, body, fmt.Errorf("no upcitemdb product found for query %q", query)
+		return nil, body, fmt.Errorf("%w for query %q", ErrNotFound, query)
 	}
 	out := make([]FoodLookup, 0, len(parsed.Items))
 	for _, item := range parsed.Items {
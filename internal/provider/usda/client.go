@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,6 +12,10 @@ import (
 	"time"
 )
 
+// ErrNotFound is returned when the provider has no record for a barcode or
+// query.
+var ErrNotFound = errors.New("no USDA branded food found")
+
 const defaultBaseURL = "https://api.nal.usda.gov"
 
 type FoodLookup struct {
@@ -63,7 +68,7 @@ func (c *Client) LookupBarcode(ctx context.Context, barcode string) (FoodLookup,
 	}
 	food, exact, ok := selectBarcodeMatch(foods, barcode)
 	if !ok {
-		return FoodLookup{}, body, fmt.Errorf("no USDA branded food found for barcode %q", barcode)
+		return FoodLookup{}, body, fmt.Errorf("%w for barcode %q", ErrNotFound, barcode)
 	}
 	out := mapUSDAFoodLookup(food, barcode, exact)
 	return out, body, nil
@@ -128,7 +133,7 @@ func (c *Client) searchFoods(ctx context.Context, httpClient *http.Client, baseU
 		return nil, body, fmt.Errorf("decode USDA response: %w", err)
 	}
 	if len(parsed.Foods) == 0 {
-		return nil, body, fmt.Errorf("no USDA branded food found for query %q", query)
+		return nil, body, fmt.Errorf("%w for query %q", ErrNotFound, query)
 	}
 	return parsed.Foods, body, nil
 }
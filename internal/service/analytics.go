@@ -27,17 +27,45 @@ type DaySummary struct {
 	IntakeCalories        int     `json:"intake_calories"`
 	ExerciseCalories      int     `json:"exercise_calories"`
 	NetCalories           int     `json:"net_calories"`
+	Steps                 int     `json:"steps,omitempty"`
+	StepCalories          int     `json:"step_calories,omitempty"`
 	EffectiveGoalCalories int     `json:"effective_goal_calories"`
 	EffectiveGoalProtein  float64 `json:"effective_goal_protein_g"`
 	EffectiveGoalCarbs    float64 `json:"effective_goal_carbs_g"`
 	EffectiveGoalFat      float64 `json:"effective_goal_fat_g"`
+	GoalSchedule          string  `json:"goal_schedule,omitempty"`
+	EatBackCalories       int     `json:"eat_back_calories"`
+	Phase                 string  `json:"phase,omitempty"`
+	PhaseWeek             int     `json:"phase_week,omitempty"`
+
+	// stepExerciseCalories is the part of StepCalories credited as exercise.
+	stepExerciseCalories int
 }
 
 type BodyPoint struct {
-	Date       string   `json:"date"`
-	WeightKg   float64  `json:"weight_kg"`
-	BodyFatPct *float64 `json:"body_fat_pct,omitempty"`
-	LeanMassKg *float64 `json:"lean_mass_kg,omitempty"`
+	Date          string       `json:"date"`
+	WeightKg      float64      `json:"weight_kg"`
+	TrendWeightKg float64      `json:"trend_weight_kg"`
+	BodyFatPct    *float64     `json:"body_fat_pct,omitempty"`
+	BodyFatSource string       `json:"body_fat_source,omitempty"`
+	LeanMassKg    *float64     `json:"lean_mass_kg,omitempty"`
+	WaistCm       *float64     `json:"waist_cm,omitempty"`
+	HipCm         *float64     `json:"hip_cm,omitempty"`
+	NeckCm        *float64     `json:"neck_cm,omitempty"`
+	ChestCm       *float64     `json:"chest_cm,omitempty"`
+	ArmCm         *float64     `json:"arm_cm,omitempty"`
+	ThighCm       *float64     `json:"thigh_cm,omitempty"`
+	Metrics       *BodyMetrics `json:"metrics,omitempty"`
+}
+
+// CircumferenceTrend compares the first and last measurement of one site in
+// the range.
+type CircumferenceTrend struct {
+	Site         string  `json:"site"`
+	Measurements int     `json:"measurements"`
+	StartCm      float64 `json:"start_cm"`
+	EndCm        float64 `json:"end_cm"`
+	ChangeCm     float64 `json:"change_cm"`
 }
 
 type BodyGoalProgress struct {
@@ -50,20 +78,28 @@ type BodyGoalProgress struct {
 	BodyFatDeltaPct         *float64 `json:"body_fat_delta_pct,omitempty"`
 }
 
+// BodySummary reports weight changes from the smoothed trend weight rather than
+// the raw first and last weigh-ins, which carry day-to-day water swings.
 type BodySummary struct {
-	MeasurementsCount int               `json:"measurements_count"`
-	StartWeightKg     float64           `json:"start_weight_kg,omitempty"`
-	EndWeightKg       float64           `json:"end_weight_kg,omitempty"`
-	WeightChangeKg    float64           `json:"weight_change_kg,omitempty"`
-	AvgWeeklyChangeKg float64           `json:"avg_weekly_change_kg,omitempty"`
-	StartBodyFatPct   *float64          `json:"start_body_fat_pct,omitempty"`
-	EndBodyFatPct     *float64          `json:"end_body_fat_pct,omitempty"`
-	BodyFatChangePct  *float64          `json:"body_fat_change_pct,omitempty"`
-	StartLeanMassKg   *float64          `json:"start_lean_mass_kg,omitempty"`
-	EndLeanMassKg     *float64          `json:"end_lean_mass_kg,omitempty"`
-	LeanMassChangeKg  *float64          `json:"lean_mass_change_kg,omitempty"`
-	GoalProgress      *BodyGoalProgress `json:"goal_progress,omitempty"`
-	Points            []BodyPoint       `json:"points"`
+	MeasurementsCount  int                  `json:"measurements_count"`
+	StartWeightKg      float64              `json:"start_weight_kg,omitempty"`
+	EndWeightKg        float64              `json:"end_weight_kg,omitempty"`
+	StartTrendWeightKg float64              `json:"start_trend_weight_kg,omitempty"`
+	EndTrendWeightKg   float64              `json:"end_trend_weight_kg,omitempty"`
+	WeightChangeKg     float64              `json:"weight_change_kg,omitempty"`
+	AvgWeeklyChangeKg  float64              `json:"avg_weekly_change_kg,omitempty"`
+	StartBodyFatPct    *float64             `json:"start_body_fat_pct,omitempty"`
+	EndBodyFatPct      *float64             `json:"end_body_fat_pct,omitempty"`
+	BodyFatChangePct   *float64             `json:"body_fat_change_pct,omitempty"`
+	StartLeanMassKg    *float64             `json:"start_lean_mass_kg,omitempty"`
+	EndLeanMassKg      *float64             `json:"end_lean_mass_kg,omitempty"`
+	LeanMassChangeKg   *float64             `json:"lean_mass_change_kg,omitempty"`
+	Circumferences     []CircumferenceTrend `json:"circumferences,omitempty"`
+	StartMetrics       *BodyMetrics         `json:"start_metrics,omitempty"`
+	EndMetrics         *BodyMetrics         `json:"end_metrics,omitempty"`
+	GoalProgress       *BodyGoalProgress    `json:"goal_progress,omitempty"`
+	Forecast           *BodyGoalForecast    `json:"forecast,omitempty"`
+	Points             []BodyPoint          `json:"points"`
 }
 
 type ConfidenceStats struct {
@@ -80,30 +116,34 @@ type MetadataSummary struct {
 }
 
 type AnalyticsReport struct {
-	FromDate                      string              `json:"from_date"`
-	ToDate                        string              `json:"to_date"`
-	TotalCalories                 int                 `json:"total_calories"`
-	TotalIntakeCalories           int                 `json:"total_intake_calories"`
-	TotalExerciseCalories         int                 `json:"total_exercise_calories"`
-	TotalNetCalories              int                 `json:"total_net_calories"`
-	TotalProtein                  float64             `json:"total_protein_g"`
-	TotalCarbs                    float64             `json:"total_carbs_g"`
-	TotalFat                      float64             `json:"total_fat_g"`
-	DaysWithEntries               int                 `json:"days_with_entries"`
-	AverageCaloriesPerDay         float64             `json:"avg_calories_per_day"`
-	AverageIntakeCaloriesPerDay   float64             `json:"avg_intake_calories_per_day"`
-	AverageExerciseCaloriesPerDay float64             `json:"avg_exercise_calories_per_day"`
-	AverageNetCaloriesPerDay      float64             `json:"avg_net_calories_per_day"`
-	AverageProteinPerDay          float64             `json:"avg_protein_per_day"`
-	AverageCarbsPerDay            float64             `json:"avg_carbs_per_day"`
-	AverageFatPerDay              float64             `json:"avg_fat_per_day"`
-	HighestDay                    *DaySummary         `json:"highest_day,omitempty"`
-	LowestDay                     *DaySummary         `json:"lowest_day,omitempty"`
-	Adherence                     AdherenceSummary    `json:"adherence"`
-	ByCategory                    []CategoryBreakdown `json:"by_category"`
-	Days                          []DaySummary        `json:"days"`
-	Body                          BodySummary         `json:"body"`
-	Metadata                      MetadataSummary     `json:"metadata"`
+	FromDate                      string                 `json:"from_date"`
+	ToDate                        string                 `json:"to_date"`
+	TotalCalories                 int                    `json:"total_calories"`
+	TotalIntakeCalories           int                    `json:"total_intake_calories"`
+	TotalExerciseCalories         int                    `json:"total_exercise_calories"`
+	TotalNetCalories              int                    `json:"total_net_calories"`
+	TotalProtein                  float64                `json:"total_protein_g"`
+	TotalCarbs                    float64                `json:"total_carbs_g"`
+	TotalFat                      float64                `json:"total_fat_g"`
+	DaysWithEntries               int                    `json:"days_with_entries"`
+	AverageCaloriesPerDay         float64                `json:"avg_calories_per_day"`
+	AverageIntakeCaloriesPerDay   float64                `json:"avg_intake_calories_per_day"`
+	AverageExerciseCaloriesPerDay float64                `json:"avg_exercise_calories_per_day"`
+	AverageNetCaloriesPerDay      float64                `json:"avg_net_calories_per_day"`
+	AverageProteinPerDay          float64                `json:"avg_protein_per_day"`
+	AverageCarbsPerDay            float64                `json:"avg_carbs_per_day"`
+	AverageFatPerDay              float64                `json:"avg_fat_per_day"`
+	HighestDay                    *DaySummary            `json:"highest_day,omitempty"`
+	LowestDay                     *DaySummary            `json:"lowest_day,omitempty"`
+	Adherence                     AdherenceSummary       `json:"adherence"`
+	WeeklyAdherence               WeeklyAdherenceSummary `json:"weekly_adherence"`
+	Phase                         *PhaseStatus           `json:"phase,omitempty"`
+	ByCategory                    []CategoryBreakdown    `json:"by_category"`
+	Days                          []DaySummary           `json:"days"`
+	Body                          BodySummary            `json:"body"`
+	Exercise                      ExerciseSummary        `json:"exercise"`
+	Strength                      StrengthSummary        `json:"strength"`
+	Metadata                      MetadataSummary        `json:"metadata"`
 }
 
 type AdherenceSummary struct {
@@ -111,6 +151,9 @@ type AdherenceSummary struct {
 	WithinGoalDays  int     `json:"within_goal_days"`
 	PercentWithin   float64 `json:"percent_within_goal"`
 	SkippedGoalDays int     `json:"days_without_goal"`
+
+	IncludesNutrientTargets bool   `json:"includes_nutrient_targets"`
+	EatBackPolicy           string `json:"eat_back_policy"`
 }
 
 func AnalyticsRange(db *sql.DB, from, to time.Time, tolerance float64) (*AnalyticsReport, error) {
@@ -134,6 +177,9 @@ func AnalyticsRange(db *sql.DB, from, to time.Time, tolerance float64) (*Analyti
 		return nil, err
 	}
 	days := mergeDaySummaries(intakeByDay, exerciseByDay)
+	if err := applyStepsToDays(db, days, from, to); err != nil {
+		return nil, err
+	}
 	report.Days = days
 	report.DaysWithEntries = len(days)
 
@@ -168,6 +214,21 @@ func AnalyticsRange(db *sql.DB, from, to time.Time, tolerance float64) (*Analyti
 		return nil, err
 	}
 	report.Adherence = adherence
+	report.WeeklyAdherence, err = calculateWeeklyAdherence(days)
+	if err != nil {
+		return nil, err
+	}
+	phases, err := ListPhases(db)
+	if err != nil {
+		return nil, err
+	}
+	for i := range days {
+		if phase := phaseStatusAt(phases, days[i].Date); phase != nil {
+			days[i].Phase = phase.Name
+			days[i].PhaseWeek = phase.Week
+		}
+	}
+	report.Phase = phaseStatusAt(phases, report.ToDate)
 	report.Days = days
 	if report.DaysWithEntries > 0 {
 		report.HighestDay, report.LowestDay = extremeDays(days)
@@ -179,6 +240,18 @@ func AnalyticsRange(db *sql.DB, from, to time.Time, tolerance float64) (*Analyti
 	}
 	report.Body = body
 
+	exercise, err := calculateExerciseSummary(db, from, to)
+	if err != nil {
+		return nil, err
+	}
+	report.Exercise = exercise
+
+	strength, err := calculateStrengthSummary(db, from, to)
+	if err != nil {
+		return nil, err
+	}
+	report.Strength = strength
+
 	metadata, err := calculateMetadataSummary(db, from, to)
 	if err != nil {
 		return nil, err
@@ -303,8 +376,18 @@ ORDER BY SUM(e.calories) DESC
 
 func calculateAdherence(db *sql.DB, days []DaySummary, tolerance float64) (AdherenceSummary, error) {
 	out := AdherenceSummary{}
+	includeTargets, err := NutrientTargetsAdherenceEnabled(db)
+	if err != nil {
+		return out, err
+	}
+	out.IncludesNutrientTargets = includeTargets
+	eatBack, err := LoadEatBackSettings(db)
+	if err != nil {
+		return out, err
+	}
+	out.EatBackPolicy = eatBack.String()
 	for i := range days {
-		goal, err := CurrentGoal(db, days[i].Date)
+		goal, err := ResolveGoalForDate(db, days[i].Date)
 		if err != nil {
 			return out, err
 		}
@@ -312,18 +395,33 @@ func calculateAdherence(db *sql.DB, days []DaySummary, tolerance float64) (Adher
 			out.SkippedGoalDays++
 			continue
 		}
-		effectiveCalories, effectiveProtein, effectiveCarbs, effectiveFat := effectiveGoalTargets(*goal, days[i].ExerciseCalories)
+		days[i].GoalSchedule = goal.Schedule
+		credit, err := eatBack.eatBackCredit(db, days[i].Date, days[i].ExerciseCalories, days[i].stepExerciseCalories)
+		if err != nil {
+			return out, err
+		}
+		days[i].EatBackCalories = credit
+		effectiveCalories, effectiveProtein, effectiveCarbs, effectiveFat := effectiveGoalTargets(goal.Goal, credit)
 		days[i].EffectiveGoalCalories = effectiveCalories
 		days[i].EffectiveGoalProtein = effectiveProtein
 		days[i].EffectiveGoalCarbs = effectiveCarbs
 		days[i].EffectiveGoalFat = effectiveFat
 
 		out.EvaluatedDays++
-		if float64(days[i].NetCalories) <= float64(goal.Calories) &&
+		if days[i].IntakeCalories <= effectiveCalories &&
 			AdherenceWithin(days[i].Protein, effectiveProtein, tolerance) &&
 			AdherenceWithin(days[i].Carbs, effectiveCarbs, tolerance) &&
 			AdherenceWithin(days[i].Fat, effectiveFat, tolerance) {
-			out.WithinGoalDays++
+			met := true
+			if includeTargets {
+				met, err = nutrientTargetsMet(db, days[i].Date, goal.NutrientTargets)
+				if err != nil {
+					return out, err
+				}
+			}
+			if met {
+				out.WithinGoalDays++
+			}
 		}
 	}
 	if out.EvaluatedDays > 0 {
@@ -332,6 +430,25 @@ func calculateAdherence(db *sql.DB, days []DaySummary, tolerance float64) (Adher
 	return out, nil
 }
 
+func nutrientTargetsMet(db *sql.DB, date, targetsJSON string) (bool, error) {
+	targets, err := ParseNutrientTargetsJSON(targetsJSON)
+	if err != nil {
+		return false, err
+	}
+	statuses, err := EvaluateNutrientTargets(db, date, targets)
+	if err != nil {
+		return false, err
+	}
+	for _, s := range statuses {
+		if !s.Met {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// effectiveGoalTargets raises the goal by the eaten-back exercise calories and
+// spreads them across macros in proportion to the goal's macro energy.
 func effectiveGoalTargets(goal model.Goal, exerciseCalories int) (int, float64, float64, float64) {
 	effectiveCalories := goal.Calories + exerciseCalories
 
@@ -355,8 +472,12 @@ func effectiveGoalTargets(goal model.Goal, exerciseCalories int) (int, float64,
 }
 
 func calculateBodySummary(db *sql.DB, from, to time.Time) (BodySummary, error) {
+	profile, err := GetProfile(db)
+	if err != nil {
+		return BodySummary{}, err
+	}
 	rows, err := db.Query(`
-SELECT measured_at, weight_kg, body_fat_pct
+SELECT `+bodyMeasurementColumns+`
 FROM body_measurements
 WHERE measured_at >= ? AND measured_at < ?
 ORDER BY measured_at ASC
@@ -368,23 +489,26 @@ ORDER BY measured_at ASC
 
 	summary := BodySummary{Points: make([]BodyPoint, 0)}
 	for rows.Next() {
-		var measured string
-		var weight float64
-		var bodyFat sql.NullFloat64
-		if err := rows.Scan(&measured, &weight, &bodyFat); err != nil {
+		m, err := scanBodyMeasurement(rows.Scan)
+		if err != nil {
 			return BodySummary{}, fmt.Errorf("scan body measurement analytics row: %w", err)
 		}
-		t, err := time.Parse(time.RFC3339, measured)
-		if err != nil {
-			return BodySummary{}, fmt.Errorf("parse measured_at analytics row: %w", err)
+		p := BodyPoint{
+			Date:     m.MeasuredAt.Format("2006-01-02"),
+			WeightKg: m.WeightKg,
+			WaistCm:  m.WaistCm,
+			HipCm:    m.HipCm,
+			NeckCm:   m.NeckCm,
+			ChestCm:  m.ChestCm,
+			ArmCm:    m.ArmCm,
+			ThighCm:  m.ThighCm,
 		}
-		p := BodyPoint{Date: t.Format("2006-01-02"), WeightKg: weight}
-		if bodyFat.Valid {
-			bf := bodyFat.Float64
-			p.BodyFatPct = &bf
-			lean := leanMassKg(weight, bf)
+		p.BodyFatPct, p.BodyFatSource = ResolveBodyFat(profile, *m)
+		if p.BodyFatPct != nil {
+			lean := leanMassKg(m.WeightKg, *p.BodyFatPct)
 			p.LeanMassKg = &lean
 		}
+		p.Metrics = MeasurementMetrics(profile, *m)
 		summary.Points = append(summary.Points, p)
 	}
 	if err := rows.Err(); err != nil {
@@ -396,11 +520,25 @@ ORDER BY measured_at ASC
 		return summary, nil
 	}
 
+	trend, err := smoothedDailyWeights(db, from, to, DefaultWeightTrendSmoothing)
+	if err != nil {
+		return BodySummary{}, err
+	}
+	trendByDay := make(map[string]float64, len(trend))
+	for _, t := range trend {
+		trendByDay[t.Date] = t.TrendWeightKg
+	}
+	for i := range summary.Points {
+		summary.Points[i].TrendWeightKg = trendByDay[summary.Points[i].Date]
+	}
+
 	start := summary.Points[0]
 	end := summary.Points[summary.MeasurementsCount-1]
 	summary.StartWeightKg = start.WeightKg
 	summary.EndWeightKg = end.WeightKg
-	summary.WeightChangeKg = end.WeightKg - start.WeightKg
+	summary.StartTrendWeightKg = start.TrendWeightKg
+	summary.EndTrendWeightKg = end.TrendWeightKg
+	summary.WeightChangeKg = end.TrendWeightKg - start.TrendWeightKg
 
 	daysSpan := to.Sub(from).Hours() / 24
 	if daysSpan >= 7 {
@@ -423,6 +561,9 @@ ORDER BY measured_at ASC
 		delta := *summary.EndLeanMassKg - *summary.StartLeanMassKg
 		summary.LeanMassChangeKg = &delta
 	}
+	summary.Circumferences = circumferenceTrends(summary.Points)
+	summary.StartMetrics = start.Metrics
+	summary.EndMetrics = end.Metrics
 
 	latest := end
 	goal, err := CurrentBodyGoal(db, end.Date)
@@ -443,11 +584,39 @@ ORDER BY measured_at ASC
 			progress.BodyFatDeltaPct = &delta
 		}
 		summary.GoalProgress = progress
+
+		summary.Forecast, err = ForecastBodyGoal(db, BodyGoalForecastOptions{Date: to})
+		if err != nil {
+			return BodySummary{}, err
+		}
 	}
 
 	return summary, nil
 }
 
+func circumferenceTrends(points []BodyPoint) []CircumferenceTrend {
+	var out []CircumferenceTrend
+	for i, site := range CircumferenceSites {
+		t := CircumferenceTrend{Site: site}
+		for _, p := range points {
+			v := []*float64{p.WaistCm, p.HipCm, p.NeckCm, p.ChestCm, p.ArmCm, p.ThighCm}[i]
+			if v == nil {
+				continue
+			}
+			if t.Measurements == 0 {
+				t.StartCm = *v
+			}
+			t.EndCm = *v
+			t.Measurements++
+		}
+		if t.Measurements > 0 {
+			t.ChangeCm = t.EndCm - t.StartCm
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
 func leanMassKg(weightKg, bodyFatPct float64) float64 {
 	return weightKg * (1 - (bodyFatPct / 100))
 }
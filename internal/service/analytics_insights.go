@@ -130,6 +130,10 @@ type InsightsStreaks struct {
 	Logging    Streak `json:"logging"`
 	Exercise   Streak `json:"exercise"`
 	WithinGoal Streak `json:"within_goal"`
+	// Steps counts days reaching StepsGoal, or days with any steps when no
+	// goal is set.
+	Steps     Streak `json:"steps"`
+	StepsGoal int    `json:"steps_goal,omitempty"`
 }
 
 type RollingWindowPoint struct {
@@ -209,6 +213,11 @@ func AnalyticsInsightsRange(db *sql.DB, from, to time.Time, tolerance float64, g
 		return nil, err
 	}
 
+	stepsSettings, err := LoadStepsSettings(db)
+	if err != nil {
+		return nil, err
+	}
+
 	current := summarizeDaySeries(currentDays, currentAdherence)
 	previous := summarizeDaySeries(prevDays, previousAdherence)
 
@@ -231,7 +240,7 @@ func AnalyticsInsightsRange(db *sql.DB, from, to time.Time, tolerance float64, g
 		Consistency: computeConsistency(currentDays),
 		Extremes:    computeExtremes(currentDays),
 		Series:      bucketDaySeries(currentDays, resolvedGranularity),
-		Streaks:     computeStreaks(currentDays),
+		Streaks:     computeStreaks(currentDays, stepsSettings.Goal),
 		RollingWindows: InsightsRollingWindows{
 			Window7:  computeRollingWindow(currentDays, 7),
 			Window30: computeRollingWindow(currentDays, 30),
@@ -288,6 +297,9 @@ func loadFullDaySeries(db *sql.DB, from, to time.Time, tolerance float64) ([]Day
 		day.NetCalories = day.IntakeCalories - day.ExerciseCalories
 		days = append(days, day)
 	}
+	if err := applyStepsToDays(db, days, from, to); err != nil {
+		return nil, AdherenceSummary{}, err
+	}
 
 	adherence, err := calculateAdherence(db, days, tolerance)
 	if err != nil {
@@ -432,7 +444,7 @@ func computeExtremes(days []DaySummary) InsightsExtremes {
 	}
 }
 
-func computeStreaks(days []DaySummary) InsightsStreaks {
+func computeStreaks(days []DaySummary, stepsGoal int) InsightsStreaks {
 	logCurrent, logLongest := computeBooleanStreak(days, func(d DaySummary) bool {
 		return d.IntakeCalories > 0
 	})
@@ -443,11 +455,19 @@ func computeStreaks(days []DaySummary) InsightsStreaks {
 		if d.EffectiveGoalCalories == 0 && d.EffectiveGoalProtein == 0 && d.EffectiveGoalCarbs == 0 && d.EffectiveGoalFat == 0 {
 			return false
 		}
-		return float64(d.NetCalories) <= float64(d.EffectiveGoalCalories) &&
+		// EffectiveGoalCalories already includes the eat-back credit, so
+		// exercise must not come off intake as well.
+		return d.IntakeCalories <= d.EffectiveGoalCalories &&
 			AdherenceWithin(d.Protein, d.EffectiveGoalProtein, 0.10) &&
 			AdherenceWithin(d.Carbs, d.EffectiveGoalCarbs, 0.10) &&
 			AdherenceWithin(d.Fat, d.EffectiveGoalFat, 0.10)
 	})
+	stepsCurrent, stepsLongest := computeBooleanStreak(days, func(d DaySummary) bool {
+		if stepsGoal > 0 {
+			return d.Steps >= stepsGoal
+		}
+		return d.Steps > 0
+	})
 	return InsightsStreaks{
 		Logging: Streak{
 			Current: logCurrent,
@@ -461,6 +481,11 @@ func computeStreaks(days []DaySummary) InsightsStreaks {
 			Current: goalCurrent,
 			Longest: goalLongest,
 		},
+		Steps: Streak{
+			Current: stepsCurrent,
+			Longest: stepsLongest,
+		},
+		StepsGoal: stepsGoal,
 	}
 }
 
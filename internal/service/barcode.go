@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -293,6 +294,23 @@ func lookupBarcodeWithClient(db *sql.DB, provider string, client barcodeClient,
 	return result, nil
 }
 
+// ErrProviderNotFound marks a provider miss, as opposed to a failed request.
+var ErrProviderNotFound = errors.New("provider record not found")
+
+// providerNotFoundError keeps the provider's message while matching
+// ErrProviderNotFound.
+type providerNotFoundError struct{ err error }
+
+func (e providerNotFoundError) Error() string   { return e.err.Error() }
+func (e providerNotFoundError) Unwrap() []error { return []error{ErrProviderNotFound, e.err} }
+
+func providerLookupError(err, notFound error) error {
+	if errors.Is(err, notFound) {
+		return providerNotFoundError{err: err}
+	}
+	return err
+}
+
 type usdaClientAdapter struct {
 	client *usda.Client
 }
@@ -300,7 +318,7 @@ type usdaClientAdapter struct {
 func (a *usdaClientAdapter) LookupBarcode(ctx context.Context, barcode string) (BarcodeLookupResult, []byte, error) {
 	food, raw, err := a.client.LookupBarcode(ctx, barcode)
 	if err != nil {
-		return BarcodeLookupResult{}, nil, err
+		return BarcodeLookupResult{}, nil, providerLookupError(err, usda.ErrNotFound)
 	}
 	return BarcodeLookupResult{
 		Description:    food.Description,
@@ -323,7 +341,7 @@ func (a *usdaClientAdapter) LookupBarcode(ctx context.Context, barcode string) (
 func (a *usdaClientAdapter) SearchFoods(ctx context.Context, query string, limit int) ([]BarcodeLookupResult, []byte, error) {
 	foods, raw, err := a.client.SearchFoods(ctx, query, limit)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, providerLookupError(err, usda.ErrNotFound)
 	}
 	out := make([]BarcodeLookupResult, 0, len(foods))
 	for _, food := range foods {
@@ -353,7 +371,7 @@ type openFoodFactsClientAdapter struct {
 func (a *openFoodFactsClientAdapter) LookupBarcode(ctx context.Context, barcode string) (BarcodeLookupResult, []byte, error) {
 	food, raw, err := a.client.LookupBarcode(ctx, barcode)
 	if err != nil {
-		return BarcodeLookupResult{}, nil, err
+		return BarcodeLookupResult{}, nil, providerLookupError(err, openfoodfacts.ErrNotFound)
 	}
 	return BarcodeLookupResult{
 		Description:    food.Description,
@@ -376,7 +394,7 @@ func (a *openFoodFactsClientAdapter) LookupBarcode(ctx context.Context, barcode
 func (a *openFoodFactsClientAdapter) SearchFoods(ctx context.Context, query string, limit int) ([]BarcodeLookupResult, []byte, error) {
 	foods, raw, err := a.client.SearchFoods(ctx, query, limit)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, providerLookupError(err, openfoodfacts.ErrNotFound)
 	}
 	out := make([]BarcodeLookupResult, 0, len(foods))
 	for _, food := range foods {
@@ -406,7 +424,7 @@ type upcItemDBClientAdapter struct {
 func (a *upcItemDBClientAdapter) LookupBarcode(ctx context.Context, barcode string) (BarcodeLookupResult, []byte, error) {
 	food, raw, err := a.client.LookupBarcode(ctx, barcode)
 	if err != nil {
-		return BarcodeLookupResult{}, nil, err
+		return BarcodeLookupResult{}, nil, providerLookupError(err, upcitemdb.ErrNotFound)
 	}
 	return BarcodeLookupResult{
 		Description:    food.Description,
@@ -429,7 +447,7 @@ func (a *upcItemDBClientAdapter) LookupBarcode(ctx context.Context, barcode stri
 func (a *upcItemDBClientAdapter) SearchFoods(ctx context.Context, query string, limit int) ([]BarcodeLookupResult, []byte, error) {
 	foods, raw, err := a.client.SearchFoods(ctx, query, limit)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, providerLookupError(err, upcitemdb.ErrNotFound)
 	}
 	out := make([]BarcodeLookupResult, 0, len(foods))
 	for _, food := range foods {
@@ -3,10 +3,13 @@ package service
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"path/filepath"
 	"testing"
 
 	"github.com/saadjs/kcal-cli/internal/db"
+	"github.com/saadjs/kcal-cli/internal/provider/openfoodfacts"
 )
 
 type fakeBarcodeClient struct {
@@ -185,6 +188,18 @@ func TestLookupBarcodeWithFallbackUsesNextProvider(t *testing.T) {
 	}
 }
 
+func TestProviderLookupErrorMarksMisses(t *testing.T) {
+	miss := fmt.Errorf("%w for barcode %q", openfoodfacts.ErrNotFound, "12345678")
+	err := providerLookupError(miss, openfoodfacts.ErrNotFound)
+	if !errors.Is(err, ErrProviderNotFound) || !errors.Is(err, openfoodfacts.ErrNotFound) || err.Error() != miss.Error() {
+		t.Fatalf("expected provider miss to match ErrProviderNotFound with its message, got %v", err)
+	}
+	failure := fmt.Errorf("openfoodfacts request failed with status 503")
+	if err := providerLookupError(failure, openfoodfacts.ErrNotFound); errors.Is(err, ErrProviderNotFound) {
+		t.Fatalf("expected request failure to stay unmarked, got %v", err)
+	}
+}
+
 func TestDeriveNutritionCompleteness(t *testing.T) {
 	if got := deriveNutritionCompleteness(BarcodeLookupResult{}); got != "unknown" {
 		t.Fatalf("expected unknown completeness, got %q", got)
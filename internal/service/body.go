@@ -15,6 +15,9 @@ type BodyMeasurementInput struct {
 	BodyFatPct *float64
 	MeasuredAt time.Time
 	Notes      string
+	// Circumferences are in LengthUnit (cm or in); nil sites are not recorded.
+	Circumferences Circumferences
+	LengthUnit     string
 }
 
 type BodyMeasurementFilter struct {
@@ -24,11 +27,16 @@ type BodyMeasurementFilter struct {
 	Limit    int
 }
 
+// UpdateBodyMeasurementInput replaces a measurement; nil circumference sites
+// keep their stored value.
 type UpdateBodyMeasurementInput struct {
 	ID int64
 	BodyMeasurementInput
 }
 
+// bodyMeasurementColumns matches the scan order of scanBodyMeasurement.
+const bodyMeasurementColumns = `id, measured_at, weight_kg, body_fat_pct, IFNULL(notes, ''), waist_cm, hip_cm, neck_cm, chest_cm, arm_cm, thigh_cm`
+
 func AddBodyMeasurement(db *sql.DB, in BodyMeasurementInput) (int64, error) {
 	weightKg, err := convertWeightToKg(in.Weight, in.Unit)
 	if err != nil {
@@ -39,13 +47,17 @@ func AddBodyMeasurement(db *sql.DB, in BodyMeasurementInput) (int64, error) {
 			return 0, fmt.Errorf("body-fat must be between 0 and 100")
 		}
 	}
+	c, err := in.Circumferences.toCm(in.LengthUnit)
+	if err != nil {
+		return 0, err
+	}
 	if in.MeasuredAt.IsZero() {
 		in.MeasuredAt = time.Now()
 	}
 	res, err := db.Exec(`
-INSERT INTO body_measurements(measured_at, weight_kg, body_fat_pct, notes)
-VALUES(?, ?, ?, ?)
-`, in.MeasuredAt.Format(time.RFC3339), weightKg, in.BodyFatPct, strings.TrimSpace(in.Notes))
+INSERT INTO body_measurements(measured_at, weight_kg, body_fat_pct, notes, waist_cm, hip_cm, neck_cm, chest_cm, arm_cm, thigh_cm)
+VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`, in.MeasuredAt.Format(time.RFC3339), weightKg, in.BodyFatPct, strings.TrimSpace(in.Notes), c.Waist, c.Hip, c.Neck, c.Chest, c.Arm, c.Thigh)
 	if err != nil {
 		return 0, fmt.Errorf("add body measurement: %w", err)
 	}
@@ -60,7 +72,7 @@ func ListBodyMeasurements(db *sql.DB, f BodyMeasurementFilter) ([]model.BodyMeas
 	if strings.TrimSpace(f.Date) != "" && (strings.TrimSpace(f.FromDate) != "" || strings.TrimSpace(f.ToDate) != "") {
 		return nil, fmt.Errorf("--date cannot be combined with --from or --to")
 	}
-	query := `SELECT id, measured_at, weight_kg, body_fat_pct, IFNULL(notes, '') FROM body_measurements WHERE 1=1`
+	query := `SELECT ` + bodyMeasurementColumns + ` FROM body_measurements WHERE 1=1`
 	args := make([]any, 0)
 
 	if strings.TrimSpace(f.Date) != "" {
@@ -103,22 +115,11 @@ func ListBodyMeasurements(db *sql.DB, f BodyMeasurementFilter) ([]model.BodyMeas
 
 	items := make([]model.BodyMeasurement, 0)
 	for rows.Next() {
-		var m model.BodyMeasurement
-		var measuredAtRaw string
-		var bodyFat sql.NullFloat64
-		if err := rows.Scan(&m.ID, &measuredAtRaw, &m.WeightKg, &bodyFat, &m.Notes); err != nil {
-			return nil, fmt.Errorf("scan body measurement: %w", err)
-		}
-		measured, err := time.Parse(time.RFC3339, measuredAtRaw)
+		m, err := scanBodyMeasurement(rows.Scan)
 		if err != nil {
-			return nil, fmt.Errorf("parse measured_at: %w", err)
-		}
-		m.MeasuredAt = measured
-		if bodyFat.Valid {
-			v := bodyFat.Float64
-			m.BodyFatPct = &v
+			return nil, fmt.Errorf("scan body measurement: %w", err)
 		}
-		items = append(items, m)
+		items = append(items, *m)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("iterate body measurements: %w", err)
@@ -126,6 +127,66 @@ func ListBodyMeasurements(db *sql.DB, f BodyMeasurementFilter) ([]model.BodyMeas
 	return items, nil
 }
 
+// LatestBodyMeasurement returns the most recent measurement taken on or before
+// date (YYYY-MM-DD, default today), or nil when none exists.
+func LatestBodyMeasurement(db *sql.DB, date string) (*model.BodyMeasurement, error) {
+	date = strings.TrimSpace(date)
+	if date == "" {
+		date = time.Now().Format("2006-01-02")
+	}
+	end, err := parseDateEndExclusive(date)
+	if err != nil {
+		return nil, err
+	}
+	m, err := scanBodyMeasurement(db.QueryRow(`
+SELECT `+bodyMeasurementColumns+`
+FROM body_measurements
+WHERE measured_at < ?
+ORDER BY measured_at DESC
+LIMIT 1
+`, end).Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("latest body measurement for %s: %w", date, err)
+	}
+	return m, nil
+}
+
+// scanBodyMeasurement reads one row selected with bodyMeasurementColumns from
+// either *sql.Row or *sql.Rows. Scan errors are returned unwrapped so callers
+// can check for sql.ErrNoRows.
+func scanBodyMeasurement(scan func(dest ...any) error) (*model.BodyMeasurement, error) {
+	var m model.BodyMeasurement
+	var measuredAtRaw string
+	var bodyFat, waist, hip, neck, chest, arm, thigh sql.NullFloat64
+	if err := scan(&m.ID, &measuredAtRaw, &m.WeightKg, &bodyFat, &m.Notes, &waist, &hip, &neck, &chest, &arm, &thigh); err != nil {
+		return nil, err
+	}
+	measured, err := time.Parse(time.RFC3339, measuredAtRaw)
+	if err != nil {
+		return nil, fmt.Errorf("parse measured_at: %w", err)
+	}
+	m.MeasuredAt = measured
+	m.BodyFatPct = nullFloatPtr(bodyFat)
+	m.WaistCm = nullFloatPtr(waist)
+	m.HipCm = nullFloatPtr(hip)
+	m.NeckCm = nullFloatPtr(neck)
+	m.ChestCm = nullFloatPtr(chest)
+	m.ArmCm = nullFloatPtr(arm)
+	m.ThighCm = nullFloatPtr(thigh)
+	return &m, nil
+}
+
+func nullFloatPtr(v sql.NullFloat64) *float64 {
+	if !v.Valid {
+		return nil
+	}
+	f := v.Float64
+	return &f
+}
+
 func UpdateBodyMeasurement(db *sql.DB, in UpdateBodyMeasurementInput) error {
 	if in.ID <= 0 {
 		return fmt.Errorf("measurement id must be > 0")
@@ -139,14 +200,21 @@ func UpdateBodyMeasurement(db *sql.DB, in UpdateBodyMeasurementInput) error {
 			return fmt.Errorf("body-fat must be between 0 and 100")
 		}
 	}
+	c, err := in.Circumferences.toCm(in.LengthUnit)
+	if err != nil {
+		return err
+	}
 	if in.MeasuredAt.IsZero() {
 		return fmt.Errorf("measurement date/time is required")
 	}
 	res, err := db.Exec(`
 UPDATE body_measurements
-SET measured_at = ?, weight_kg = ?, body_fat_pct = ?, notes = ?, updated_at = CURRENT_TIMESTAMP
+SET measured_at = ?, weight_kg = ?, body_fat_pct = ?, notes = ?,
+    waist_cm = COALESCE(?, waist_cm), hip_cm = COALESCE(?, hip_cm), neck_cm = COALESCE(?, neck_cm),
+    chest_cm = COALESCE(?, chest_cm), arm_cm = COALESCE(?, arm_cm), thigh_cm = COALESCE(?, thigh_cm),
+    updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
-`, in.MeasuredAt.Format(time.RFC3339), weightKg, in.BodyFatPct, strings.TrimSpace(in.Notes), in.ID)
+`, in.MeasuredAt.Format(time.RFC3339), weightKg, in.BodyFatPct, strings.TrimSpace(in.Notes), c.Waist, c.Hip, c.Neck, c.Chest, c.Arm, c.Thigh, in.ID)
 	if err != nil {
 		return fmt.Errorf("update body measurement %d: %w", in.ID, err)
 	}
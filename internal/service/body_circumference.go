@@ -0,0 +1,124 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/saadjs/kcal-cli/internal/model"
+)
+
+const (
+	BodyFatSourceMeasured = "measured"
+	BodyFatSourceNavy     = "navy"
+)
+
+// CircumferenceSites lists the supported sites in display order.
+var CircumferenceSites = []string{"waist", "hip", "neck", "chest", "arm", "thigh"}
+
+// Circumferences holds optional tape measurements at each site.
+type Circumferences struct {
+	Waist *float64
+	Hip   *float64
+	Neck  *float64
+	Chest *float64
+	Arm   *float64
+	Thigh *float64
+}
+
+func (c Circumferences) sites() []*float64 {
+	return []*float64{c.Waist, c.Hip, c.Neck, c.Chest, c.Arm, c.Thigh}
+}
+
+// toCm validates the measurements and converts them from unit to centimeters.
+func (c Circumferences) toCm(unit string) (Circumferences, error) {
+	factor, err := lengthUnitToCm(unit)
+	if err != nil {
+		return Circumferences{}, err
+	}
+	values := c.sites()
+	out := make([]*float64, len(values))
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		if *v <= 0 {
+			return Circumferences{}, fmt.Errorf("%s circumference must be > 0", CircumferenceSites[i])
+		}
+		cm := *v * factor
+		out[i] = &cm
+	}
+	return Circumferences{Waist: out[0], Hip: out[1], Neck: out[2], Chest: out[3], Arm: out[4], Thigh: out[5]}, nil
+}
+
+// MeasurementCircumferences returns the stored circumferences of m in cm.
+func MeasurementCircumferences(m model.BodyMeasurement) Circumferences {
+	return Circumferences{Waist: m.WaistCm, Hip: m.HipCm, Neck: m.NeckCm, Chest: m.ChestCm, Arm: m.ArmCm, Thigh: m.ThighCm}
+}
+
+// CircumferenceFromCm converts a stored centimeter value to unit (cm or in).
+func CircumferenceFromCm(cm float64, unit string) (float64, error) {
+	factor, err := lengthUnitToCm(unit)
+	if err != nil {
+		return 0, err
+	}
+	return cm / factor, nil
+}
+
+func lengthUnitToCm(unit string) (float64, error) {
+	switch strings.ToLower(strings.TrimSpace(unit)) {
+	case "", "cm":
+		return 1, nil
+	case "in", "inch", "inches":
+		return cmPerInch, nil
+	default:
+		return 0, fmt.Errorf("invalid length unit %q (use cm or in)", unit)
+	}
+}
+
+// NavyBodyFatPct estimates body fat with the US Navy circumference method
+// (metric form). Women also need a hip measurement.
+func NavyBodyFatPct(sex string, heightCm, waistCm, neckCm float64, hipCm *float64) (float64, error) {
+	if heightCm <= 0 {
+		return 0, fmt.Errorf("navy body fat needs a height")
+	}
+	var bf float64
+	switch sex {
+	case SexMale:
+		if waistCm <= neckCm {
+			return 0, fmt.Errorf("navy body fat needs waist larger than neck")
+		}
+		bf = 495/(1.0324-0.19077*math.Log10(waistCm-neckCm)+0.15456*math.Log10(heightCm)) - 450
+	case SexFemale:
+		if hipCm == nil {
+			return 0, fmt.Errorf("navy body fat for women needs a hip measurement")
+		}
+		if waistCm+*hipCm <= neckCm {
+			return 0, fmt.Errorf("navy body fat needs waist plus hip larger than neck")
+		}
+		bf = 495/(1.29579-0.35004*math.Log10(waistCm+*hipCm-neckCm)+0.22100*math.Log10(heightCm)) - 450
+	default:
+		return 0, fmt.Errorf("navy body fat needs the profile sex")
+	}
+	if bf <= 0 || bf >= 100 {
+		return 0, fmt.Errorf("navy body fat estimate %.1f%% is out of range", bf)
+	}
+	return roundTo(bf, 2), nil
+}
+
+// ResolveBodyFat returns the measured body fat of m, or a Navy estimate from
+// its circumferences and the profile when no reading was taken. The source is
+// BodyFatSourceMeasured, BodyFatSourceNavy, or empty when neither is available.
+func ResolveBodyFat(profile *model.Profile, m model.BodyMeasurement) (*float64, string) {
+	if m.BodyFatPct != nil {
+		return m.BodyFatPct, BodyFatSourceMeasured
+	}
+	if profile == nil || profile.HeightCm == nil || m.WaistCm == nil || m.NeckCm == nil {
+		return nil, ""
+	}
+	bf, err := NavyBodyFatPct(profile.Sex, *profile.HeightCm, *m.WaistCm, *m.NeckCm, m.HipCm)
+	if err != nil {
+		return nil, ""
+	}
+	return &bf, BodyFatSourceNavy
+}
@@ -0,0 +1,131 @@
+package service_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/service"
+)
+
+func TestNavyBodyFatPct(t *testing.T) {
+	t.Parallel()
+	male, err := service.NavyBodyFatPct(service.SexMale, 180, 90, 38, nil)
+	if err != nil {
+		t.Fatalf("male navy body fat: %v", err)
+	}
+	if math.Abs(male-19.81) > 0.01 {
+		t.Fatalf("expected 19.81%% for male, got %.2f", male)
+	}
+	hip := 100.0
+	female, err := service.NavyBodyFatPct(service.SexFemale, 165, 75, 33, &hip)
+	if err != nil {
+		t.Fatalf("female navy body fat: %v", err)
+	}
+	if math.Abs(female-29.43) > 0.01 {
+		t.Fatalf("expected 29.43%% for female, got %.2f", female)
+	}
+	if _, err := service.NavyBodyFatPct(service.SexFemale, 165, 75, 33, nil); err == nil {
+		t.Fatalf("expected error without hip measurement for women")
+	}
+}
+
+func TestBodyCircumferencesAndNavyEstimateInSummary(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	if _, err := service.SetProfile(db, service.ProfileInput{Sex: "male", BirthDate: "1990-01-01", Height: 180, HeightUnit: "cm"}); err != nil {
+		t.Fatalf("set profile: %v", err)
+	}
+	day := time.Date(2026, 2, 2, 7, 0, 0, 0, time.Local)
+	waistIn, neckIn := 90/2.54, 38/2.54
+	if _, err := service.AddBodyMeasurement(db, service.BodyMeasurementInput{
+		Weight: 85, Unit: "kg", MeasuredAt: day, LengthUnit: "in",
+		Circumferences: service.Circumferences{Waist: &waistIn, Neck: &neckIn},
+	}); err != nil {
+		t.Fatalf("add first measurement: %v", err)
+	}
+	waist, neck, arm := 86.0, 38.0, 36.0
+	if _, err := service.AddBodyMeasurement(db, service.BodyMeasurementInput{
+		Weight: 83, Unit: "kg", MeasuredAt: day.AddDate(0, 0, 14),
+		Circumferences: service.Circumferences{Waist: &waist, Neck: &neck, Arm: &arm},
+	}); err != nil {
+		t.Fatalf("add second measurement: %v", err)
+	}
+	bf := 15.0
+	if _, err := service.AddBodyMeasurement(db, service.BodyMeasurementInput{Weight: 83, Unit: "kg", MeasuredAt: day.AddDate(0, 0, 15), BodyFatPct: &bf}); err != nil {
+		t.Fatalf("add measured body fat: %v", err)
+	}
+	zero := 0.0
+	if _, err := service.AddBodyMeasurement(db, service.BodyMeasurementInput{Weight: 83, Unit: "kg", Circumferences: service.Circumferences{Hip: &zero}}); err == nil {
+		t.Fatalf("expected non-positive circumference to be rejected")
+	}
+
+	latest, err := service.LatestBodyMeasurement(db, "2026-02-16")
+	if err != nil {
+		t.Fatalf("latest measurement: %v", err)
+	}
+	if latest == nil || latest.WaistCm == nil || *latest.WaistCm != 86 || latest.ArmCm == nil || latest.HipCm != nil {
+		t.Fatalf("expected stored circumferences on latest measurement, got %+v", latest)
+	}
+
+	report, err := service.AnalyticsRange(db, day, day.AddDate(0, 0, 15), 0.10)
+	if err != nil {
+		t.Fatalf("analytics: %v", err)
+	}
+	points := report.Body.Points
+	if len(points) != 3 {
+		t.Fatalf("expected 3 body points, got %d", len(points))
+	}
+	if points[0].BodyFatSource != service.BodyFatSourceNavy || points[0].BodyFatPct == nil || math.Abs(*points[0].BodyFatPct-19.81) > 0.01 {
+		t.Fatalf("expected navy estimate on first point, got %+v", points[0])
+	}
+	if points[0].WaistCm == nil || math.Abs(*points[0].WaistCm-90) > 1e-9 {
+		t.Fatalf("expected waist converted from inches, got %+v", points[0].WaistCm)
+	}
+	if points[2].BodyFatSource != service.BodyFatSourceMeasured || *points[2].BodyFatPct != 15 {
+		t.Fatalf("expected measured body fat to win, got %+v", points[2])
+	}
+	trends := report.Body.Circumferences
+	if len(trends) != 3 || trends[0].Site != "waist" || trends[0].Measurements != 2 || math.Abs(trends[0].ChangeCm+4) > 1e-9 {
+		t.Fatalf("expected waist trend of -4cm first, got %+v", trends)
+	}
+	if trends[2].Site != "arm" || trends[2].Measurements != 1 || trends[2].ChangeCm != 0 {
+		t.Fatalf("expected single arm measurement, got %+v", trends[2])
+	}
+}
+
+func TestUpdateBodyMeasurementKeepsUnsetCircumferences(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	day := time.Date(2026, 2, 2, 7, 0, 0, 0, time.Local)
+	waist, hip := 86.0, 100.0
+	id, err := service.AddBodyMeasurement(db, service.BodyMeasurementInput{
+		Weight: 83, Unit: "kg", MeasuredAt: day,
+		Circumferences: service.Circumferences{Waist: &waist, Hip: &hip},
+	})
+	if err != nil {
+		t.Fatalf("add measurement: %v", err)
+	}
+	waist = 85
+	if err := service.UpdateBodyMeasurement(db, service.UpdateBodyMeasurementInput{
+		ID: id,
+		BodyMeasurementInput: service.BodyMeasurementInput{
+			Weight: 82, Unit: "kg", MeasuredAt: day,
+			Circumferences: service.Circumferences{Waist: &waist},
+		},
+	}); err != nil {
+		t.Fatalf("update measurement: %v", err)
+	}
+	items, err := service.ListBodyMeasurements(db, service.BodyMeasurementFilter{})
+	if err != nil {
+		t.Fatalf("list measurements: %v", err)
+	}
+	m := items[0]
+	if m.WeightKg != 82 || m.WaistCm == nil || *m.WaistCm != 85 || m.HipCm == nil || *m.HipCm != 100 {
+		t.Fatalf("expected waist updated and hip kept, got %+v", m)
+	}
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	DefaultMaxLossPctPerWeek = 1.0
+	DefaultMaxGainPctPerWeek = 0.5
+
+	ForecastDirectionLose    = "lose"
+	ForecastDirectionGain    = "gain"
+	ForecastDirectionReached = "reached"
+
+	// forecastReachedKg is how close the trend must be to count as reached.
+	forecastReachedKg = 0.1
+)
+
+type BodyGoalForecastOptions struct {
+	// Date is the as-of day; zero means today.
+	Date              time.Time
+	WindowDays        int
+	MaxLossPctPerWeek float64
+	MaxGainPctPerWeek float64
+}
+
+// BodyGoalForecast projects when the weight trend reaches the active body
+// goal. Rates are kg per week; negative means losing.
+type BodyGoalForecast struct {
+	AsOf              string   `json:"as_of"`
+	GoalEffectiveDate string   `json:"goal_effective_date"`
+	TargetWeightKg    float64  `json:"target_weight_kg"`
+	TargetDate        string   `json:"target_date,omitempty"`
+	TrendWeightKg     float64  `json:"trend_weight_kg"`
+	RemainingKg       float64  `json:"remaining_kg"`
+	Direction         string   `json:"direction"`
+	WeighIns          int      `json:"weigh_ins"`
+	TrendKgPerWeek    *float64 `json:"trend_kg_per_week,omitempty"`
+	TrendKgPerWeekLow *float64 `json:"trend_kg_per_week_low,omitempty"`
+	TrendKgPerWeekHi  *float64 `json:"trend_kg_per_week_high,omitempty"`
+	ETA               string   `json:"eta,omitempty"`
+	ETAEarliest       string   `json:"eta_earliest,omitempty"`
+	ETALatest         string   `json:"eta_latest,omitempty"`
+	OnTrack           *bool    `json:"on_track,omitempty"`
+
+	RequiredKgPerWeek    *float64 `json:"required_kg_per_week,omitempty"`
+	RequiredDailyBalance *int     `json:"required_daily_balance_kcal,omitempty"`
+	SafeKgPerWeek        float64  `json:"safe_kg_per_week"`
+	Unrealistic          bool     `json:"unrealistic"`
+	Notes                []string `json:"notes,omitempty"`
+}
+
+// ForecastBodyGoal projects the body goal in effect at opts.Date from the
+// least-squares weight trend over the trailing window. It returns nil when no
+// body goal is set.
+func ForecastBodyGoal(db *sql.DB, opts BodyGoalForecastOptions) (*BodyGoalForecast, error) {
+	if opts.WindowDays == 0 {
+		opts.WindowDays = DefaultTDEEWindowDays
+	}
+	if opts.MaxLossPctPerWeek == 0 {
+		opts.MaxLossPctPerWeek = DefaultMaxLossPctPerWeek
+	}
+	if opts.MaxGainPctPerWeek == 0 {
+		opts.MaxGainPctPerWeek = DefaultMaxGainPctPerWeek
+	}
+	if opts.WindowDays < 7 {
+		return nil, fmt.Errorf("window must be at least 7 days")
+	}
+	if opts.MaxLossPctPerWeek < 0 || opts.MaxGainPctPerWeek < 0 {
+		return nil, fmt.Errorf("safe rates must be >= 0")
+	}
+	asOf := opts.Date
+	if asOf.IsZero() {
+		asOf = time.Now()
+	}
+	asOf = beginningOfDay(asOf)
+	date := asOf.Format("2006-01-02")
+
+	goal, err := CurrentBodyGoal(db, date)
+	if err != nil || goal == nil {
+		return nil, err
+	}
+	latest, err := LatestBodyMeasurement(db, date)
+	if err != nil {
+		return nil, err
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("no body measurements on or before %s", date)
+	}
+
+	out := &BodyGoalForecast{
+		AsOf:              date,
+		GoalEffectiveDate: goal.EffectiveDate,
+		TargetWeightKg:    goal.TargetWeightKg,
+		TargetDate:        goal.TargetDate,
+		TrendWeightKg:     latest.WeightKg,
+	}
+
+	from := asOf.AddDate(0, 0, -(opts.WindowDays - 1))
+	days, weights, err := loadDailyWeights(db, from, asOf)
+	if err != nil {
+		return nil, err
+	}
+	out.WeighIns = len(weights)
+	var slope, slopeSE float64
+	hasTrend := len(weights) >= 2
+	if hasTrend {
+		var intercept float64
+		slope, intercept, slopeSE = weightTrend(days, weights)
+		out.TrendWeightKg = intercept + slope*float64(opts.WindowDays-1)
+		perWeek := roundTo(slope*7, 3)
+		low := roundTo((slope-1.96*slopeSE)*7, 3)
+		high := roundTo((slope+1.96*slopeSE)*7, 3)
+		out.TrendKgPerWeek, out.TrendKgPerWeekLow, out.TrendKgPerWeekHi = &perWeek, &low, &high
+	} else {
+		out.Notes = append(out.Notes, fmt.Sprintf("need at least 2 weigh-ins in the last %d days for a trend; using the latest measurement", opts.WindowDays))
+	}
+	out.TrendWeightKg = roundTo(out.TrendWeightKg, 2)
+	out.RemainingKg = roundTo(goal.TargetWeightKg-out.TrendWeightKg, 2)
+
+	switch {
+	case math.Abs(out.RemainingKg) < forecastReachedKg:
+		out.Direction = ForecastDirectionReached
+	case out.RemainingKg < 0:
+		out.Direction = ForecastDirectionLose
+		out.SafeKgPerWeek = -roundTo(out.TrendWeightKg*opts.MaxLossPctPerWeek/100, 2)
+	default:
+		out.Direction = ForecastDirectionGain
+		out.SafeKgPerWeek = roundTo(out.TrendWeightKg*opts.MaxGainPctPerWeek/100, 2)
+	}
+	if out.Direction == ForecastDirectionReached {
+		return out, nil
+	}
+
+	if hasTrend {
+		if eta, ok := forecastETA(asOf, out.RemainingKg, slope); ok {
+			out.ETA = eta
+		} else {
+			out.Notes = append(out.Notes, "weight trend is flat or moving away from the target")
+		}
+		// A faster rate reaches the target sooner, so the rate bounds map to
+		// the earliest and latest dates.
+		fast, slow := slope-1.96*slopeSE, slope+1.96*slopeSE
+		if out.Direction == ForecastDirectionGain {
+			fast, slow = slow, fast
+		}
+		out.ETAEarliest, _ = forecastETA(asOf, out.RemainingKg, fast)
+		out.ETALatest, _ = forecastETA(asOf, out.RemainingKg, slow)
+	}
+
+	if goal.TargetDate != "" {
+		target, err := time.ParseInLocation("2006-01-02", goal.TargetDate, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid target date %q: %w", goal.TargetDate, err)
+		}
+		daysLeft := math.Round(target.Sub(asOf).Hours() / 24)
+		if daysLeft <= 0 {
+			out.Notes = append(out.Notes, fmt.Sprintf("target date %s has passed", goal.TargetDate))
+			out.Unrealistic = true
+		} else {
+			required := roundTo(out.RemainingKg/daysLeft*7, 3)
+			balance := int(math.Round(out.RemainingKg / daysLeft * KcalPerKgBodyWeight))
+			out.RequiredKgPerWeek = &required
+			out.RequiredDailyBalance = &balance
+			if math.Abs(required) > math.Abs(out.SafeKgPerWeek) {
+				out.Unrealistic = true
+				out.Notes = append(out.Notes, fmt.Sprintf("needs %.2f kg/week, beyond the safe rate of %.2f kg/week", required, out.SafeKgPerWeek))
+			}
+		}
+		if out.ETA != "" {
+			onTrack := out.ETA <= goal.TargetDate
+			out.OnTrack = &onTrack
+		}
+	}
+	return out, nil
+}
+
+// forecastETA returns the date the remaining change is covered at slope kg per
+// day, or false when the slope does not move toward the target.
+func forecastETA(asOf time.Time, remainingKg, slope float64) (string, bool) {
+	if slope == 0 || (remainingKg < 0) != (slope < 0) {
+		return "", false
+	}
+	days := math.Ceil(remainingKg / slope)
+	if days > 3650 {
+		return "", false
+	}
+	return asOf.AddDate(0, 0, int(days)).Format("2006-01-02"), true
+}
+
+func roundTo(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
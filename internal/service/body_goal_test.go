@@ -2,6 +2,7 @@ package service_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/saadjs/kcal-cli/internal/service"
 )
@@ -34,3 +35,56 @@ func TestBodyGoalVersioning(t *testing.T) {
 		t.Fatalf("expected feb target weight 75kg, got %+v", feb)
 	}
 }
+
+func TestForecastBodyGoalFromTrend(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	start := time.Date(2026, 3, 1, 7, 0, 0, 0, time.Local)
+	for i := 0; i < 28; i++ {
+		if _, err := service.AddBodyMeasurement(db, service.BodyMeasurementInput{Weight: 80 - 0.125*float64(i), Unit: "kg", MeasuredAt: start.AddDate(0, 0, i)}); err != nil {
+			t.Fatalf("add weight day %d: %v", i, err)
+		}
+	}
+	asOf := time.Date(2026, 3, 28, 0, 0, 0, 0, time.Local)
+	if f, err := service.ForecastBodyGoal(db, service.BodyGoalForecastOptions{Date: asOf}); err != nil || f != nil {
+		t.Fatalf("expected no forecast without a body goal, got %+v (%v)", f, err)
+	}
+	if err := service.SetBodyGoal(db, service.SetBodyGoalInput{TargetWeight: 75, Unit: "kg", TargetDate: "2026-04-30", EffectiveDate: "2026-03-01"}); err != nil {
+		t.Fatalf("set body goal: %v", err)
+	}
+
+	f, err := service.ForecastBodyGoal(db, service.BodyGoalForecastOptions{Date: asOf})
+	if err != nil {
+		t.Fatalf("forecast: %v", err)
+	}
+	if f.Direction != service.ForecastDirectionLose || f.TrendWeightKg != 76.63 || f.ETA != "2026-04-11" {
+		t.Fatalf("unexpected forecast %+v", f)
+	}
+	if f.OnTrack == nil || !*f.OnTrack || f.Unrealistic {
+		t.Fatalf("expected forecast to be on track, got %+v", f)
+	}
+	if f.RequiredDailyBalance == nil || *f.RequiredDailyBalance != -380 {
+		t.Fatalf("expected -380 kcal/day to hit target date, got %+v", f.RequiredDailyBalance)
+	}
+
+	if err := service.SetBodyGoal(db, service.SetBodyGoalInput{TargetWeight: 70, Unit: "kg", TargetDate: "2026-04-15", EffectiveDate: "2026-03-20"}); err != nil {
+		t.Fatalf("set aggressive body goal: %v", err)
+	}
+	f, err = service.ForecastBodyGoal(db, service.BodyGoalForecastOptions{Date: asOf})
+	if err != nil {
+		t.Fatalf("forecast aggressive goal: %v", err)
+	}
+	if !f.Unrealistic || f.OnTrack == nil || *f.OnTrack {
+		t.Fatalf("expected aggressive goal to be flagged unrealistic and behind, got %+v", f)
+	}
+
+	report, err := service.AnalyticsRange(db, start, asOf, 0.10)
+	if err != nil {
+		t.Fatalf("analytics: %v", err)
+	}
+	if report.Body.Forecast == nil || report.Body.Forecast.TargetWeightKg != 70 {
+		t.Fatalf("expected forecast in analytics body summary, got %+v", report.Body.Forecast)
+	}
+}
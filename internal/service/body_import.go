@@ -0,0 +1,472 @@
+package service
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const kgPerStone = 6.35029318
+
+// BodyImportFormats lists the supported smart-scale CSV layouts.
+var BodyImportFormats = []string{"withings", "fitbit", "renpho", "generic"}
+
+// BodyImportFields are the column roles that --map can assign.
+var BodyImportFields = []string{"date", "time", "weight", "body_fat", "fat_mass", "unit", "notes"}
+
+// bodyImportLayouts holds the header names each format uses for a field,
+// matched case-insensitively with any "(unit)" suffix removed. The date column
+// may hold a full timestamp; a separate time column is joined to it.
+var bodyImportLayouts = map[string]map[string][]string{
+	"withings": {
+		"date":     {"date"},
+		"weight":   {"weight"},
+		"fat_mass": {"fat mass"},
+		"notes":    {"comments"},
+	},
+	"fitbit": {
+		"date":     {"date"},
+		"time":     {"time"},
+		"weight":   {"weight"},
+		"body_fat": {"fat", "body fat"},
+	},
+	"renpho": {
+		"date":     {"time of measurement", "date"},
+		"time":     {"time"},
+		"weight":   {"weight"},
+		"body_fat": {"body fat", "bodyfat"},
+	},
+	"generic": {
+		"date":     {"timestamp", "datetime", "measured_at", "date"},
+		"time":     {"time"},
+		"weight":   {"weight", "weight_kg", "weight_lb", "mass"},
+		"body_fat": {"body_fat", "body fat", "body_fat_pct", "bodyfat", "fat"},
+		"fat_mass": {"fat mass", "fat_mass"},
+		"unit":     {"unit", "units"},
+		"notes":    {"notes", "note", "comments", "comment"},
+	},
+}
+
+var (
+	headerUnitPattern = regexp.MustCompile(`\s*[\(\[]\s*([^\)\]]*)\s*[\)\]]\s*$`)
+	stoneValuePattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*st(?:one)?s?\s*(?:(\d+(?:\.\d+)?)\s*(?:lb|lbs)?)?$`)
+	unitValuePattern  = regexp.MustCompile(`^(-?\d+(?:\.\d+)?)\s*(kg|kgs|lb|lbs)?$`)
+)
+
+var bodyImportTimestampLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006/01/02 15:04:05",
+	"2006/01/02 15:04",
+	"01/02/2006 15:04:05",
+	"01/02/2006 15:04",
+	"01/02/2006 3:04 PM",
+	"01-02-2006 15:04:05",
+	"01-02-2006 15:04",
+	"Jan 2, 2006 3:04:05 PM",
+	"Jan 2, 2006 3:04 PM",
+	"2006-01-02",
+	"2006/01/02",
+	"01/02/2006",
+	"01-02-2006",
+	"Jan 2, 2006",
+}
+
+type BodyImportOptions struct {
+	Format string
+	// Unit overrides unit detection (kg, lb, or st).
+	Unit string
+	// Timezone is an IANA zone for timestamps without an offset; empty means
+	// local time.
+	Timezone string
+	// Columns maps a field in BodyImportFields to a header name, overriding the
+	// format's layout.
+	Columns map[string]string
+	DryRun  bool
+}
+
+type BodyImportReport struct {
+	Format     string   `json:"format"`
+	DryRun     bool     `json:"dry_run"`
+	Rows       int      `json:"rows"`
+	Inserted   int      `json:"inserted"`
+	Duplicates int      `json:"duplicates"`
+	Skipped    int      `json:"skipped"`
+	Unit       string   `json:"unit"`
+	UnitSource string   `json:"unit_source"`
+	Timezone   string   `json:"timezone"`
+	Columns    []string `json:"columns"`
+	FirstDate  string   `json:"first_date,omitempty"`
+	LastDate   string   `json:"last_date,omitempty"`
+	Warnings   []string `json:"warnings,omitempty"`
+}
+
+type bodyImportRow struct {
+	line       int
+	measuredAt time.Time
+	weightKg   float64
+	bodyFatPct *float64
+	notes      string
+}
+
+// ImportBodyCSV reads a smart-scale CSV export and adds measurements whose
+// timestamp is not already stored. With DryRun the report is computed but
+// nothing is written.
+func ImportBodyCSV(db *sql.DB, r io.Reader, opts BodyImportOptions) (*BodyImportReport, error) {
+	format := strings.ToLower(strings.TrimSpace(opts.Format))
+	if format == "" {
+		format = "generic"
+	}
+	layout, ok := bodyImportLayouts[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported body import format %q (use %s)", opts.Format, strings.Join(BodyImportFormats, "|"))
+	}
+	loc := time.Local
+	if tz := strings.TrimSpace(opts.Timezone); tz != "" {
+		var err error
+		loc, err = time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", tz, err)
+		}
+	}
+	report := &BodyImportReport{Format: format, DryRun: opts.DryRun, Timezone: loc.String()}
+
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read body import csv: %w", err)
+	}
+	raw = bytes.TrimPrefix(raw, []byte("\xef\xbb\xbf"))
+	reader := csv.NewReader(bytes.NewReader(raw))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if firstLine, _, _ := bytes.Cut(raw, []byte("\n")); bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parse body import csv: %w", err)
+	}
+	if len(records) <= 1 {
+		return nil, fmt.Errorf("body import csv contains no data rows")
+	}
+
+	cols, headerUnit, err := resolveBodyImportColumns(records[0], layout, opts.Columns)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range BodyImportFields {
+		if i, ok := cols[field]; ok {
+			report.Columns = append(report.Columns, fmt.Sprintf("%s=%s", field, strings.TrimSpace(records[0][i])))
+		}
+	}
+	report.Unit, report.UnitSource = headerUnit, "header"
+	if u := strings.TrimSpace(opts.Unit); u != "" {
+		report.Unit, report.UnitSource = u, "flag"
+	} else if headerUnit == "" {
+		report.Unit, report.UnitSource = "kg", "default"
+		if _, ok := cols["unit"]; ok {
+			report.UnitSource = "unit column"
+		} else {
+			report.Warnings = append(report.Warnings, "weight unit not found in header; assuming kg (use --unit to override)")
+		}
+	}
+	if _, err := bodyImportUnitFactor(report.Unit); err != nil {
+		return nil, err
+	}
+
+	rows := make([]bodyImportRow, 0, len(records)-1)
+	for i, rec := range records[1:] {
+		line := i + 2
+		if bodyImportBlankRecord(rec) {
+			continue
+		}
+		report.Rows++
+		row, err := parseBodyImportRow(rec, cols, report.Unit, loc)
+		if err != nil {
+			report.Skipped++
+			report.Warnings = append(report.Warnings, fmt.Sprintf("row %d: %v", line, err))
+			continue
+		}
+		row.line = line
+		rows = append(rows, row)
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].measuredAt.Before(rows[j].measuredAt) })
+
+	existing, err := existingBodyTimestamps(db)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin body import tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	for _, row := range rows {
+		key := row.measuredAt.Unix()
+		if existing[key] {
+			report.Duplicates++
+			continue
+		}
+		existing[key] = true
+		if report.FirstDate == "" {
+			report.FirstDate = row.measuredAt.In(time.Local).Format("2006-01-02")
+		}
+		report.LastDate = row.measuredAt.In(time.Local).Format("2006-01-02")
+		report.Inserted++
+		if opts.DryRun {
+			continue
+		}
+		if _, err := tx.Exec(`INSERT INTO body_measurements(measured_at, weight_kg, body_fat_pct, notes) VALUES(?, ?, ?, ?)`, row.measuredAt.In(time.Local).Format(time.RFC3339), row.weightKg, row.bodyFatPct, row.notes); err != nil {
+			return nil, fmt.Errorf("import body row %d: %w", row.line, err)
+		}
+	}
+	if opts.DryRun {
+		return report, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit body import: %w", err)
+	}
+	return report, nil
+}
+
+// resolveBodyImportColumns finds the column index of each field and the weight
+// unit named in the weight header, if any.
+func resolveBodyImportColumns(header []string, layout map[string][]string, overrides map[string]string) (map[string]int, string, error) {
+	bases := make([]string, len(header))
+	units := make([]string, len(header))
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if m := headerUnitPattern.FindStringSubmatch(h); m != nil {
+			units[i] = strings.TrimSpace(m[1])
+			h = strings.TrimSpace(h[:len(h)-len(m[0])])
+		}
+		bases[i] = h
+	}
+	find := func(name string) int {
+		name = strings.ToLower(strings.TrimSpace(name))
+		for i := range header {
+			if bases[i] == name || strings.ToLower(strings.TrimSpace(header[i])) == name {
+				return i
+			}
+		}
+		return -1
+	}
+
+	cols := map[string]int{}
+	for field, names := range layout {
+		for _, name := range names {
+			if i := find(name); i >= 0 {
+				cols[field] = i
+				break
+			}
+		}
+	}
+	for field, name := range overrides {
+		if !slices.Contains(BodyImportFields, field) {
+			return nil, "", fmt.Errorf("unknown column field %q (use %s)", field, strings.Join(BodyImportFields, ", "))
+		}
+		i := find(name)
+		if i < 0 {
+			return nil, "", fmt.Errorf("column %q for %s not found in header", name, field)
+		}
+		cols[field] = i
+	}
+	// A timestamp column named "time" is the date column when no date exists.
+	if _, ok := cols["date"]; !ok {
+		if i, ok := cols["time"]; ok {
+			cols["date"] = i
+			delete(cols, "time")
+		}
+	}
+	if i, ok := cols["time"]; ok && i == cols["date"] {
+		delete(cols, "time")
+	}
+	if _, ok := cols["date"]; !ok {
+		return nil, "", fmt.Errorf("no date column found (use --map date=<header>)")
+	}
+	i, ok := cols["weight"]
+	if !ok {
+		return nil, "", fmt.Errorf("no weight column found (use --map weight=<header>)")
+	}
+	unit := units[i]
+	if unit == "" {
+		switch {
+		case strings.HasSuffix(bases[i], "_kg"):
+			unit = "kg"
+		case strings.HasSuffix(bases[i], "_lb"):
+			unit = "lb"
+		}
+	}
+	if unit != "" {
+		if _, err := bodyImportUnitFactor(unit); err != nil {
+			unit = ""
+		}
+	}
+	return cols, unit, nil
+}
+
+func parseBodyImportRow(rec []string, cols map[string]int, unit string, loc *time.Location) (bodyImportRow, error) {
+	get := func(field string) string {
+		i, ok := cols[field]
+		if !ok || i >= len(rec) {
+			return ""
+		}
+		return strings.TrimSpace(rec[i])
+	}
+	var row bodyImportRow
+	stamp := get("date")
+	if t := get("time"); t != "" {
+		stamp += " " + t
+	}
+	measuredAt, err := parseBodyImportTime(stamp, loc)
+	if err != nil {
+		return row, err
+	}
+	row.measuredAt = measuredAt
+
+	if u := get("unit"); u != "" {
+		unit = u
+	}
+	row.weightKg, err = parseBodyImportWeight(get("weight"), unit)
+	if err != nil {
+		return row, err
+	}
+	if bf := bodyImportNumber(get("body_fat")); bf != "" {
+		v, err := strconv.ParseFloat(strings.TrimSuffix(bf, "%"), 64)
+		if err != nil || v <= 0 || v >= 100 {
+			return row, fmt.Errorf("invalid body fat %q", get("body_fat"))
+		}
+		row.bodyFatPct = &v
+	} else if fm := get("fat_mass"); bodyImportNumber(fm) != "" {
+		fatKg, err := parseBodyImportWeight(fm, unit)
+		if err != nil || fatKg >= row.weightKg {
+			return row, fmt.Errorf("invalid fat mass %q", fm)
+		}
+		v := roundTo(fatKg/row.weightKg*100, 2)
+		row.bodyFatPct = &v
+	}
+	row.notes = get("notes")
+	return row, nil
+}
+
+func parseBodyImportTime(value string, loc *time.Location) (time.Time, error) {
+	value = strings.Join(strings.Fields(value), " ")
+	if value == "" {
+		return time.Time{}, fmt.Errorf("missing date")
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if unix, err := strconv.ParseInt(value, 10, 64); err == nil && unix > 100000000 {
+		return time.Unix(unix, 0), nil
+	}
+	for _, layout := range bodyImportTimestampLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date %q", value)
+}
+
+// parseBodyImportWeight converts a weight cell to kg. Cells may carry their own
+// unit ("80.4 kg", "177 lb", "12st 6lb"); otherwise unit applies.
+func parseBodyImportWeight(value, unit string) (float64, error) {
+	v := strings.ToLower(bodyImportNumber(value))
+	if v == "" {
+		return 0, fmt.Errorf("missing weight")
+	}
+	if m := stoneValuePattern.FindStringSubmatch(v); m != nil {
+		stones, _ := strconv.ParseFloat(m[1], 64)
+		pounds := 0.0
+		if m[2] != "" {
+			pounds, _ = strconv.ParseFloat(m[2], 64)
+		}
+		return convertWeightToKg(stones*14+pounds, "lb")
+	}
+	m := unitValuePattern.FindStringSubmatch(v)
+	if m == nil {
+		return 0, fmt.Errorf("invalid weight %q", value)
+	}
+	amount, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid weight %q", value)
+	}
+	if m[2] != "" {
+		unit = m[2]
+	}
+	factor, err := bodyImportUnitFactor(unit)
+	if err != nil {
+		return 0, err
+	}
+	if amount <= 0 {
+		return 0, fmt.Errorf("weight must be > 0")
+	}
+	return amount * factor, nil
+}
+
+func bodyImportUnitFactor(unit string) (float64, error) {
+	switch strings.ToLower(strings.TrimSpace(unit)) {
+	case "kg", "kgs":
+		return 1, nil
+	case "lb", "lbs":
+		return kgPerLb, nil
+	case "st", "stone", "stones":
+		return kgPerStone, nil
+	default:
+		return 0, fmt.Errorf("invalid weight unit %q (use kg, lb, or st)", unit)
+	}
+}
+
+// bodyImportNumber trims a cell and turns a lone decimal comma into a point;
+// placeholder cells such as "-" become empty.
+func bodyImportNumber(value string) string {
+	v := strings.TrimSpace(value)
+	if v == "-" || v == "--" || strings.EqualFold(v, "n/a") {
+		return ""
+	}
+	if strings.Count(v, ",") == 1 && !strings.Contains(v, ".") {
+		v = strings.Replace(v, ",", ".", 1)
+	}
+	return v
+}
+
+func bodyImportBlankRecord(rec []string) bool {
+	for _, v := range rec {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func existingBodyTimestamps(db *sql.DB) (map[int64]bool, error) {
+	rows, err := db.Query(`SELECT measured_at FROM body_measurements`)
+	if err != nil {
+		return nil, fmt.Errorf("load body measurement timestamps: %w", err)
+	}
+	defer rows.Close()
+	out := map[int64]bool{}
+	for rows.Next() {
+		var raw string
+		if err := rows.Scan(&raw); err != nil {
+			return nil, fmt.Errorf("scan body measurement timestamp: %w", err)
+		}
+		if t, err := time.Parse(time.RFC3339, raw); err == nil {
+			out[t.Unix()] = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate body measurement timestamps: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,106 @@
+package service_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/service"
+)
+
+func TestImportBodyCSVWithingsDedupeAndDryRun(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	csv := `Date,"Weight (lb)","Fat mass (lb)",Comments
+2026-03-01 07:00:00,200,40,
+2026-03-02 07:00:00,199,,post run
+2026-03-02 07:00:00,199,,
+2026-03-03 07:00:00,,,
+`
+	opts := service.BodyImportOptions{Format: "withings", Timezone: "UTC", DryRun: true}
+	report, err := service.ImportBodyCSV(db, strings.NewReader(csv), opts)
+	if err != nil {
+		t.Fatalf("dry-run import: %v", err)
+	}
+	if report.Rows != 4 || report.Inserted != 2 || report.Duplicates != 1 || report.Skipped != 1 || report.Unit != "lb" || report.UnitSource != "header" {
+		t.Fatalf("unexpected dry-run report %+v", report)
+	}
+	items, err := service.ListBodyMeasurements(db, service.BodyMeasurementFilter{})
+	if err != nil {
+		t.Fatalf("list after dry run: %v", err)
+	}
+	if len(items) != 0 {
+		t.Fatalf("expected dry run to write nothing, got %d measurements", len(items))
+	}
+
+	// An existing measurement at the same instant is a duplicate.
+	if _, err := service.AddBodyMeasurement(db, service.BodyMeasurementInput{Weight: 90, Unit: "kg", MeasuredAt: time.Date(2026, 3, 2, 7, 0, 0, 0, time.UTC)}); err != nil {
+		t.Fatalf("add existing measurement: %v", err)
+	}
+	opts.DryRun = false
+	report, err = service.ImportBodyCSV(db, strings.NewReader(csv), opts)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if report.Inserted != 1 || report.Duplicates != 2 {
+		t.Fatalf("expected 1 inserted and 2 duplicates, got %+v", report)
+	}
+	latest, err := service.LatestBodyMeasurement(db, "2026-03-01")
+	if err != nil {
+		t.Fatalf("latest measurement: %v", err)
+	}
+	if latest == nil || !latest.MeasuredAt.Equal(time.Date(2026, 3, 1, 7, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected imported measurement at 07:00 UTC, got %+v", latest)
+	}
+	if math.Abs(latest.WeightKg-90.718474) > 1e-6 || latest.BodyFatPct == nil || *latest.BodyFatPct != 20 {
+		t.Fatalf("expected 200 lb with 20%% fat from fat mass, got %.6f kg %v", latest.WeightKg, latest.BodyFatPct)
+	}
+}
+
+func TestImportBodyCSVUnitsAndMapping(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	renpho := "Date;Time;Weight(st);Body Fat(%)\n2026/03/01;07:30:00;12st 7lb;21,5\n2026/03/02;07:30:00;80 kg;--\n"
+	report, err := service.ImportBodyCSV(db, strings.NewReader(renpho), service.BodyImportOptions{Format: "renpho"})
+	if err != nil {
+		t.Fatalf("renpho import: %v", err)
+	}
+	if report.Inserted != 2 || report.Unit != "st" {
+		t.Fatalf("unexpected renpho report %+v", report)
+	}
+	items, err := service.ListBodyMeasurements(db, service.BodyMeasurementFilter{})
+	if err != nil {
+		t.Fatalf("list measurements: %v", err)
+	}
+	if len(items) != 2 || items[0].WeightKg != 80 || math.Abs(items[1].WeightKg-79.3786648) > 1e-6 {
+		t.Fatalf("expected 80 kg and 12st 7lb, got %+v", items)
+	}
+	if items[1].BodyFatPct == nil || *items[1].BodyFatPct != 21.5 || items[0].BodyFatPct != nil {
+		t.Fatalf("expected decimal-comma body fat on first row only, got %+v", items)
+	}
+
+	generic := "When,Scale reading\n2026-03-05 06:45,176.4\n"
+	if _, err := service.ImportBodyCSV(db, strings.NewReader(generic), service.BodyImportOptions{Format: "generic"}); err == nil || !strings.Contains(err.Error(), "--map") {
+		t.Fatalf("expected missing column error, got %v", err)
+	}
+	report, err = service.ImportBodyCSV(db, strings.NewReader(generic), service.BodyImportOptions{
+		Format:  "generic",
+		Unit:    "lb",
+		Columns: map[string]string{"date": "When", "weight": "scale reading"},
+		DryRun:  true,
+	})
+	if err != nil {
+		t.Fatalf("mapped import: %v", err)
+	}
+	if report.Inserted != 1 || report.UnitSource != "flag" || report.FirstDate != "2026-03-05" {
+		t.Fatalf("unexpected mapped report %+v", report)
+	}
+	if _, err := service.ImportBodyCSV(db, strings.NewReader(generic), service.BodyImportOptions{Format: "omron"}); err == nil {
+		t.Fatalf("expected unsupported format error")
+	}
+}
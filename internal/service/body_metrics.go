@@ -0,0 +1,48 @@
+package service
+
+import "github.com/saadjs/kcal-cli/internal/model"
+
+// ffmiReferenceHeightM is the height FFMI is normalized to (Kouri et al.).
+const ffmiReferenceHeightM = 1.8
+
+// BodyMetrics are height-normalized measures of one measurement. Each is nil
+// when its inputs are missing: all need the profile height, FFMI needs body
+// fat, and waist-to-height needs a waist circumference.
+type BodyMetrics struct {
+	BMI            *float64 `json:"bmi,omitempty"`
+	FFMI           *float64 `json:"ffmi,omitempty"`
+	NormalizedFFMI *float64 `json:"normalized_ffmi,omitempty"`
+	WaistToHeight  *float64 `json:"waist_to_height,omitempty"`
+}
+
+// ComputeBodyMetrics derives BMI, FFMI, normalized FFMI and waist-to-height
+// ratio. It returns nil without a height.
+func ComputeBodyMetrics(heightCm *float64, weightKg float64, bodyFatPct, waistCm *float64) *BodyMetrics {
+	if heightCm == nil || *heightCm <= 0 || weightKg <= 0 {
+		return nil
+	}
+	heightM := *heightCm / 100
+	bmi := roundTo(weightKg/(heightM*heightM), 1)
+	out := &BodyMetrics{BMI: &bmi}
+	if bodyFatPct != nil {
+		ffmi := leanMassKg(weightKg, *bodyFatPct) / (heightM * heightM)
+		normalized := roundTo(ffmi+6.1*(ffmiReferenceHeightM-heightM), 1)
+		ffmi = roundTo(ffmi, 1)
+		out.FFMI, out.NormalizedFFMI = &ffmi, &normalized
+	}
+	if waistCm != nil {
+		ratio := roundTo(*waistCm / *heightCm, 3)
+		out.WaistToHeight = &ratio
+	}
+	return out
+}
+
+// MeasurementMetrics computes BodyMetrics for m with the profile height, using
+// a Navy body-fat estimate when m has no reading.
+func MeasurementMetrics(profile *model.Profile, m model.BodyMeasurement) *BodyMetrics {
+	if profile == nil {
+		return nil
+	}
+	bodyFat, _ := ResolveBodyFat(profile, m)
+	return ComputeBodyMetrics(profile.HeightCm, m.WeightKg, bodyFat, m.WaistCm)
+}
@@ -0,0 +1,76 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/service"
+)
+
+func TestComputeBodyMetrics(t *testing.T) {
+	t.Parallel()
+
+	height, bodyFat, waist := 180.0, 16.0, 90.0
+	m := service.ComputeBodyMetrics(&height, 81, &bodyFat, &waist)
+	if m == nil || m.BMI == nil || *m.BMI != 25 {
+		t.Fatalf("expected BMI 25.0, got %+v", m)
+	}
+	// Lean mass 68.04 kg over 1.8^2 m; at the reference height normalization is a no-op.
+	if m.FFMI == nil || *m.FFMI != 21 || m.NormalizedFFMI == nil || *m.NormalizedFFMI != 21 {
+		t.Fatalf("expected FFMI and normalized FFMI 21.0, got %+v", m)
+	}
+	if m.WaistToHeight == nil || *m.WaistToHeight != 0.5 {
+		t.Fatalf("expected waist-to-height 0.5, got %+v", m)
+	}
+
+	short := 160.0
+	m = service.ComputeBodyMetrics(&short, 60, &bodyFat, nil)
+	// FFMI 19.69 plus 6.1*(1.8-1.6) for the shorter height.
+	if m == nil || *m.FFMI != 19.7 || *m.NormalizedFFMI != 20.9 || m.WaistToHeight != nil {
+		t.Fatalf("expected FFMI 19.7 normalized to 20.9 without waist ratio, got %+v", m)
+	}
+	if m := service.ComputeBodyMetrics(nil, 81, &bodyFat, &waist); m != nil {
+		t.Fatalf("expected no metrics without height, got %+v", m)
+	}
+}
+
+func TestBodyMetricsInAnalyticsAndExport(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	if _, err := service.SetProfile(db, service.ProfileInput{Sex: "male", BirthDate: "1990-01-01", Height: 180, HeightUnit: "cm"}); err != nil {
+		t.Fatalf("set profile: %v", err)
+	}
+	day := time.Date(2026, 4, 1, 7, 0, 0, 0, time.Local)
+	bodyFat := 20.0
+	if _, err := service.AddBodyMeasurement(db, service.BodyMeasurementInput{Weight: 81, Unit: "kg", BodyFatPct: &bodyFat, MeasuredAt: day}); err != nil {
+		t.Fatalf("add measurement: %v", err)
+	}
+	if _, err := service.AddBodyMeasurement(db, service.BodyMeasurementInput{Weight: 77.76, Unit: "kg", MeasuredAt: day.AddDate(0, 0, 7)}); err != nil {
+		t.Fatalf("add measurement: %v", err)
+	}
+
+	report, err := service.AnalyticsRange(db, day, day.AddDate(0, 0, 7), 0.10)
+	if err != nil {
+		t.Fatalf("analytics: %v", err)
+	}
+	body := report.Body
+	if len(body.Points) != 2 || body.Points[0].Metrics == nil || *body.Points[0].Metrics.FFMI != 20 {
+		t.Fatalf("expected FFMI 20.0 on the first point, got %+v", body.Points)
+	}
+	if body.StartMetrics == nil || *body.StartMetrics.BMI != 25 || body.EndMetrics == nil || *body.EndMetrics.BMI != 24 {
+		t.Fatalf("expected BMI 25.0 -> 24.0, got start=%+v end=%+v", body.StartMetrics, body.EndMetrics)
+	}
+	if body.EndMetrics.FFMI != nil {
+		t.Fatalf("expected no FFMI without body fat, got %+v", body.EndMetrics)
+	}
+
+	data, err := service.ExportDataSnapshot(db)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if len(data.BodyMeasurements) != 2 || data.BodyMeasurements[0].Metrics == nil || data.BodyMeasurements[0].Metrics.BMI == nil {
+		t.Fatalf("expected exported measurements with metrics, got %+v", data.BodyMeasurements)
+	}
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// DefaultWeightTrendSmoothing is the share of each day's deviation from
+	// the trend that the trend absorbs.
+	DefaultWeightTrendSmoothing = 0.1
+
+	// weightTrendLookbackDays seeds the trend with earlier weigh-ins so the
+	// first point of a range is already smoothed.
+	weightTrendLookbackDays = 60
+)
+
+type WeightTrendPoint struct {
+	Date          string  `json:"date"`
+	WeightKg      float64 `json:"weight_kg"`
+	TrendWeightKg float64 `json:"trend_weight_kg"`
+	DeviationKg   float64 `json:"deviation_kg"`
+}
+
+// WeightTrendReport compares daily average weights with the smoothed trend.
+type WeightTrendReport struct {
+	FromDate           string             `json:"from_date"`
+	ToDate             string             `json:"to_date"`
+	Smoothing          float64            `json:"smoothing"`
+	StartTrendWeightKg float64            `json:"start_trend_weight_kg,omitempty"`
+	EndTrendWeightKg   float64            `json:"end_trend_weight_kg,omitempty"`
+	TrendChangeKg      float64            `json:"trend_change_kg"`
+	RawChangeKg        float64            `json:"raw_change_kg"`
+	TrendKgPerWeek     float64            `json:"trend_kg_per_week"`
+	Points             []WeightTrendPoint `json:"points"`
+}
+
+// BodyWeightTrend smooths weigh-ins between from and to (inclusive days). A
+// smoothing of zero uses DefaultWeightTrendSmoothing.
+func BodyWeightTrend(db *sql.DB, from, to time.Time, smoothing float64) (*WeightTrendReport, error) {
+	from, to = beginningOfDay(from), beginningOfDay(to)
+	if from.After(to) {
+		return nil, fmt.Errorf("from date must be <= to date")
+	}
+	if smoothing == 0 {
+		smoothing = DefaultWeightTrendSmoothing
+	}
+	points, err := smoothedDailyWeights(db, from, to, smoothing)
+	if err != nil {
+		return nil, err
+	}
+	out := &WeightTrendReport{
+		FromDate:  from.Format("2006-01-02"),
+		ToDate:    to.Format("2006-01-02"),
+		Smoothing: smoothing,
+		Points:    points,
+	}
+	if len(points) == 0 {
+		return out, nil
+	}
+	first, last := points[0], points[len(points)-1]
+	out.StartTrendWeightKg = first.TrendWeightKg
+	out.EndTrendWeightKg = last.TrendWeightKg
+	out.TrendChangeKg = roundTo(last.TrendWeightKg-first.TrendWeightKg, 3)
+	out.RawChangeKg = roundTo(last.WeightKg-first.WeightKg, 3)
+	start, _ := time.ParseInLocation("2006-01-02", first.Date, time.Local)
+	end, _ := time.ParseInLocation("2006-01-02", last.Date, time.Local)
+	if days := math.Round(end.Sub(start).Hours() / 24); days > 0 {
+		out.TrendKgPerWeek = roundTo(out.TrendChangeKg/days*7, 3)
+	}
+	return out, nil
+}
+
+// smoothedDailyWeights returns the daily average weights between from and to
+// with an exponential moving average trend. The trend starts at the first
+// weigh-in of the lookback window; after a gap of n days it moves as if the new
+// weight had been seen on each missing day.
+func smoothedDailyWeights(db *sql.DB, from, to time.Time, smoothing float64) ([]WeightTrendPoint, error) {
+	if smoothing <= 0 || smoothing > 1 {
+		return nil, fmt.Errorf("smoothing must be > 0 and <= 1")
+	}
+	seedFrom := from.AddDate(0, 0, -weightTrendLookbackDays)
+	days, weights, err := loadDailyWeights(db, seedFrom, to)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]WeightTrendPoint, 0, len(weights))
+	var trend float64
+	for i, w := range weights {
+		if i == 0 {
+			trend = w
+		} else {
+			gap := days[i] - days[i-1]
+			trend += (1 - math.Pow(1-smoothing, gap)) * (w - trend)
+		}
+		date := seedFrom.AddDate(0, 0, int(days[i]))
+		if date.Before(from) {
+			continue
+		}
+		points = append(points, WeightTrendPoint{
+			Date:          date.Format("2006-01-02"),
+			WeightKg:      roundTo(w, 3),
+			TrendWeightKg: roundTo(trend, 3),
+			DeviationKg:   roundTo(w-trend, 3),
+		})
+	}
+	return points, nil
+}
@@ -0,0 +1,57 @@
+package service_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/service"
+)
+
+func TestBodyWeightTrendSmoothsAcrossGaps(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	day := time.Date(2026, 3, 1, 7, 0, 0, 0, time.Local)
+	for offset, weight := range map[int]float64{0: 80, 1: 81, 3: 79} {
+		if _, err := service.AddBodyMeasurement(db, service.BodyMeasurementInput{Weight: weight, Unit: "kg", MeasuredAt: day.AddDate(0, 0, offset)}); err != nil {
+			t.Fatalf("add measurement day %d: %v", offset, err)
+		}
+	}
+
+	report, err := service.BodyWeightTrend(db, day.AddDate(0, 0, 1), day.AddDate(0, 0, 3), 0)
+	if err != nil {
+		t.Fatalf("weight trend: %v", err)
+	}
+	if report.Smoothing != service.DefaultWeightTrendSmoothing || len(report.Points) != 2 {
+		t.Fatalf("expected 2 points with default smoothing, got %+v", report)
+	}
+	// The day before the range seeds the trend: 80 + 0.1*(81-80).
+	if got := report.Points[0].TrendWeightKg; math.Abs(got-80.1) > 1e-9 {
+		t.Fatalf("expected seeded trend 80.1, got %.3f", got)
+	}
+	// A two-day gap absorbs 1-0.9^2 = 19% of the deviation.
+	if got := report.Points[1].TrendWeightKg; math.Abs(got-79.891) > 1e-9 {
+		t.Fatalf("expected gap-adjusted trend 79.891, got %.3f", got)
+	}
+	if math.Abs(report.TrendChangeKg+0.209) > 1e-9 || math.Abs(report.RawChangeKg+2) > 1e-9 {
+		t.Fatalf("expected trend change -0.209 and raw change -2, got %+v", report)
+	}
+
+	analytics, err := service.AnalyticsRange(db, day.AddDate(0, 0, 1), day.AddDate(0, 0, 3), 0.10)
+	if err != nil {
+		t.Fatalf("analytics: %v", err)
+	}
+	body := analytics.Body
+	if body.StartWeightKg != 81 || body.EndWeightKg != 79 {
+		t.Fatalf("expected raw start/end weights, got %+v", body)
+	}
+	if math.Abs(body.WeightChangeKg+0.209) > 1e-9 || math.Abs(body.Points[1].TrendWeightKg-79.891) > 1e-9 {
+		t.Fatalf("expected summary change from trend weights, got %+v", body)
+	}
+
+	if _, err := service.BodyWeightTrend(db, day, day, 1.5); err == nil {
+		t.Fatalf("expected invalid smoothing error")
+	}
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ConfigExerciseEatBack = "exercise_eat_back"
+	// ConfigExerciseEatBackTypePrefix is followed by an exercise type, e.g.
+	// "exercise_eat_back.walking".
+	ConfigExerciseEatBackTypePrefix = ConfigExerciseEatBack + "."
+
+	EatBackNone    = "none"
+	EatBackPercent = "percent"
+	EatBackCap     = "cap"
+)
+
+// EatBackPolicy controls how much logged exercise is added back to the
+// calorie goal.
+type EatBackPolicy struct {
+	Mode    string  `json:"mode"`
+	Percent float64 `json:"percent,omitempty"`
+	CapKcal int     `json:"cap_kcal,omitempty"`
+}
+
+type EatBackSettings struct {
+	Default EatBackPolicy            `json:"default"`
+	ByType  map[string]EatBackPolicy `json:"by_type,omitempty"`
+}
+
+// ParseEatBackPolicy accepts "none", "full", a percentage such as "50%", or a
+// daily cap such as "cap:300".
+func ParseEatBackPolicy(raw string) (EatBackPolicy, error) {
+	value := strings.ToLower(strings.TrimSpace(raw))
+	switch {
+	case value == "" || value == "full":
+		return EatBackPolicy{Mode: EatBackPercent, Percent: 100}, nil
+	case value == EatBackNone:
+		return EatBackPolicy{Mode: EatBackNone}, nil
+	case strings.HasPrefix(value, EatBackCap+":"):
+		capKcal, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(value, EatBackCap+":")))
+		if err != nil || capKcal < 0 {
+			return EatBackPolicy{}, fmt.Errorf("invalid eat-back cap %q (expected cap:<kcal>)", raw)
+		}
+		return EatBackPolicy{Mode: EatBackCap, CapKcal: capKcal}, nil
+	case strings.HasSuffix(value, "%"):
+		pct, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(value, "%")), 64)
+		if err != nil || pct < 0 || pct > 100 {
+			return EatBackPolicy{}, fmt.Errorf("invalid eat-back percentage %q (expected 0%%-100%%)", raw)
+		}
+		return EatBackPolicy{Mode: EatBackPercent, Percent: pct}, nil
+	default:
+		return EatBackPolicy{}, fmt.Errorf("invalid eat-back policy %q (expected none, full, N%%, or cap:N)", raw)
+	}
+}
+
+func (p EatBackPolicy) String() string {
+	switch p.Mode {
+	case EatBackNone:
+		return EatBackNone
+	case EatBackCap:
+		return fmt.Sprintf("cap:%d", p.CapKcal)
+	default:
+		return strconv.FormatFloat(p.Percent, 'f', -1, 64) + "%"
+	}
+}
+
+// Credit returns the calories added back for exerciseCalories burned in a day.
+func (p EatBackPolicy) Credit(exerciseCalories int) int {
+	if exerciseCalories <= 0 {
+		return 0
+	}
+	switch p.Mode {
+	case EatBackNone:
+		return 0
+	case EatBackCap:
+		if exerciseCalories > p.CapKcal {
+			return p.CapKcal
+		}
+		return exerciseCalories
+	default:
+		return int(math.Round(float64(exerciseCalories) * p.Percent / 100))
+	}
+}
+
+func (s EatBackSettings) String() string {
+	if len(s.ByType) == 0 {
+		return s.Default.String()
+	}
+	types := make([]string, 0, len(s.ByType))
+	for t := range s.ByType {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	parts := make([]string, 0, len(types))
+	for _, t := range types {
+		parts = append(parts, t+"="+s.ByType[t].String())
+	}
+	return fmt.Sprintf("%s (%s)", s.Default.String(), strings.Join(parts, ", "))
+}
+
+// CreditByType applies per-type policies to their own daily totals and the
+// default policy to the remaining exercise.
+func (s EatBackSettings) CreditByType(caloriesByType map[string]int) int {
+	credit := 0
+	rest := 0
+	for exerciseType, calories := range caloriesByType {
+		if p, ok := s.ByType[exerciseType]; ok {
+			credit += p.Credit(calories)
+			continue
+		}
+		rest += calories
+	}
+	return credit + s.Default.Credit(rest)
+}
+
+// LoadEatBackSettings reads the configured eat-back policy. With nothing
+// configured all exercise calories are eaten back.
+func LoadEatBackSettings(db *sql.DB) (EatBackSettings, error) {
+	cfg, err := ListConfig(db)
+	if err != nil {
+		return EatBackSettings{}, err
+	}
+	out := EatBackSettings{}
+	out.Default, err = ParseEatBackPolicy(cfg[ConfigExerciseEatBack])
+	if err != nil {
+		return out, fmt.Errorf("config %s: %w", ConfigExerciseEatBack, err)
+	}
+	for key, value := range cfg {
+		if !strings.HasPrefix(key, ConfigExerciseEatBackTypePrefix) || strings.TrimSpace(value) == "" {
+			continue
+		}
+		p, err := ParseEatBackPolicy(value)
+		if err != nil {
+			return out, fmt.Errorf("config %s: %w", key, err)
+		}
+		if out.ByType == nil {
+			out.ByType = map[string]EatBackPolicy{}
+		}
+		out.ByType[strings.TrimPrefix(key, ConfigExerciseEatBackTypePrefix)] = p
+	}
+	return out, nil
+}
+
+// eatBackCredit returns the calories credited back on date given the day's
+// logged exercise calories and the step calories that count as exercise,
+// splitting by exercise type only when needed. Step calories are credited
+// under StepsExerciseType.
+func (s EatBackSettings) eatBackCredit(db *sql.DB, date string, exerciseCalories, stepCalories int) (int, error) {
+	if len(s.ByType) == 0 || exerciseCalories+stepCalories == 0 {
+		return s.Default.Credit(exerciseCalories + stepCalories), nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return 0, fmt.Errorf("invalid date %q (expected YYYY-MM-DD)", date)
+	}
+	rows, err := db.Query(`
+SELECT exercise_type, SUM(calories_burned)
+FROM exercise_logs
+WHERE performed_at >= ? AND performed_at < ?
+GROUP BY exercise_type
+`, day.Format(time.RFC3339), day.Add(24*time.Hour).Format(time.RFC3339))
+	if err != nil {
+		return 0, fmt.Errorf("query exercise calories by type: %w", err)
+	}
+	defer rows.Close()
+	byType := map[string]int{}
+	for rows.Next() {
+		var exerciseType string
+		var calories int
+		if err := rows.Scan(&exerciseType, &calories); err != nil {
+			return 0, fmt.Errorf("scan exercise calories by type: %w", err)
+		}
+		byType[exerciseType] = calories
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterate exercise calories by type: %w", err)
+	}
+	if stepCalories > 0 {
+		byType[StepsExerciseType] += stepCalories
+	}
+	return s.CreditByType(byType), nil
+}
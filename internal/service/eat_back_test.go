@@ -0,0 +1,124 @@
+package service_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/service"
+)
+
+func TestParseEatBackPolicy(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]int{"": 400, "full": 400, "none": 0, "50%": 200, "cap:300": 300, "cap:1000": 400}
+	for raw, want := range cases {
+		p, err := service.ParseEatBackPolicy(raw)
+		if err != nil {
+			t.Fatalf("parse %q: %v", raw, err)
+		}
+		if got := p.Credit(400); got != want {
+			t.Fatalf("policy %q credit for 400 kcal: expected %d, got %d", raw, want, got)
+		}
+	}
+	for _, raw := range []string{"150%", "cap:-1", "half"} {
+		if _, err := service.ParseEatBackPolicy(raw); err == nil {
+			t.Fatalf("expected %q to be rejected", raw)
+		}
+	}
+}
+
+func TestEatBackPolicyAppliedToTodayAndAdherence(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	if err := service.SetGoal(db, service.SetGoalInput{Calories: 2000, ProteinG: 150, CarbsG: 200, FatG: 70, EffectiveDate: "2026-02-01"}); err != nil {
+		t.Fatalf("set goal: %v", err)
+	}
+	day := time.Date(2026, 2, 10, 0, 0, 0, 0, time.Local)
+	if _, err := service.CreateEntry(db, service.CreateEntryInput{Name: "Big day", Calories: 2300, ProteinG: 190, CarbsG: 255, FatG: 88, Category: "dinner", Consumed: day.Add(19 * time.Hour), SourceType: "manual"}); err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+	for _, ex := range []service.ExerciseLogInput{
+		{ExerciseType: "run", CaloriesBurned: 400, PerformedAt: day.Add(7 * time.Hour)},
+		{ExerciseType: "walking", CaloriesBurned: 200, PerformedAt: day.Add(12 * time.Hour)},
+	} {
+		if _, err := service.CreateExerciseLog(db, ex); err != nil {
+			t.Fatalf("create exercise %s: %v", ex.ExerciseType, err)
+		}
+	}
+
+	report, err := service.AnalyticsRange(db, day, day, 0.10)
+	if err != nil {
+		t.Fatalf("analytics with default policy: %v", err)
+	}
+	if report.Adherence.EatBackPolicy != "100%" || report.Adherence.WithinGoalDays != 1 || report.Days[0].EatBackCalories != 600 {
+		t.Fatalf("expected full eat-back to keep day within goal, got %+v / %+v", report.Adherence, report.Days[0])
+	}
+
+	if err := service.SetConfig(db, service.ConfigExerciseEatBack, "50%"); err != nil {
+		t.Fatalf("set eat-back policy: %v", err)
+	}
+	if err := service.SetConfig(db, service.ConfigExerciseEatBackTypePrefix+"walking", "none"); err != nil {
+		t.Fatalf("set walking eat-back policy: %v", err)
+	}
+	report, err = service.AnalyticsRange(db, day, day, 0.10)
+	if err != nil {
+		t.Fatalf("analytics with partial policy: %v", err)
+	}
+	if report.Days[0].EatBackCalories != 200 || report.Days[0].EffectiveGoalCalories != 2200 || report.Adherence.WithinGoalDays != 0 {
+		t.Fatalf("expected 200 kcal credit and day over goal, got %+v / %+v", report.Adherence, report.Days[0])
+	}
+	if report.Adherence.EatBackPolicy != "50% (walking=none)" {
+		t.Fatalf("unexpected policy description %q", report.Adherence.EatBackPolicy)
+	}
+
+	status, err := service.TodaySummary(db, day)
+	if err != nil {
+		t.Fatalf("today summary: %v", err)
+	}
+	if status.EatBackCalories != 200 || status.RemainingCalories != -100 {
+		t.Fatalf("expected 200 kcal eat-back and 100 kcal over, got %+v", status)
+	}
+	// The 200 kcal credit is split by the goal's macro energy (600/800/630 of
+	// 2030 kcal), so protein, carbs and fat targets grow with it.
+	near := func(got, want float64) bool { return math.Abs(got-want) < 0.01 }
+	if !near(status.RemainingProteinG, 150+200*600.0/2030/4-190) ||
+		!near(status.RemainingCarbsG, 200+200*800.0/2030/4-255) ||
+		!near(status.RemainingFatG, 70+200*630.0/2030/9-88) {
+		t.Fatalf("expected macro remainders against eat-back adjusted targets, got %.2f/%.2f/%.2f", status.RemainingProteinG, status.RemainingCarbsG, status.RemainingFatG)
+	}
+}
+
+func TestEatBackPolicyAppliedToWithinGoalStreak(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	if err := service.SetGoal(db, service.SetGoalInput{Calories: 2000, ProteinG: 150, CarbsG: 200, FatG: 70, EffectiveDate: "2026-02-01"}); err != nil {
+		t.Fatalf("set goal: %v", err)
+	}
+	day := time.Date(2026, 2, 10, 0, 0, 0, 0, time.Local)
+	if _, err := service.CreateEntry(db, service.CreateEntryInput{Name: "Dinner", Calories: 2250, ProteinG: 160, CarbsG: 215, FatG: 75, Category: "dinner", Consumed: day.Add(19 * time.Hour), SourceType: "manual"}); err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+	if _, err := service.CreateExerciseLog(db, service.ExerciseLogInput{ExerciseType: "run", CaloriesBurned: 600, PerformedAt: day.Add(7 * time.Hour)}); err != nil {
+		t.Fatalf("create exercise: %v", err)
+	}
+
+	// 2250 kcal is over the 2000 kcal goal unless exercise is eaten back; with
+	// 50% the goal is 2300 kcal.
+	for policy, want := range map[string]int{"none": 0, "50%": 1} {
+		if err := service.SetConfig(db, service.ConfigExerciseEatBack, policy); err != nil {
+			t.Fatalf("set eat-back policy %s: %v", policy, err)
+		}
+		report, err := service.AnalyticsInsightsRange(db, day, day, 0.10, service.InsightsGranularityDay)
+		if err != nil {
+			t.Fatalf("insights with policy %s: %v", policy, err)
+		}
+		if report.Streaks.WithinGoal.Current != want || report.Current.Adherence.WithinGoalDays != want {
+			t.Fatalf("policy %s: expected within-goal streak %d matching adherence, got %+v / %+v", policy, want, report.Streaks.WithinGoal, report.Current.Adherence)
+		}
+	}
+}
@@ -38,7 +38,11 @@ func CreateExerciseLog(db *sql.DB, in ExerciseLogInput) (int64, error) {
 	if err != nil {
 		return 0, err
 	}
-	res, err := db.Exec(`
+	return insertExerciseLog(db, normalized)
+}
+
+func insertExerciseLog(exec sqlExecutor, normalized ExerciseLogInput) (int64, error) {
+	res, err := exec.Exec(`
 INSERT INTO exercise_logs(exercise_type, calories_burned, duration_min, distance, distance_unit, performed_at, notes, metadata_json)
 VALUES(?, ?, ?, ?, ?, ?, ?, ?)
 `, normalized.ExerciseType, normalized.CaloriesBurned, normalized.DurationMin, normalized.Distance, nullableString(normalized.DistanceUnit), normalized.PerformedAt.Format(time.RFC3339), normalized.Notes, normalized.Metadata)
@@ -57,7 +61,7 @@ func ListExerciseLogs(db *sql.DB, f ListExerciseFilter) ([]model.ExerciseLog, er
 		return nil, fmt.Errorf("--date cannot be combined with --from or --to")
 	}
 
-	query := `SELECT id, exercise_type, calories_burned, duration_min, distance, IFNULL(distance_unit, ''), performed_at, IFNULL(notes, ''), IFNULL(metadata_json, ''), created_at, updated_at FROM exercise_logs WHERE 1=1`
+	query := `SELECT ` + exerciseLogColumns + ` FROM exercise_logs WHERE 1=1`
 	args := make([]any, 0)
 	if strings.TrimSpace(f.Date) != "" {
 		start, end, err := dayBounds(f.Date)
@@ -103,30 +107,10 @@ func ListExerciseLogs(db *sql.DB, f ListExerciseFilter) ([]model.ExerciseLog, er
 
 	items := make([]model.ExerciseLog, 0)
 	for rows.Next() {
-		var item model.ExerciseLog
-		var duration sql.NullInt64
-		var distance sql.NullFloat64
-		var performedAtRaw string
-		var createdRaw string
-		var updatedRaw string
-		if err := rows.Scan(&item.ID, &item.ExerciseType, &item.CaloriesBurned, &duration, &distance, &item.DistanceUnit, &performedAtRaw, &item.Notes, &item.Metadata, &createdRaw, &updatedRaw); err != nil {
-			return nil, fmt.Errorf("scan exercise log: %w", err)
-		}
-		performedAt, err := time.Parse(time.RFC3339, performedAtRaw)
+		item, err := scanExerciseLog(rows.Scan)
 		if err != nil {
-			return nil, fmt.Errorf("parse performed_at: %w", err)
-		}
-		item.PerformedAt = performedAt
-		if duration.Valid {
-			v := int(duration.Int64)
-			item.DurationMin = &v
-		}
-		if distance.Valid {
-			v := distance.Float64
-			item.Distance = &v
+			return nil, fmt.Errorf("scan exercise log: %w", err)
 		}
-		item.CreatedAt, _ = time.Parse(time.RFC3339, createdRaw)
-		item.UpdatedAt, _ = time.Parse(time.RFC3339, updatedRaw)
 		items = append(items, item)
 	}
 	if err := rows.Err(); err != nil {
@@ -135,6 +119,50 @@ func ListExerciseLogs(db *sql.DB, f ListExerciseFilter) ([]model.ExerciseLog, er
 	return items, nil
 }
 
+// GetExerciseLog returns one exercise log by id.
+func GetExerciseLog(db *sql.DB, id int64) (model.ExerciseLog, error) {
+	item, err := scanExerciseLog(db.QueryRow(`SELECT `+exerciseLogColumns+` FROM exercise_logs WHERE id = ?`, id).Scan)
+	if err == sql.ErrNoRows {
+		return model.ExerciseLog{}, fmt.Errorf("exercise log %d not found", id)
+	}
+	if err != nil {
+		return model.ExerciseLog{}, fmt.Errorf("get exercise log %d: %w", id, err)
+	}
+	return item, nil
+}
+
+const exerciseLogColumns = `id, exercise_type, calories_burned, duration_min, distance, IFNULL(distance_unit, ''), performed_at, IFNULL(notes, ''), IFNULL(metadata_json, ''), created_at, updated_at`
+
+// scanExerciseLog reads one row selected with exerciseLogColumns. Scan errors
+// are returned unwrapped so callers can check for sql.ErrNoRows.
+func scanExerciseLog(scan func(dest ...any) error) (model.ExerciseLog, error) {
+	var item model.ExerciseLog
+	var duration sql.NullInt64
+	var distance sql.NullFloat64
+	var performedAtRaw string
+	var createdRaw string
+	var updatedRaw string
+	if err := scan(&item.ID, &item.ExerciseType, &item.CaloriesBurned, &duration, &distance, &item.DistanceUnit, &performedAtRaw, &item.Notes, &item.Metadata, &createdRaw, &updatedRaw); err != nil {
+		return item, err
+	}
+	performedAt, err := time.Parse(time.RFC3339, performedAtRaw)
+	if err != nil {
+		return item, fmt.Errorf("parse performed_at: %w", err)
+	}
+	item.PerformedAt = performedAt
+	if duration.Valid {
+		v := int(duration.Int64)
+		item.DurationMin = &v
+	}
+	if distance.Valid {
+		v := distance.Float64
+		item.Distance = &v
+	}
+	item.CreatedAt, _ = time.Parse(time.RFC3339, createdRaw)
+	item.UpdatedAt, _ = time.Parse(time.RFC3339, updatedRaw)
+	return item, nil
+}
+
 func UpdateExerciseLog(db *sql.DB, in UpdateExerciseInput) error {
 	if in.ID <= 0 {
 		return fmt.Errorf("exercise id must be > 0")
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/model"
+)
+
+// longestExerciseSessions caps the longest sessions list.
+const longestExerciseSessions = 5
+
+// ExerciseSummary breaks logged exercise down by type and week for a date
+// range. Distances are normalized to km; pace is minutes per km over sessions
+// that have both a duration and a distance.
+type ExerciseSummary struct {
+	Sessions        int                   `json:"sessions"`
+	Calories        int                   `json:"calories"`
+	DurationMin     int                   `json:"duration_min"`
+	DistanceKm      float64               `json:"distance_km"`
+	ByType          []ExerciseTypeSummary `json:"by_type"`
+	Weeks           []ExerciseWeekSummary `json:"weeks"`
+	LongestSessions []ExerciseSession     `json:"longest_sessions"`
+}
+
+type ExerciseTypeSummary struct {
+	ExerciseType    string   `json:"exercise_type"`
+	Sessions        int      `json:"sessions"`
+	Calories        int      `json:"calories"`
+	DurationMin     int      `json:"duration_min"`
+	DistanceKm      float64  `json:"distance_km"`
+	AvgPaceMinPerKm *float64 `json:"avg_pace_min_per_km,omitempty"`
+}
+
+// ExerciseWeekSummary covers one Monday-based week. Weeks without sessions
+// are kept so the series can be charted directly; pace is only reported per
+// type since mixing activities makes it meaningless.
+type ExerciseWeekSummary struct {
+	WeekStart   string                `json:"week_start"`
+	WeekEnd     string                `json:"week_end"`
+	Sessions    int                   `json:"sessions"`
+	Calories    int                   `json:"calories"`
+	DurationMin int                   `json:"duration_min"`
+	DistanceKm  float64               `json:"distance_km"`
+	ByType      []ExerciseTypeSummary `json:"by_type"`
+}
+
+type ExerciseSession struct {
+	ID           int64    `json:"id"`
+	Date         string   `json:"date"`
+	ExerciseType string   `json:"exercise_type"`
+	Calories     int      `json:"calories"`
+	DurationMin  int      `json:"duration_min"`
+	DistanceKm   *float64 `json:"distance_km,omitempty"`
+	PaceMinPerKm *float64 `json:"pace_min_per_km,omitempty"`
+}
+
+type ExerciseAnalyticsReport struct {
+	FromDate string `json:"from_date"`
+	ToDate   string `json:"to_date"`
+	ExerciseSummary
+}
+
+// AnalyticsExercise returns the exercise breakdown for an inclusive date range.
+func AnalyticsExercise(db *sql.DB, from, to time.Time) (*ExerciseAnalyticsReport, error) {
+	if from.After(to) {
+		return nil, fmt.Errorf("from date must be <= to date")
+	}
+	from = beginningOfDay(from)
+	to = beginningOfDay(to)
+	summary, err := calculateExerciseSummary(db, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &ExerciseAnalyticsReport{
+		FromDate:        from.Format("2006-01-02"),
+		ToDate:          to.Format("2006-01-02"),
+		ExerciseSummary: summary,
+	}, nil
+}
+
+// paceTotals accumulates the duration and distance of sessions that have both,
+// so average pace is weighted by distance rather than by session.
+type paceTotals struct {
+	minutes float64
+	km      float64
+}
+
+func (p *paceTotals) add(log model.ExerciseLog) {
+	km := exerciseLogDistanceKm(log)
+	if log.DurationMin == nil || km == nil {
+		return
+	}
+	p.minutes += float64(*log.DurationMin)
+	p.km += *km
+}
+
+func (p paceTotals) pace() *float64 {
+	if p.km <= 0 {
+		return nil
+	}
+	v := roundTo(p.minutes/p.km, 2)
+	return &v
+}
+
+type exerciseTypeTotals struct {
+	summary ExerciseTypeSummary
+	pace    paceTotals
+}
+
+func addExerciseTypeTotals(types map[string]*exerciseTypeTotals, log model.ExerciseLog) {
+	t, ok := types[log.ExerciseType]
+	if !ok {
+		t = &exerciseTypeTotals{summary: ExerciseTypeSummary{ExerciseType: log.ExerciseType}}
+		types[log.ExerciseType] = t
+	}
+	t.summary.Sessions++
+	t.summary.Calories += log.CaloriesBurned
+	if log.DurationMin != nil {
+		t.summary.DurationMin += *log.DurationMin
+	}
+	if km := exerciseLogDistanceKm(log); km != nil {
+		t.summary.DistanceKm += *km
+	}
+	t.pace.add(log)
+}
+
+// exerciseTypeSummaries finalizes per-type totals, ordered by calories.
+func exerciseTypeSummaries(types map[string]*exerciseTypeTotals) []ExerciseTypeSummary {
+	out := make([]ExerciseTypeSummary, 0, len(types))
+	for _, t := range types {
+		item := t.summary
+		item.DistanceKm = roundTo(item.DistanceKm, 2)
+		item.AvgPaceMinPerKm = t.pace.pace()
+		out = append(out, item)
+	}
+	slices.SortFunc(out, func(a, b ExerciseTypeSummary) int {
+		if a.Calories != b.Calories {
+			return b.Calories - a.Calories
+		}
+		return strings.Compare(a.ExerciseType, b.ExerciseType)
+	})
+	return out
+}
+
+func calculateExerciseSummary(db *sql.DB, from, to time.Time) (ExerciseSummary, error) {
+	summary := ExerciseSummary{
+		ByType:          make([]ExerciseTypeSummary, 0),
+		Weeks:           make([]ExerciseWeekSummary, 0),
+		LongestSessions: make([]ExerciseSession, 0),
+	}
+	logs, err := listExerciseLogsInRange(db, from, to)
+	if err != nil {
+		return summary, err
+	}
+	if len(logs) == 0 {
+		return summary, nil
+	}
+
+	weekIndex := map[string]int{}
+	for start := beginningOfWeekLocal(from); !start.After(to); start = start.AddDate(0, 0, 7) {
+		key := start.Format("2006-01-02")
+		weekIndex[key] = len(summary.Weeks)
+		summary.Weeks = append(summary.Weeks, ExerciseWeekSummary{WeekStart: key, WeekEnd: start.AddDate(0, 0, 6).Format("2006-01-02")})
+	}
+	types := map[string]*exerciseTypeTotals{}
+	weekTypes := make([]map[string]*exerciseTypeTotals, len(summary.Weeks))
+	sessions := make([]ExerciseSession, 0, len(logs))
+
+	end := to.Add(24 * time.Hour)
+	for _, log := range logs {
+		// Logs stored with a different UTC offset can sort into the query
+		// bounds while falling outside the local range.
+		performedAt := log.PerformedAt.In(time.Local)
+		if performedAt.Before(from) || !performedAt.Before(end) {
+			continue
+		}
+		i, ok := weekIndex[beginningOfWeekLocal(performedAt).Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		duration := 0
+		if log.DurationMin != nil {
+			duration = *log.DurationMin
+		}
+		km := exerciseLogDistanceKm(log)
+		summary.Sessions++
+		summary.Calories += log.CaloriesBurned
+		summary.DurationMin += duration
+
+		addExerciseTypeTotals(types, log)
+		if weekTypes[i] == nil {
+			weekTypes[i] = map[string]*exerciseTypeTotals{}
+		}
+		addExerciseTypeTotals(weekTypes[i], log)
+
+		w := &summary.Weeks[i]
+		w.Sessions++
+		w.Calories += log.CaloriesBurned
+		w.DurationMin += duration
+
+		session := ExerciseSession{
+			ID:           log.ID,
+			Date:         performedAt.Format("2006-01-02"),
+			ExerciseType: log.ExerciseType,
+			Calories:     log.CaloriesBurned,
+			DurationMin:  duration,
+		}
+		if km != nil {
+			summary.DistanceKm += *km
+			w.DistanceKm += *km
+			d := roundTo(*km, 2)
+			session.DistanceKm = &d
+			if duration > 0 {
+				pace := roundTo(float64(duration) / *km, 2)
+				session.PaceMinPerKm = &pace
+			}
+		}
+		sessions = append(sessions, session)
+	}
+
+	summary.DistanceKm = roundTo(summary.DistanceKm, 2)
+	summary.ByType = exerciseTypeSummaries(types)
+	for i := range summary.Weeks {
+		w := &summary.Weeks[i]
+		w.DistanceKm = roundTo(w.DistanceKm, 2)
+		w.ByType = exerciseTypeSummaries(weekTypes[i])
+	}
+
+	// Longest by duration; distance breaks ties and ranks sessions logged
+	// without a duration.
+	slices.SortStableFunc(sessions, func(a, b ExerciseSession) int {
+		if a.DurationMin != b.DurationMin {
+			return b.DurationMin - a.DurationMin
+		}
+		distA, distB := 0.0, 0.0
+		if a.DistanceKm != nil {
+			distA = *a.DistanceKm
+		}
+		if b.DistanceKm != nil {
+			distB = *b.DistanceKm
+		}
+		switch {
+		case distA > distB:
+			return -1
+		case distA < distB:
+			return 1
+		}
+		return 0
+	})
+	summary.LongestSessions = sessions[:min(len(sessions), longestExerciseSessions)]
+	return summary, nil
+}
+
+// exerciseLogDistanceKm returns the log's distance in km, or nil when it has
+// none.
+func exerciseLogDistanceKm(log model.ExerciseLog) *float64 {
+	if log.Distance == nil || *log.Distance <= 0 {
+		return nil
+	}
+	km := *log.Distance
+	if log.DistanceUnit == "mi" {
+		km *= kmPerMile
+	}
+	return &km
+}
+
+func listExerciseLogsInRange(db *sql.DB, from, to time.Time) ([]model.ExerciseLog, error) {
+	rows, err := db.Query(`SELECT `+exerciseLogColumns+` FROM exercise_logs WHERE performed_at >= ? AND performed_at < ? ORDER BY performed_at ASC, id ASC`,
+		from.Format(time.RFC3339), to.Add(24*time.Hour).Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("query exercise logs: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]model.ExerciseLog, 0)
+	for rows.Next() {
+		item, err := scanExerciseLog(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("scan exercise log: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate exercise logs: %w", err)
+	}
+	return items, nil
+}
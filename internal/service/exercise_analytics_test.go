@@ -0,0 +1,92 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/service"
+)
+
+func TestAnalyticsExerciseBreakdown(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	intPtr := func(v int) *int { return &v }
+	floatPtr := func(v float64) *float64 { return &v }
+	logs := []service.ExerciseLogInput{
+		{ExerciseType: "running", CaloriesBurned: 400, DurationMin: intPtr(30), Distance: floatPtr(6), DistanceUnit: "km", PerformedAt: time.Date(2026, 2, 2, 7, 0, 0, 0, time.Local)},
+		{ExerciseType: "running", CaloriesBurned: 700, DurationMin: intPtr(60), Distance: floatPtr(6.2137), DistanceUnit: "mi", PerformedAt: time.Date(2026, 2, 10, 7, 0, 0, 0, time.Local)},
+		{ExerciseType: "cycling", CaloriesBurned: 500, DurationMin: intPtr(50), Distance: floatPtr(20), DistanceUnit: "km", PerformedAt: time.Date(2026, 2, 11, 18, 0, 0, 0, time.Local)},
+		{ExerciseType: "yoga", CaloriesBurned: 150, DurationMin: intPtr(45), PerformedAt: time.Date(2026, 2, 12, 8, 0, 0, 0, time.Local)},
+		{ExerciseType: "running", CaloriesBurned: 900, DurationMin: intPtr(90), Distance: floatPtr(18), DistanceUnit: "km", PerformedAt: time.Date(2026, 3, 1, 7, 0, 0, 0, time.Local)},
+	}
+	for _, in := range logs {
+		if _, err := service.CreateExerciseLog(db, in); err != nil {
+			t.Fatalf("create exercise log: %v", err)
+		}
+	}
+
+	from := time.Date(2026, 2, 1, 0, 0, 0, 0, time.Local)
+	to := time.Date(2026, 2, 20, 0, 0, 0, 0, time.Local)
+	report, err := service.AnalyticsExercise(db, from, to)
+	if err != nil {
+		t.Fatalf("exercise analytics: %v", err)
+	}
+	if report.Sessions != 4 || report.Calories != 1750 || report.DurationMin != 185 || report.DistanceKm != 36 {
+		t.Fatalf("unexpected totals %+v", report.ExerciseSummary)
+	}
+	if len(report.ByType) != 3 || report.ByType[0].ExerciseType != "running" || report.ByType[2].ExerciseType != "yoga" {
+		t.Fatalf("expected types ordered by calories, got %+v", report.ByType)
+	}
+	running := report.ByType[0]
+	// 90 minutes over 16 km; the mile run is normalized to 10 km.
+	if running.Sessions != 2 || running.DistanceKm != 16 || running.AvgPaceMinPerKm == nil || *running.AvgPaceMinPerKm != 5.63 {
+		t.Fatalf("unexpected running summary %+v", running)
+	}
+	if yoga := report.ByType[2]; yoga.AvgPaceMinPerKm != nil || yoga.DistanceKm != 0 {
+		t.Fatalf("expected no pace without distance, got %+v", yoga)
+	}
+
+	// Monday-based weeks from 2026-01-26, including empty weeks.
+	if len(report.Weeks) != 4 || report.Weeks[0].WeekStart != "2026-01-26" || report.Weeks[0].Sessions != 0 {
+		t.Fatalf("unexpected weeks %+v", report.Weeks)
+	}
+	week := report.Weeks[2]
+	if week.WeekStart != "2026-02-09" || week.Sessions != 3 || week.DistanceKm != 30 || len(week.ByType) != 3 {
+		t.Fatalf("unexpected week %+v", week)
+	}
+	if pace := week.ByType[0].AvgPaceMinPerKm; week.ByType[0].ExerciseType != "running" || pace == nil || *pace != 6 {
+		t.Fatalf("expected weekly running pace 6 min/km, got %+v", week.ByType[0])
+	}
+
+	// Stored 12 hours behind local time: the timestamp text sorts inside the
+	// range, but the session falls on 2026-02-21 locally.
+	_, offset := to.Zone()
+	late := time.Date(2026, 2, 20, 23, 0, 0, 0, time.FixedZone("", offset-12*3600))
+	if _, err := service.CreateExerciseLog(db, service.ExerciseLogInput{ExerciseType: "running", CaloriesBurned: 300, DurationMin: intPtr(30), PerformedAt: late}); err != nil {
+		t.Fatalf("create offset exercise log: %v", err)
+	}
+	if report, err = service.AnalyticsExercise(db, from, to); err != nil {
+		t.Fatalf("exercise analytics with offset log: %v", err)
+	}
+	if report.Sessions != 4 || report.Calories != 1750 {
+		t.Fatalf("expected log outside the local range to be skipped, got %+v", report.ExerciseSummary)
+	}
+
+	if len(report.LongestSessions) != 4 {
+		t.Fatalf("expected 4 longest sessions, got %d", len(report.LongestSessions))
+	}
+	longest := report.LongestSessions[0]
+	if longest.DurationMin != 60 || longest.Date != "2026-02-10" || longest.DistanceKm == nil || *longest.DistanceKm != 10 {
+		t.Fatalf("unexpected longest session %+v", longest)
+	}
+
+	rangeReport, err := service.AnalyticsRange(db, from, to, 0.1)
+	if err != nil {
+		t.Fatalf("analytics range: %v", err)
+	}
+	if rangeReport.Exercise.Sessions != 4 || len(rangeReport.Exercise.ByType) != 3 {
+		t.Fatalf("expected exercise breakdown in range report, got %+v", rangeReport.Exercise)
+	}
+}
@@ -0,0 +1,470 @@
+package service
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ExerciseImportFormats lists the supported activity file formats.
+var ExerciseImportFormats = []string{"gpx", "tcx"}
+
+// exerciseImportSports maps GPX track types and TCX sports to exercise types.
+var exerciseImportSports = map[string]string{
+	"run":      "running",
+	"running":  "running",
+	"bike":     "cycling",
+	"biking":   "cycling",
+	"cycling":  "cycling",
+	"ride":     "cycling",
+	"walk":     "walking",
+	"walking":  "walking",
+	"hike":     "hiking",
+	"hiking":   "hiking",
+	"swim":     "swimming",
+	"swimming": "swimming",
+	"rowing":   "rowing",
+}
+
+const earthRadiusM = 6371008.8
+
+type ExerciseImportOptions struct {
+	// Format is gpx or tcx; empty detects it from the file's root element.
+	Format string
+	// FileName is recorded in the metadata summary.
+	FileName string
+	// ExerciseType overrides the sport recorded in the file.
+	ExerciseType string
+	// DistanceUnit is km (default) or mi.
+	DistanceUnit string
+	// Intensity is used for MET estimates of activities without calories.
+	Intensity string
+	DryRun    bool
+}
+
+// ExerciseImportSummary describes an imported activity. It is stored under
+// "activity_import" in the exercise log's metadata JSON.
+type ExerciseImportSummary struct {
+	Source        string  `json:"source"`
+	File          string  `json:"file,omitempty"`
+	Name          string  `json:"name,omitempty"`
+	Sport         string  `json:"sport,omitempty"`
+	StartTime     string  `json:"start_time"`
+	DurationSec   int     `json:"duration_sec"`
+	DistanceM     float64 `json:"distance_m"`
+	AvgHeartRate  *int    `json:"avg_heart_rate,omitempty"`
+	MaxHeartRate  *int    `json:"max_heart_rate,omitempty"`
+	Calories      int     `json:"calories"`
+	CalorieSource string  `json:"calorie_source"`
+	Points        int     `json:"points"`
+}
+
+type ExerciseImportItem struct {
+	StartTime     string   `json:"start_time"`
+	ExerciseType  string   `json:"exercise_type"`
+	DurationMin   int      `json:"duration_min"`
+	Distance      *float64 `json:"distance,omitempty"`
+	DistanceUnit  string   `json:"distance_unit,omitempty"`
+	AvgHeartRate  *int     `json:"avg_heart_rate,omitempty"`
+	Calories      int      `json:"calories"`
+	CalorieSource string   `json:"calorie_source"`
+	Status        string   `json:"status"`
+	ExerciseLogID int64    `json:"exercise_log_id,omitempty"`
+}
+
+type ExerciseImportReport struct {
+	Format     string               `json:"format"`
+	DryRun     bool                 `json:"dry_run"`
+	Activities int                  `json:"activities"`
+	Inserted   int                  `json:"inserted"`
+	Duplicates int                  `json:"duplicates"`
+	Skipped    int                  `json:"skipped"`
+	Items      []ExerciseImportItem `json:"items"`
+	Warnings   []string             `json:"warnings,omitempty"`
+}
+
+// importedActivity is one activity read from a GPX track or TCX activity.
+type importedActivity struct {
+	name        string
+	sport       string
+	start       time.Time
+	durationSec float64
+	distanceM   float64
+	avgHR       int
+	maxHR       int
+	calories    int
+	points      int
+}
+
+type gpxFile struct {
+	Tracks []struct {
+		Name     string `xml:"name"`
+		Type     string `xml:"type"`
+		Segments []struct {
+			Points []struct {
+				Lat       float64 `xml:"lat,attr"`
+				Lon       float64 `xml:"lon,attr"`
+				Time      string  `xml:"time"`
+				HeartRate int     `xml:"extensions>TrackPointExtension>hr"`
+			} `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+type tcxFile struct {
+	Activities []struct {
+		Sport string `xml:"Sport,attr"`
+		ID    string `xml:"Id"`
+		Laps  []struct {
+			StartTime        string  `xml:"StartTime,attr"`
+			TotalTimeSeconds float64 `xml:"TotalTimeSeconds"`
+			DistanceMeters   float64 `xml:"DistanceMeters"`
+			Calories         int     `xml:"Calories"`
+			AverageHeartRate int     `xml:"AverageHeartRateBpm>Value"`
+			MaximumHeartRate int     `xml:"MaximumHeartRateBpm>Value"`
+			Points           []struct {
+				HeartRate int `xml:"HeartRateBpm>Value"`
+			} `xml:"Track>Trackpoint"`
+		} `xml:"Lap"`
+	} `xml:"Activities>Activity"`
+}
+
+// ImportExerciseFile reads a GPX or TCX activity file and adds an exercise log
+// for each activity whose start time is not already logged. Calories come from
+// the TCX laps when present and are otherwise estimated from MET values. With
+// DryRun the report is computed but nothing is written.
+func ImportExerciseFile(db *sql.DB, r io.Reader, opts ExerciseImportOptions) (*ExerciseImportReport, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read exercise import file: %w", err)
+	}
+	format := strings.ToLower(strings.TrimSpace(opts.Format))
+	if format == "" {
+		format = detectExerciseImportFormat(raw)
+	}
+	unit := strings.ToLower(strings.TrimSpace(opts.DistanceUnit))
+	if unit == "" {
+		unit = "km"
+	}
+	if unit != "km" && unit != "mi" {
+		return nil, fmt.Errorf("invalid distance unit %q (use km or mi)", opts.DistanceUnit)
+	}
+
+	var activities []importedActivity
+	switch format {
+	case "gpx":
+		activities, err = parseGPXActivities(raw)
+	case "tcx":
+		activities, err = parseTCXActivities(raw)
+	default:
+		return nil, fmt.Errorf("unsupported exercise import format %q (use %s)", opts.Format, strings.Join(ExerciseImportFormats, "|"))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(activities) == 0 {
+		return nil, fmt.Errorf("%s file contains no timed activities", format)
+	}
+	sort.SliceStable(activities, func(i, j int) bool { return activities[i].start.Before(activities[j].start) })
+
+	report := &ExerciseImportReport{Format: format, DryRun: opts.DryRun, Activities: len(activities), Items: make([]ExerciseImportItem, 0, len(activities))}
+	existing, err := existingExerciseStartTimes(db)
+	if err != nil {
+		return nil, err
+	}
+	type pendingLog struct {
+		item int
+		log  ExerciseLogInput
+	}
+	pending := make([]pendingLog, 0, len(activities))
+	for _, a := range activities {
+		key := a.start.Unix()
+		if existing[key] {
+			report.Duplicates++
+			report.Items = append(report.Items, ExerciseImportItem{
+				StartTime:    a.start.In(time.Local).Format(time.RFC3339),
+				ExerciseType: importedExerciseType(a, opts.ExerciseType),
+				DurationMin:  max(int(math.Round(a.durationSec/60)), 1),
+				Status:       "duplicate",
+			})
+			continue
+		}
+		log, item, err := buildImportedExerciseLog(db, a, format, unit, opts)
+		if err != nil {
+			report.Skipped++
+			report.Warnings = append(report.Warnings, fmt.Sprintf("activity at %s: %v", a.start.Local().Format("2006-01-02 15:04"), err))
+			continue
+		}
+		existing[key] = true
+		report.Inserted++
+		item.Status = "inserted"
+		report.Items = append(report.Items, item)
+		pending = append(pending, pendingLog{item: len(report.Items) - 1, log: log})
+	}
+	if opts.DryRun {
+		return report, nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin exercise import tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	for _, p := range pending {
+		normalized, err := normalizeExerciseInput(p.log, true)
+		if err != nil {
+			return nil, err
+		}
+		id, err := insertExerciseLog(tx, normalized)
+		if err != nil {
+			return nil, err
+		}
+		report.Items[p.item].ExerciseLogID = id
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit exercise import: %w", err)
+	}
+	return report, nil
+}
+
+func detectExerciseImportFormat(raw []byte) string {
+	dec := xml.NewDecoder(bytes.NewReader(raw))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return ""
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			switch start.Name.Local {
+			case "gpx":
+				return "gpx"
+			case "TrainingCenterDatabase":
+				return "tcx"
+			}
+			return ""
+		}
+	}
+}
+
+// buildImportedExerciseLog converts an activity to an exercise log input with
+// its summary in the metadata JSON.
+func buildImportedExerciseLog(db *sql.DB, a importedActivity, format, unit string, opts ExerciseImportOptions) (ExerciseLogInput, ExerciseImportItem, error) {
+	exerciseType := importedExerciseType(a, opts.ExerciseType)
+	if exerciseType == "" {
+		return ExerciseLogInput{}, ExerciseImportItem{}, fmt.Errorf("no sport recorded; pass --type")
+	}
+	durationMin := max(int(math.Round(a.durationSec/60)), 1)
+
+	summary := ExerciseImportSummary{
+		Source:      format,
+		File:        strings.TrimSpace(opts.FileName),
+		Name:        strings.TrimSpace(a.name),
+		Sport:       strings.TrimSpace(a.sport),
+		StartTime:   a.start.UTC().Format(time.RFC3339),
+		DurationSec: int(math.Round(a.durationSec)),
+		DistanceM:   roundTo(a.distanceM, 1),
+		Points:      a.points,
+	}
+	if a.avgHR > 0 {
+		v := a.avgHR
+		summary.AvgHeartRate = &v
+	}
+	if a.maxHR > 0 {
+		v := a.maxHR
+		summary.MaxHeartRate = &v
+	}
+	log := ExerciseLogInput{
+		ExerciseType: exerciseType,
+		DurationMin:  &durationMin,
+		PerformedAt:  a.start.In(time.Local),
+	}
+	if a.distanceM > 0 {
+		d := a.distanceM / 1000
+		if unit == "mi" {
+			d /= kmPerMile
+		}
+		d = roundTo(d, 2)
+		log.Distance, log.DistanceUnit = &d, unit
+	}
+
+	var estimate *ExerciseEstimate
+	if a.calories > 0 {
+		log.CaloriesBurned = a.calories
+		summary.CalorieSource = format
+	} else {
+		var err error
+		log, estimate, err = estimateExerciseCalories(db, log, opts.Intensity, 0)
+		if err != nil {
+			return ExerciseLogInput{}, ExerciseImportItem{}, err
+		}
+		summary.CalorieSource = "estimate"
+	}
+	summary.Calories = log.CaloriesBurned
+	metadata, err := json.Marshal(map[string]any{"activity_import": summary})
+	if err != nil {
+		return ExerciseLogInput{}, ExerciseImportItem{}, fmt.Errorf("marshal activity import metadata: %w", err)
+	}
+	log.Metadata = string(metadata)
+	if estimate != nil {
+		if log.Metadata, err = withExerciseEstimate(log.Metadata, estimate); err != nil {
+			return ExerciseLogInput{}, ExerciseImportItem{}, err
+		}
+	}
+
+	item := ExerciseImportItem{
+		StartTime:     log.PerformedAt.Format(time.RFC3339),
+		ExerciseType:  exerciseType,
+		DurationMin:   durationMin,
+		Distance:      log.Distance,
+		DistanceUnit:  log.DistanceUnit,
+		AvgHeartRate:  summary.AvgHeartRate,
+		Calories:      log.CaloriesBurned,
+		CalorieSource: summary.CalorieSource,
+	}
+	return log, item, nil
+}
+
+// importedExerciseType resolves the exercise type from the override or the
+// activity's sport; an unknown or "other" sport resolves to "".
+func importedExerciseType(a importedActivity, override string) string {
+	if v := strings.ToLower(strings.TrimSpace(override)); v != "" {
+		return v
+	}
+	sport := strings.ToLower(strings.TrimSpace(a.sport))
+	if v, ok := exerciseImportSports[sport]; ok {
+		return v
+	}
+	if sport == "other" {
+		return ""
+	}
+	return sport
+}
+
+func parseGPXActivities(raw []byte) ([]importedActivity, error) {
+	var doc gpxFile
+	if err := xml.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("parse gpx: %w", err)
+	}
+	out := make([]importedActivity, 0, len(doc.Tracks))
+	for _, trk := range doc.Tracks {
+		a := importedActivity{name: trk.Name, sport: trk.Type}
+		var first, last time.Time
+		hrSum, hrCount := 0, 0
+		for _, seg := range trk.Segments {
+			for i, pt := range seg.Points {
+				a.points++
+				if i > 0 {
+					prev := seg.Points[i-1]
+					a.distanceM += haversineMeters(prev.Lat, prev.Lon, pt.Lat, pt.Lon)
+				}
+				if t, err := time.Parse(time.RFC3339, strings.TrimSpace(pt.Time)); err == nil {
+					if first.IsZero() || t.Before(first) {
+						first = t
+					}
+					if t.After(last) {
+						last = t
+					}
+				}
+				if pt.HeartRate > 0 {
+					hrSum += pt.HeartRate
+					hrCount++
+					a.maxHR = max(a.maxHR, pt.HeartRate)
+				}
+			}
+		}
+		if first.IsZero() || !last.After(first) {
+			continue
+		}
+		a.start = first
+		a.durationSec = last.Sub(first).Seconds()
+		if hrCount > 0 {
+			a.avgHR = int(math.Round(float64(hrSum) / float64(hrCount)))
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+func parseTCXActivities(raw []byte) ([]importedActivity, error) {
+	var doc tcxFile
+	if err := xml.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("parse tcx: %w", err)
+	}
+	out := make([]importedActivity, 0, len(doc.Activities))
+	for _, act := range doc.Activities {
+		a := importedActivity{sport: act.Sport}
+		start, err := time.Parse(time.RFC3339, strings.TrimSpace(act.ID))
+		weightedHR, hrSeconds := 0.0, 0.0
+		pointHRSum, pointHRCount := 0, 0
+		for _, lap := range act.Laps {
+			if err != nil {
+				start, err = time.Parse(time.RFC3339, strings.TrimSpace(lap.StartTime))
+			}
+			a.durationSec += lap.TotalTimeSeconds
+			a.distanceM += lap.DistanceMeters
+			a.calories += lap.Calories
+			a.points += len(lap.Points)
+			if lap.AverageHeartRate > 0 && lap.TotalTimeSeconds > 0 {
+				weightedHR += float64(lap.AverageHeartRate) * lap.TotalTimeSeconds
+				hrSeconds += lap.TotalTimeSeconds
+			}
+			a.maxHR = max(a.maxHR, lap.MaximumHeartRate)
+			for _, pt := range lap.Points {
+				if pt.HeartRate > 0 {
+					pointHRSum += pt.HeartRate
+					pointHRCount++
+					a.maxHR = max(a.maxHR, pt.HeartRate)
+				}
+			}
+		}
+		if err != nil || a.durationSec <= 0 {
+			continue
+		}
+		a.start = start
+		switch {
+		case hrSeconds > 0:
+			a.avgHR = int(math.Round(weightedHR / hrSeconds))
+		case pointHRCount > 0:
+			a.avgHR = int(math.Round(float64(pointHRSum) / float64(pointHRCount)))
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+func haversineMeters(lat1, lon1, lat2, lon2 float64) float64 {
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusM * math.Asin(math.Sqrt(h))
+}
+
+func existingExerciseStartTimes(db *sql.DB) (map[int64]bool, error) {
+	rows, err := db.Query(`SELECT performed_at FROM exercise_logs`)
+	if err != nil {
+		return nil, fmt.Errorf("load exercise start times: %w", err)
+	}
+	defer rows.Close()
+	out := map[int64]bool{}
+	for rows.Next() {
+		var raw string
+		if err := rows.Scan(&raw); err != nil {
+			return nil, fmt.Errorf("scan exercise start time: %w", err)
+		}
+		if t, err := time.Parse(time.RFC3339, raw); err == nil {
+			out[t.Unix()] = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate exercise start times: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,147 @@
+package service_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/service"
+)
+
+const testGPX = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1" xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v1">
+  <trk>
+    <name>Morning Run</name>
+    <type>running</type>
+    <trkseg>
+      <trkpt lat="40.0000" lon="-74.0000"><time>2026-05-01T10:00:00Z</time><extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>140</gpxtpx:hr></gpxtpx:TrackPointExtension></extensions></trkpt>
+      <trkpt lat="40.0225" lon="-74.0000"><time>2026-05-01T10:15:00Z</time><extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>150</gpxtpx:hr></gpxtpx:TrackPointExtension></extensions></trkpt>
+      <trkpt lat="40.0450" lon="-74.0000"><time>2026-05-01T10:30:00Z</time><extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>160</gpxtpx:hr></gpxtpx:TrackPointExtension></extensions></trkpt>
+    </trkseg>
+  </trk>
+</gpx>`
+
+const testTCX = `<?xml version="1.0" encoding="UTF-8"?>
+<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2">
+  <Activities>
+    <Activity Sport="Biking">
+      <Id>2026-05-02T08:00:00Z</Id>
+      <Lap StartTime="2026-05-02T08:00:00Z">
+        <TotalTimeSeconds>1800</TotalTimeSeconds><DistanceMeters>12000</DistanceMeters><Calories>300</Calories>
+        <AverageHeartRateBpm><Value>130</Value></AverageHeartRateBpm><MaximumHeartRateBpm><Value>150</Value></MaximumHeartRateBpm>
+      </Lap>
+      <Lap StartTime="2026-05-02T08:30:00Z">
+        <TotalTimeSeconds>1800</TotalTimeSeconds><DistanceMeters>13000</DistanceMeters><Calories>350</Calories>
+        <AverageHeartRateBpm><Value>140</Value></AverageHeartRateBpm><MaximumHeartRateBpm><Value>165</Value></MaximumHeartRateBpm>
+      </Lap>
+    </Activity>
+    <Activity Sport="Other">
+      <Id>2026-05-03T08:00:00Z</Id>
+      <Lap StartTime="2026-05-03T08:00:00Z"><TotalTimeSeconds>600</TotalTimeSeconds></Lap>
+    </Activity>
+  </Activities>
+</TrainingCenterDatabase>`
+
+func TestImportExerciseFileGPXEstimatesAndDedupes(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	opts := service.ExerciseImportOptions{FileName: "run.gpx"}
+	report, err := service.ImportExerciseFile(db, strings.NewReader(testGPX), opts)
+	if err != nil {
+		t.Fatalf("import without body weight: %v", err)
+	}
+	if report.Format != "gpx" || report.Skipped != 1 || report.Inserted != 0 || len(report.Warnings) != 1 {
+		t.Fatalf("expected activity skipped without a body weight, got %+v", report)
+	}
+
+	if _, err := service.AddBodyMeasurement(db, service.BodyMeasurementInput{Weight: 70, Unit: "kg", MeasuredAt: time.Date(2026, 4, 30, 7, 0, 0, 0, time.UTC)}); err != nil {
+		t.Fatalf("add measurement: %v", err)
+	}
+	report, err = service.ImportExerciseFile(db, strings.NewReader(testGPX), opts)
+	if err != nil {
+		t.Fatalf("import gpx: %v", err)
+	}
+	if report.Inserted != 1 || len(report.Items) != 1 {
+		t.Fatalf("expected one inserted activity, got %+v", report)
+	}
+	item := report.Items[0]
+	if item.ExerciseType != "running" || item.DurationMin != 30 || item.Distance == nil || *item.Distance != 5 || item.DistanceUnit != "km" {
+		t.Fatalf("unexpected imported item %+v", item)
+	}
+	// 10 km/h interpolates to MET 10.0: 10 x 70 kg x 0.5 h.
+	if item.CalorieSource != "estimate" || item.Calories != 350 || item.AvgHeartRate == nil || *item.AvgHeartRate != 150 {
+		t.Fatalf("expected pace-based estimate and average heart rate, got %+v", item)
+	}
+
+	logs, err := service.ListExerciseLogs(db, service.ListExerciseFilter{})
+	if err != nil {
+		t.Fatalf("list exercise logs: %v", err)
+	}
+	if len(logs) != 1 || !logs[0].PerformedAt.Equal(time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected one log at the track start, got %+v", logs)
+	}
+	var metadata struct {
+		Import   service.ExerciseImportSummary `json:"activity_import"`
+		Estimate *service.ExerciseEstimate     `json:"calorie_estimate"`
+	}
+	if err := json.Unmarshal([]byte(logs[0].Metadata), &metadata); err != nil {
+		t.Fatalf("decode metadata: %v", err)
+	}
+	if metadata.Import.Source != "gpx" || metadata.Import.File != "run.gpx" || metadata.Import.DurationSec != 1800 || metadata.Import.Points != 3 || metadata.Import.MaxHeartRate == nil || *metadata.Import.MaxHeartRate != 160 {
+		t.Fatalf("unexpected import summary %+v", metadata.Import)
+	}
+	if metadata.Estimate == nil || metadata.Estimate.Method != service.ExerciseEstimateMethodPace {
+		t.Fatalf("expected pace estimate in metadata, got %+v", metadata.Estimate)
+	}
+
+	report, err = service.ImportExerciseFile(db, strings.NewReader(testGPX), opts)
+	if err != nil {
+		t.Fatalf("re-import gpx: %v", err)
+	}
+	if report.Inserted != 0 || report.Duplicates != 1 || report.Items[0].Status != "duplicate" {
+		t.Fatalf("expected the same start time to dedupe, got %+v", report)
+	}
+}
+
+func TestImportExerciseFileTCXCaloriesAndUnits(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	report, err := service.ImportExerciseFile(db, strings.NewReader(testTCX), service.ExerciseImportOptions{DistanceUnit: "mi", DryRun: true})
+	if err != nil {
+		t.Fatalf("dry-run tcx import: %v", err)
+	}
+	if report.Format != "tcx" || report.Activities != 2 || report.Inserted != 1 || report.Skipped != 1 {
+		t.Fatalf("unexpected dry-run report %+v", report)
+	}
+	if logs, _ := service.ListExerciseLogs(db, service.ListExerciseFilter{}); len(logs) != 0 {
+		t.Fatalf("expected dry run to write nothing, got %d logs", len(logs))
+	}
+
+	report, err = service.ImportExerciseFile(db, strings.NewReader(testTCX), service.ExerciseImportOptions{DistanceUnit: "mi"})
+	if err != nil {
+		t.Fatalf("import tcx: %v", err)
+	}
+	item := report.Items[0]
+	if item.ExerciseType != "cycling" || item.DurationMin != 60 || item.Calories != 650 || item.CalorieSource != "tcx" {
+		t.Fatalf("expected lap totals with TCX calories, got %+v", item)
+	}
+	if item.Distance == nil || *item.Distance != 15.53 || item.DistanceUnit != "mi" || item.AvgHeartRate == nil || *item.AvgHeartRate != 135 {
+		t.Fatalf("expected 25 km in miles and time-weighted heart rate, got %+v", item)
+	}
+	log, err := service.GetExerciseLog(db, item.ExerciseLogID)
+	if err != nil {
+		t.Fatalf("get imported log: %v", err)
+	}
+	if log.CaloriesBurned != 650 || log.DistanceUnit != "mi" || strings.Contains(log.Metadata, "calorie_estimate") {
+		t.Fatalf("unexpected imported log %+v", log)
+	}
+
+	if _, err := service.ImportExerciseFile(db, strings.NewReader("<kml></kml>"), service.ExerciseImportOptions{}); err == nil {
+		t.Fatalf("expected unsupported format error")
+	}
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	ExerciseEstimateMethodMET  = "met"
+	ExerciseEstimateMethodPace = "met_pace"
+
+	IntensityLight    = "light"
+	IntensityModerate = "moderate"
+	IntensityVigorous = "vigorous"
+
+	// kmPerMile converts distances logged in miles.
+	kmPerMile = 1.609344
+)
+
+var ExerciseIntensities = []string{IntensityLight, IntensityModerate, IntensityVigorous}
+
+// exerciseMETs holds MET values by activity type and intensity, taken from the
+// Compendium of Physical Activities.
+var exerciseMETs = map[string]map[string]float64{
+	"walking":    {IntensityLight: 2.8, IntensityModerate: 3.5, IntensityVigorous: 5.0},
+	"hiking":     {IntensityLight: 5.3, IntensityModerate: 6.0, IntensityVigorous: 7.8},
+	"running":    {IntensityLight: 7.0, IntensityModerate: 9.8, IntensityVigorous: 11.5},
+	"cycling":    {IntensityLight: 4.0, IntensityModerate: 6.8, IntensityVigorous: 10.0},
+	"swimming":   {IntensityLight: 5.8, IntensityModerate: 8.3, IntensityVigorous: 10.0},
+	"rowing":     {IntensityLight: 4.8, IntensityModerate: 7.0, IntensityVigorous: 8.5},
+	"elliptical": {IntensityLight: 4.6, IntensityModerate: 5.0, IntensityVigorous: 7.3},
+	"strength":   {IntensityLight: 3.5, IntensityModerate: 5.0, IntensityVigorous: 6.0},
+	"hiit":       {IntensityLight: 4.8, IntensityModerate: 8.0, IntensityVigorous: 11.0},
+	"yoga":       {IntensityLight: 2.3, IntensityModerate: 3.0, IntensityVigorous: 4.0},
+	"dancing":    {IntensityLight: 3.0, IntensityModerate: 5.0, IntensityVigorous: 7.3},
+	"stairs":     {IntensityLight: 4.0, IntensityModerate: 8.8, IntensityVigorous: 12.3},
+}
+
+// paceMET maps a speed in km/h to a MET value; speeds between two points are
+// interpolated and speeds outside the table are clamped.
+type paceMET struct {
+	SpeedKmh float64
+	MET      float64
+}
+
+var exercisePaceMETs = map[string][]paceMET{
+	"running": {
+		{6.4, 6.0}, {8.0, 8.3}, {9.7, 9.8}, {10.8, 10.5}, {11.3, 11.0}, {12.1, 11.8},
+		{13.8, 12.3}, {14.5, 12.8}, {16.1, 14.5}, {17.7, 16.0}, {19.3, 19.0}, {22.5, 23.0},
+	},
+	"cycling": {
+		{8.9, 3.5}, {16.1, 5.8}, {19.3, 6.8}, {22.5, 8.0}, {25.7, 10.0}, {30.6, 12.0}, {32.2, 15.8},
+	},
+}
+
+// paceIntensityKmh are the speeds at which pace-refined sessions become
+// moderate and vigorous.
+var paceIntensityKmh = map[string][2]float64{
+	"running": {8.0, 11.3},
+	"cycling": {16.1, 22.5},
+}
+
+// ExerciseEstimate records how an exercise log's calories were estimated. It is
+// stored under "calorie_estimate" in the log's metadata JSON.
+type ExerciseEstimate struct {
+	Method          string   `json:"method"`
+	ExerciseType    string   `json:"exercise_type"`
+	Intensity       string   `json:"intensity"`
+	IntensitySource string   `json:"intensity_source"`
+	MET             float64  `json:"met"`
+	DurationMin     int      `json:"duration_min"`
+	SpeedKmh        *float64 `json:"speed_kmh,omitempty"`
+	WeightKg        float64  `json:"weight_kg"`
+	WeightDate      string   `json:"weight_date"`
+	Calories        int      `json:"calories"`
+}
+
+// ExerciseMETTypes lists the activity types with bundled MET values.
+func ExerciseMETTypes() []string {
+	types := make([]string, 0, len(exerciseMETs))
+	for t := range exerciseMETs {
+		types = append(types, t)
+	}
+	slices.Sort(types)
+	return types
+}
+
+// ExerciseMET resolves the MET value for an activity. When speedKmh is set and
+// the type has a pace table, the pace decides both MET and intensity; otherwise
+// intensity (default moderate) selects from the type's table.
+func ExerciseMET(exerciseType, intensity string, speedKmh *float64) (met float64, resolved, source string, err error) {
+	exerciseType = strings.ToLower(strings.TrimSpace(exerciseType))
+	byIntensity, ok := exerciseMETs[exerciseType]
+	if !ok {
+		return 0, "", "", fmt.Errorf("no MET value for exercise type %q (supported: %s); pass --calories", exerciseType, strings.Join(ExerciseMETTypes(), ", "))
+	}
+	intensity = strings.ToLower(strings.TrimSpace(intensity))
+	if intensity != "" && !slices.Contains(ExerciseIntensities, intensity) {
+		return 0, "", "", fmt.Errorf("invalid intensity %q (use light, moderate or vigorous)", intensity)
+	}
+	if table, ok := exercisePaceMETs[exerciseType]; ok && speedKmh != nil {
+		bounds := paceIntensityKmh[exerciseType]
+		resolved = IntensityLight
+		if *speedKmh >= bounds[1] {
+			resolved = IntensityVigorous
+		} else if *speedKmh >= bounds[0] {
+			resolved = IntensityModerate
+		}
+		return interpolatePaceMET(table, *speedKmh), resolved, "pace", nil
+	}
+	if intensity == "" {
+		return byIntensity[IntensityModerate], IntensityModerate, "default", nil
+	}
+	return byIntensity[intensity], intensity, "flag", nil
+}
+
+func interpolatePaceMET(table []paceMET, speed float64) float64 {
+	if speed <= table[0].SpeedKmh {
+		return table[0].MET
+	}
+	for i := 1; i < len(table); i++ {
+		lo, hi := table[i-1], table[i]
+		if speed <= hi.SpeedKmh {
+			return roundTo(lo.MET+(hi.MET-lo.MET)*(speed-lo.SpeedKmh)/(hi.SpeedKmh-lo.SpeedKmh), 1)
+		}
+	}
+	return table[len(table)-1].MET
+}
+
+// EstimateExerciseCalories fills in.CaloriesBurned as MET x kg x hours, using
+// the latest body weight on or before the session, and records the estimate in
+// the metadata JSON. in.DurationMin is required.
+func EstimateExerciseCalories(db *sql.DB, in ExerciseLogInput, intensity string) (ExerciseLogInput, *ExerciseEstimate, error) {
+	return estimateExerciseCalories(db, in, intensity, 0)
+}
+
+// estimateExerciseCalories uses met instead of the bundled table when it is
+// positive.
+func estimateExerciseCalories(db *sql.DB, in ExerciseLogInput, intensity string, met float64) (ExerciseLogInput, *ExerciseEstimate, error) {
+	if in.DurationMin == nil || *in.DurationMin <= 0 {
+		return in, nil, fmt.Errorf("duration is required to estimate calories")
+	}
+	performedAt := in.PerformedAt
+	if performedAt.IsZero() {
+		performedAt = time.Now()
+	}
+	latest, err := LatestBodyMeasurement(db, performedAt.Format("2006-01-02"))
+	if err != nil {
+		return in, nil, err
+	}
+	if latest == nil {
+		return in, nil, fmt.Errorf("no body weight recorded on or before %s; add one with kcal body add or pass --calories", performedAt.Format("2006-01-02"))
+	}
+
+	var speedKmh *float64
+	if in.Distance != nil && *in.Distance > 0 {
+		km := *in.Distance
+		switch strings.ToLower(strings.TrimSpace(in.DistanceUnit)) {
+		case "km":
+		case "mi":
+			km *= kmPerMile
+		default:
+			return in, nil, fmt.Errorf("invalid distance unit %q (use km or mi)", in.DistanceUnit)
+		}
+		v := roundTo(km/(float64(*in.DurationMin)/60), 2)
+		speedKmh = &v
+	}
+	resolved, source := strings.ToLower(strings.TrimSpace(intensity)), "template"
+	if met <= 0 {
+		var err error
+		met, resolved, source, err = ExerciseMET(in.ExerciseType, intensity, speedKmh)
+		if err != nil {
+			return in, nil, err
+		}
+	}
+	method := ExerciseEstimateMethodMET
+	if source == "pace" {
+		method = ExerciseEstimateMethodPace
+	} else {
+		speedKmh = nil
+	}
+
+	estimate := &ExerciseEstimate{
+		Method:          method,
+		ExerciseType:    strings.ToLower(strings.TrimSpace(in.ExerciseType)),
+		Intensity:       resolved,
+		IntensitySource: source,
+		MET:             met,
+		DurationMin:     *in.DurationMin,
+		SpeedKmh:        speedKmh,
+		WeightKg:        roundTo(latest.WeightKg, 2),
+		WeightDate:      latest.MeasuredAt.Local().Format("2006-01-02"),
+		Calories:        int(met*latest.WeightKg*float64(*in.DurationMin)/60 + 0.5),
+	}
+	metadata, err := withExerciseEstimate(in.Metadata, estimate)
+	if err != nil {
+		return in, nil, err
+	}
+	in.CaloriesBurned = estimate.Calories
+	in.Metadata = metadata
+	return in, estimate, nil
+}
+
+func withExerciseEstimate(metadata string, estimate *ExerciseEstimate) (string, error) {
+	normalized, err := normalizeEntryMetadata(metadata)
+	if err != nil {
+		return "", err
+	}
+	decoded := map[string]any{}
+	if normalized != "" {
+		if err := json.Unmarshal([]byte(normalized), &decoded); err != nil {
+			return "", fmt.Errorf("decode exercise metadata: %w", err)
+		}
+	}
+	decoded["calorie_estimate"] = estimate
+	out, err := json.Marshal(decoded)
+	if err != nil {
+		return "", fmt.Errorf("marshal exercise metadata: %w", err)
+	}
+	return string(out), nil
+}
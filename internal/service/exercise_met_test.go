@@ -0,0 +1,74 @@
+package service_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/service"
+)
+
+func TestEstimateExerciseCaloriesFromMET(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	day := time.Date(2026, 5, 4, 18, 0, 0, 0, time.Local)
+	duration := 30
+	in := service.ExerciseLogInput{ExerciseType: "Running", DurationMin: &duration, PerformedAt: day}
+	if _, _, err := service.EstimateExerciseCalories(db, in, "moderate"); err == nil || !strings.Contains(err.Error(), "no body weight") {
+		t.Fatalf("expected missing weight error, got %v", err)
+	}
+	if _, err := service.AddBodyMeasurement(db, service.BodyMeasurementInput{Weight: 80, Unit: "kg", MeasuredAt: day.AddDate(0, 0, -2)}); err != nil {
+		t.Fatalf("add measurement: %v", err)
+	}
+
+	// 9.8 MET x 80 kg x 0.5 h.
+	got, estimate, err := service.EstimateExerciseCalories(db, in, "moderate")
+	if err != nil {
+		t.Fatalf("estimate: %v", err)
+	}
+	if got.CaloriesBurned != 392 || estimate.Method != service.ExerciseEstimateMethodMET || estimate.IntensitySource != "flag" {
+		t.Fatalf("expected 392 kcal from the moderate MET, got %d %+v", got.CaloriesBurned, estimate)
+	}
+	if _, err := service.CreateExerciseLog(db, got); err != nil {
+		t.Fatalf("create estimated log: %v", err)
+	}
+	logs, err := service.ListExerciseLogs(db, service.ListExerciseFilter{})
+	if err != nil {
+		t.Fatalf("list logs: %v", err)
+	}
+	var metadata struct {
+		Estimate service.ExerciseEstimate `json:"calorie_estimate"`
+	}
+	if err := json.Unmarshal([]byte(logs[0].Metadata), &metadata); err != nil {
+		t.Fatalf("decode metadata %q: %v", logs[0].Metadata, err)
+	}
+	if metadata.Estimate.MET != 9.8 || metadata.Estimate.WeightKg != 80 || metadata.Estimate.WeightDate != "2026-05-02" {
+		t.Fatalf("expected estimate recorded in metadata, got %+v", metadata.Estimate)
+	}
+
+	// 6 km in 30 min is 12 km/h: pace overrides the light intensity flag.
+	distance := 6.0
+	in.Distance, in.DistanceUnit, in.Metadata = &distance, "km", `{"shoes":"trail"}`
+	got, estimate, err = service.EstimateExerciseCalories(db, in, "light")
+	if err != nil {
+		t.Fatalf("estimate with pace: %v", err)
+	}
+	if estimate.Method != service.ExerciseEstimateMethodPace || estimate.Intensity != "vigorous" || estimate.MET != 11.7 || got.CaloriesBurned != 468 {
+		t.Fatalf("expected pace-refined vigorous estimate of 468 kcal, got %d %+v", got.CaloriesBurned, estimate)
+	}
+	if !strings.Contains(got.Metadata, `"shoes":"trail"`) || !strings.Contains(got.Metadata, `"method":"met_pace"`) {
+		t.Fatalf("expected estimate merged into existing metadata, got %s", got.Metadata)
+	}
+
+	in.ExerciseType = "curling"
+	if _, _, err := service.EstimateExerciseCalories(db, in, ""); err == nil {
+		t.Fatalf("expected unknown exercise type error")
+	}
+	in.ExerciseType = "walking"
+	if _, _, err := service.EstimateExerciseCalories(db, in, "extreme"); err == nil {
+		t.Fatalf("expected invalid intensity error")
+	}
+}
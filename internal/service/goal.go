@@ -10,47 +10,102 @@ import (
 )
 
 type SetGoalInput struct {
-	Calories      int
-	ProteinG      float64
-	CarbsG        float64
-	FatG          float64
-	EffectiveDate string
+	Calories        int
+	ProteinG        float64
+	CarbsG          float64
+	FatG            float64
+	NutrientTargets []NutrientTarget
+	// MacroRules replaces the fixed grams for any macro with a rule; the stored
+	// grams are resolved as of EffectiveDate.
+	MacroRules MacroRules
+	// InheritTargets carries the previous goal's nutrient targets forward
+	// when NutrientTargets is empty.
+	InheritTargets bool
+	EffectiveDate  string
 }
 
 func SetGoal(db *sql.DB, in SetGoalInput) error {
-	if err := validateNonNegativeInt("calories", in.Calories); err != nil {
+	row, err := prepareGoal(db, in)
+	if err != nil {
 		return err
 	}
+	_, err = db.Exec(`
+INSERT INTO goals(calories, protein_g, carbs_g, fat_g, nutrient_targets_json, macro_rules_json, effective_date)
+VALUES(?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(effective_date) DO UPDATE SET
+  calories=excluded.calories,
+  protein_g=excluded.protein_g,
+  carbs_g=excluded.carbs_g,
+  fat_g=excluded.fat_g,
+  nutrient_targets_json=excluded.nutrient_targets_json,
+  macro_rules_json=excluded.macro_rules_json
+`, row.Calories, row.ProteinG, row.CarbsG, row.FatG, row.NutrientTargets, row.MacroRules, row.EffectiveDate)
+	if err != nil {
+		return fmt.Errorf("set goal: %w", err)
+	}
+	return nil
+}
+
+// prepareGoal validates in and resolves inherited targets and macro rules into
+// the row to store. It only reads from db, so callers can write the row inside
+// their own transaction.
+func prepareGoal(db *sql.DB, in SetGoalInput) (model.Goal, error) {
+	if err := validateNonNegativeInt("calories", in.Calories); err != nil {
+		return model.Goal{}, err
+	}
 	if err := validateNonNegativeFloat("protein", in.ProteinG); err != nil {
-		return err
+		return model.Goal{}, err
 	}
 	if err := validateNonNegativeFloat("carbs", in.CarbsG); err != nil {
-		return err
+		return model.Goal{}, err
 	}
 	if err := validateNonNegativeFloat("fat", in.FatG); err != nil {
-		return err
+		return model.Goal{}, err
 	}
 	in.EffectiveDate = strings.TrimSpace(in.EffectiveDate)
 	if in.EffectiveDate == "" {
 		in.EffectiveDate = time.Now().Format("2006-01-02")
 	}
 	if _, err := time.Parse("2006-01-02", in.EffectiveDate); err != nil {
-		return fmt.Errorf("invalid effective date %q (expected YYYY-MM-DD)", in.EffectiveDate)
+		return model.Goal{}, fmt.Errorf("invalid effective date %q (expected YYYY-MM-DD)", in.EffectiveDate)
 	}
-
-	_, err := db.Exec(`
-INSERT INTO goals(calories, protein_g, carbs_g, fat_g, effective_date)
-VALUES(?, ?, ?, ?, ?)
-ON CONFLICT(effective_date) DO UPDATE SET
-  calories=excluded.calories,
-  protein_g=excluded.protein_g,
-  carbs_g=excluded.carbs_g,
-  fat_g=excluded.fat_g
-`, in.Calories, in.ProteinG, in.CarbsG, in.FatG, in.EffectiveDate)
+	targets := in.NutrientTargets
+	if len(targets) == 0 && in.InheritTargets {
+		prev, err := CurrentGoal(db, in.EffectiveDate)
+		if err != nil {
+			return model.Goal{}, err
+		}
+		if prev != nil {
+			targets, err = ParseNutrientTargetsJSON(prev.NutrientTargets)
+			if err != nil {
+				return model.Goal{}, err
+			}
+		}
+	}
+	targetsJSON, err := EncodeNutrientTargetsJSON(targets)
 	if err != nil {
-		return fmt.Errorf("set goal: %w", err)
+		return model.Goal{}, err
 	}
-	return nil
+	rulesJSON, err := EncodeMacroRulesJSON(in.MacroRules)
+	if err != nil {
+		return model.Goal{}, err
+	}
+	if rulesJSON != "" {
+		resolved, err := ResolveMacroRules(db, model.Goal{Calories: in.Calories, ProteinG: in.ProteinG, CarbsG: in.CarbsG, FatG: in.FatG, MacroRules: rulesJSON}, in.EffectiveDate)
+		if err != nil {
+			return model.Goal{}, err
+		}
+		in.ProteinG, in.CarbsG, in.FatG = resolved.ProteinG, resolved.CarbsG, resolved.FatG
+	}
+	return model.Goal{
+		Calories:        in.Calories,
+		ProteinG:        in.ProteinG,
+		CarbsG:          in.CarbsG,
+		FatG:            in.FatG,
+		NutrientTargets: targetsJSON,
+		MacroRules:      rulesJSON,
+		EffectiveDate:   in.EffectiveDate,
+	}, nil
 }
 
 func CurrentGoal(db *sql.DB, date string) (*model.Goal, error) {
@@ -64,12 +119,12 @@ func CurrentGoal(db *sql.DB, date string) (*model.Goal, error) {
 
 	var g model.Goal
 	err := db.QueryRow(`
-SELECT id, calories, protein_g, carbs_g, fat_g, effective_date, created_at
+SELECT id, calories, protein_g, carbs_g, fat_g, IFNULL(nutrient_targets_json,''), IFNULL(macro_rules_json,''), effective_date, created_at
 FROM goals
 WHERE effective_date <= ?
 ORDER BY effective_date DESC
 LIMIT 1
-`, date).Scan(&g.ID, &g.Calories, &g.ProteinG, &g.CarbsG, &g.FatG, &g.EffectiveDate, &g.CreatedAt)
+`, date).Scan(&g.ID, &g.Calories, &g.ProteinG, &g.CarbsG, &g.FatG, &g.NutrientTargets, &g.MacroRules, &g.EffectiveDate, &g.CreatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -81,7 +136,7 @@ LIMIT 1
 
 func GoalHistory(db *sql.DB) ([]model.Goal, error) {
 	rows, err := db.Query(`
-SELECT id, calories, protein_g, carbs_g, fat_g, effective_date, created_at
+SELECT id, calories, protein_g, carbs_g, fat_g, IFNULL(nutrient_targets_json,''), IFNULL(macro_rules_json,''), effective_date, created_at
 FROM goals
 ORDER BY effective_date DESC
 `)
@@ -93,7 +148,7 @@ ORDER BY effective_date DESC
 	goals := make([]model.Goal, 0)
 	for rows.Next() {
 		var g model.Goal
-		if err := rows.Scan(&g.ID, &g.Calories, &g.ProteinG, &g.CarbsG, &g.FatG, &g.EffectiveDate, &g.CreatedAt); err != nil {
+		if err := rows.Scan(&g.ID, &g.Calories, &g.ProteinG, &g.CarbsG, &g.FatG, &g.NutrientTargets, &g.MacroRules, &g.EffectiveDate, &g.CreatedAt); err != nil {
 			return nil, fmt.Errorf("scan goal history: %w", err)
 		}
 		goals = append(goals, g)
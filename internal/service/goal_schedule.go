@@ -0,0 +1,313 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/model"
+)
+
+const (
+	DayTypeTraining = "training"
+	DayTypeRest     = "rest"
+)
+
+var goalScheduleWeekdays = []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+
+type GoalScheduleEntry struct {
+	DayKey   string  `json:"day_key"`
+	Calories int     `json:"calories"`
+	ProteinG float64 `json:"protein_g"`
+	CarbsG   float64 `json:"carbs_g"`
+	FatG     float64 `json:"fat_g"`
+}
+
+type SetGoalScheduleInput struct {
+	// Date selects the goal version the override belongs to.
+	Date     string
+	DayKey   string
+	Calories int
+	ProteinG float64
+	CarbsG   float64
+	FatG     float64
+}
+
+// ResolvedGoal is the goal version in effect on a date with any weekday or
+// day-type override applied.
+type ResolvedGoal struct {
+	model.Goal
+	MacroNotes      []string
+	Schedule        string
+	DayType         string
+	DayTypeInferred bool
+}
+
+// NormalizeGoalDayKey accepts weekday names (full or three-letter) and the
+// training/rest day types.
+func NormalizeGoalDayKey(raw string) (string, error) {
+	key := strings.ToLower(strings.TrimSpace(raw))
+	if key == DayTypeTraining || key == DayTypeRest {
+		return key, nil
+	}
+	for _, day := range goalScheduleWeekdays {
+		if key == day || (len(key) == 3 && strings.HasPrefix(day, key)) {
+			return day, nil
+		}
+	}
+	return "", fmt.Errorf("invalid schedule day %q (expected monday..sunday, training, or rest)", raw)
+}
+
+func SetGoalSchedule(db *sql.DB, in SetGoalScheduleInput) error {
+	key, err := NormalizeGoalDayKey(in.DayKey)
+	if err != nil {
+		return err
+	}
+	if err := validateNonNegativeInt("calories", in.Calories); err != nil {
+		return err
+	}
+	if err := validateNonNegativeFloat("protein", in.ProteinG); err != nil {
+		return err
+	}
+	if err := validateNonNegativeFloat("carbs", in.CarbsG); err != nil {
+		return err
+	}
+	if err := validateNonNegativeFloat("fat", in.FatG); err != nil {
+		return err
+	}
+	goal, err := goalVersionAt(db, in.Date)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+INSERT INTO goal_schedules(goal_id, day_key, calories, protein_g, carbs_g, fat_g)
+VALUES(?, ?, ?, ?, ?, ?)
+ON CONFLICT(goal_id, day_key) DO UPDATE SET
+  calories=excluded.calories,
+  protein_g=excluded.protein_g,
+  carbs_g=excluded.carbs_g,
+  fat_g=excluded.fat_g
+`, goal.ID, key, in.Calories, in.ProteinG, in.CarbsG, in.FatG)
+	if err != nil {
+		return fmt.Errorf("set goal schedule %s: %w", key, err)
+	}
+	return nil
+}
+
+func DeleteGoalSchedule(db *sql.DB, date, dayKey string) error {
+	key, err := NormalizeGoalDayKey(dayKey)
+	if err != nil {
+		return err
+	}
+	goal, err := goalVersionAt(db, date)
+	if err != nil {
+		return err
+	}
+	res, err := db.Exec(`DELETE FROM goal_schedules WHERE goal_id = ? AND day_key = ?`, goal.ID, key)
+	if err != nil {
+		return fmt.Errorf("delete goal schedule %s: %w", key, err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected for goal schedule delete: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("no %s schedule on goal effective %s", key, goal.EffectiveDate)
+	}
+	return nil
+}
+
+// GoalSchedule returns the goal version in effect on date and its overrides,
+// weekdays first in calendar order, then day types.
+func GoalSchedule(db *sql.DB, date string) (*model.Goal, []GoalScheduleEntry, error) {
+	goal, err := goalVersionAt(db, date)
+	if err != nil {
+		return nil, nil, err
+	}
+	entries, err := loadGoalSchedule(db, goal.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return goal, entries, nil
+}
+
+// ResolveGoalForDate resolves macro rules and applies the schedule of the goal
+// version in effect on date. A matching day-type override wins over a weekday
+// override.
+func ResolveGoalForDate(db *sql.DB, date string) (*ResolvedGoal, error) {
+	date, err := normalizeGoalDate(date)
+	if err != nil {
+		return nil, err
+	}
+	goal, err := CurrentGoal(db, date)
+	if err != nil || goal == nil {
+		return nil, err
+	}
+	out := &ResolvedGoal{Goal: *goal}
+	if goal.MacroRules != "" {
+		macros, err := ResolveMacroRules(db, *goal, date)
+		if err != nil {
+			return nil, err
+		}
+		out.ProteinG, out.CarbsG, out.FatG = macros.ProteinG, macros.CarbsG, macros.FatG
+		out.MacroNotes = macros.Notes
+	}
+	entries, err := loadGoalSchedule(db, goal.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return out, nil
+	}
+	byKey := map[string]GoalScheduleEntry{}
+	hasDayTypes := false
+	for _, e := range entries {
+		byKey[e.DayKey] = e
+		if e.DayKey == DayTypeTraining || e.DayKey == DayTypeRest {
+			hasDayTypes = true
+		}
+	}
+	if hasDayTypes {
+		out.DayType, out.DayTypeInferred, err = DayTypeFor(db, date)
+		if err != nil {
+			return nil, err
+		}
+		if e, ok := byKey[out.DayType]; ok {
+			out.applySchedule(e)
+			return out, nil
+		}
+	}
+	day, _ := time.Parse("2006-01-02", date)
+	if e, ok := byKey[strings.ToLower(day.Weekday().String())]; ok {
+		out.applySchedule(e)
+	}
+	return out, nil
+}
+
+// SetDayType marks date as a training or rest day, overriding inference.
+func SetDayType(db *sql.DB, date, dayType string) error {
+	date, err := normalizeGoalDate(date)
+	if err != nil {
+		return err
+	}
+	dayType = strings.ToLower(strings.TrimSpace(dayType))
+	if dayType != DayTypeTraining && dayType != DayTypeRest {
+		return fmt.Errorf("invalid day type %q (expected training or rest)", dayType)
+	}
+	if _, err := db.Exec(`
+INSERT INTO day_types(date, day_type) VALUES(?, ?)
+ON CONFLICT(date) DO UPDATE SET day_type=excluded.day_type
+`, date, dayType); err != nil {
+		return fmt.Errorf("set day type for %s: %w", date, err)
+	}
+	return nil
+}
+
+func ClearDayType(db *sql.DB, date string) error {
+	date, err := normalizeGoalDate(date)
+	if err != nil {
+		return err
+	}
+	if _, err := db.Exec(`DELETE FROM day_types WHERE date = ?`, date); err != nil {
+		return fmt.Errorf("clear day type for %s: %w", date, err)
+	}
+	return nil
+}
+
+// DayTypeFor returns the marked day type for date, or infers training when
+// any exercise is logged that day and rest otherwise.
+func DayTypeFor(db *sql.DB, date string) (string, bool, error) {
+	date, err := normalizeGoalDate(date)
+	if err != nil {
+		return "", false, err
+	}
+	var dayType string
+	err = db.QueryRow(`SELECT day_type FROM day_types WHERE date = ?`, date).Scan(&dayType)
+	if err == nil {
+		return dayType, false, nil
+	}
+	if err != sql.ErrNoRows {
+		return "", false, fmt.Errorf("get day type for %s: %w", date, err)
+	}
+	day, _ := time.ParseInLocation("2006-01-02", date, time.Local)
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(1) FROM exercise_logs WHERE performed_at >= ? AND performed_at < ?`,
+		day.Format(time.RFC3339), day.Add(24*time.Hour).Format(time.RFC3339)).Scan(&count); err != nil {
+		return "", false, fmt.Errorf("infer day type for %s: %w", date, err)
+	}
+	if count > 0 {
+		return DayTypeTraining, true, nil
+	}
+	return DayTypeRest, true, nil
+}
+
+func (g *ResolvedGoal) applySchedule(e GoalScheduleEntry) {
+	g.Schedule = e.DayKey
+	g.Calories = e.Calories
+	g.ProteinG = e.ProteinG
+	g.CarbsG = e.CarbsG
+	g.FatG = e.FatG
+}
+
+func goalVersionAt(db *sql.DB, date string) (*model.Goal, error) {
+	goal, err := CurrentGoal(db, date)
+	if err != nil {
+		return nil, err
+	}
+	if goal == nil {
+		if strings.TrimSpace(date) == "" {
+			date = "today"
+		}
+		return nil, fmt.Errorf("no goal configured for %s", date)
+	}
+	return goal, nil
+}
+
+func loadGoalSchedule(db *sql.DB, goalID int64) ([]GoalScheduleEntry, error) {
+	rows, err := db.Query(`SELECT day_key, calories, protein_g, carbs_g, fat_g FROM goal_schedules WHERE goal_id = ?`, goalID)
+	if err != nil {
+		return nil, fmt.Errorf("list goal schedule: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]GoalScheduleEntry, 0)
+	for rows.Next() {
+		var e GoalScheduleEntry
+		if err := rows.Scan(&e.DayKey, &e.Calories, &e.ProteinG, &e.CarbsG, &e.FatG); err != nil {
+			return nil, fmt.Errorf("scan goal schedule: %w", err)
+		}
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate goal schedule: %w", err)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return goalDayKeyOrder(out[i].DayKey) < goalDayKeyOrder(out[j].DayKey)
+	})
+	return out, nil
+}
+
+func goalDayKeyOrder(key string) int {
+	for i, day := range goalScheduleWeekdays {
+		if day == key {
+			return i
+		}
+	}
+	if key == DayTypeTraining {
+		return len(goalScheduleWeekdays)
+	}
+	return len(goalScheduleWeekdays) + 1
+}
+
+func normalizeGoalDate(date string) (string, error) {
+	date = strings.TrimSpace(date)
+	if date == "" {
+		return time.Now().Format("2006-01-02"), nil
+	}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return "", fmt.Errorf("invalid date %q (expected YYYY-MM-DD)", date)
+	}
+	return date, nil
+}
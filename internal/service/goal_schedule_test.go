@@ -0,0 +1,130 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/service"
+)
+
+func TestResolveGoalForDateWeekdayAndDayType(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	if err := service.SetGoal(db, service.SetGoalInput{Calories: 2000, ProteinG: 150, CarbsG: 200, FatG: 70, EffectiveDate: "2026-02-01"}); err != nil {
+		t.Fatalf("set goal: %v", err)
+	}
+	if err := service.SetGoalSchedule(db, service.SetGoalScheduleInput{Date: "2026-02-01", DayKey: "Sat", Calories: 2400, ProteinG: 150, CarbsG: 280, FatG: 70}); err != nil {
+		t.Fatalf("set saturday schedule: %v", err)
+	}
+
+	saturday, err := service.ResolveGoalForDate(db, "2026-02-07")
+	if err != nil {
+		t.Fatalf("resolve saturday: %v", err)
+	}
+	if saturday.Schedule != "saturday" || saturday.Calories != 2400 {
+		t.Fatalf("expected saturday override, got %+v", saturday)
+	}
+	monday, err := service.ResolveGoalForDate(db, "2026-02-09")
+	if err != nil {
+		t.Fatalf("resolve monday: %v", err)
+	}
+	if monday.Schedule != "" || monday.Calories != 2000 {
+		t.Fatalf("expected base goal on monday, got %+v", monday)
+	}
+
+	if err := service.SetGoalSchedule(db, service.SetGoalScheduleInput{Date: "2026-02-01", DayKey: "training", Calories: 2600, ProteinG: 160, CarbsG: 300, FatG: 70}); err != nil {
+		t.Fatalf("set training schedule: %v", err)
+	}
+	if _, err := service.CreateExerciseLog(db, service.ExerciseLogInput{ExerciseType: "run", CaloriesBurned: 400, PerformedAt: time.Date(2026, 2, 9, 7, 0, 0, 0, time.Local)}); err != nil {
+		t.Fatalf("create exercise: %v", err)
+	}
+	monday, err = service.ResolveGoalForDate(db, "2026-02-09")
+	if err != nil {
+		t.Fatalf("resolve training monday: %v", err)
+	}
+	if monday.Schedule != "training" || monday.Calories != 2600 || !monday.DayTypeInferred {
+		t.Fatalf("expected inferred training override, got %+v", monday)
+	}
+	saturday, err = service.ResolveGoalForDate(db, "2026-02-07")
+	if err != nil {
+		t.Fatalf("resolve rest saturday: %v", err)
+	}
+	if saturday.DayType != service.DayTypeRest || saturday.Schedule != "saturday" {
+		t.Fatalf("expected rest day to fall back to weekday override, got %+v", saturday)
+	}
+
+	if err := service.SetDayType(db, "2026-02-09", service.DayTypeRest); err != nil {
+		t.Fatalf("mark rest day: %v", err)
+	}
+	monday, err = service.ResolveGoalForDate(db, "2026-02-09")
+	if err != nil {
+		t.Fatalf("resolve marked monday: %v", err)
+	}
+	if monday.Schedule != "" || monday.DayType != service.DayTypeRest || monday.DayTypeInferred {
+		t.Fatalf("expected manual rest day with base goal, got %+v", monday)
+	}
+
+	day := time.Date(2026, 2, 7, 0, 0, 0, 0, time.Local)
+	if _, err := service.CreateEntry(db, service.CreateEntryInput{Name: "Pasta", Calories: 2300, ProteinG: 150, CarbsG: 280, FatG: 70, Category: "dinner", Consumed: day.Add(19 * time.Hour), SourceType: "manual"}); err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+	report, err := service.AnalyticsRange(db, day, day, 0.10)
+	if err != nil {
+		t.Fatalf("analytics: %v", err)
+	}
+	if report.Adherence.WithinGoalDays != 1 || report.Days[0].GoalSchedule != "saturday" {
+		t.Fatalf("expected saturday schedule to drive adherence, got %+v / %+v", report.Adherence, report.Days)
+	}
+
+	if err := service.DeleteGoalSchedule(db, "2026-02-01", "saturday"); err != nil {
+		t.Fatalf("delete saturday schedule: %v", err)
+	}
+	if err := service.DeleteGoalSchedule(db, "2026-02-01", "saturday"); err == nil {
+		t.Fatalf("expected deleting missing schedule to fail")
+	}
+	if _, err := service.NormalizeGoalDayKey("someday"); err == nil {
+		t.Fatalf("expected invalid day key to fail")
+	}
+}
+
+func TestExportImportGoalSchedulesAndDayTypes(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	if err := service.SetGoal(db, service.SetGoalInput{Calories: 2000, ProteinG: 150, CarbsG: 200, FatG: 70, EffectiveDate: "2026-02-01"}); err != nil {
+		t.Fatalf("set goal: %v", err)
+	}
+	if err := service.SetGoalSchedule(db, service.SetGoalScheduleInput{Date: "2026-02-01", DayKey: "sat", Calories: 2400, ProteinG: 150, CarbsG: 280, FatG: 70}); err != nil {
+		t.Fatalf("set schedule: %v", err)
+	}
+	// No exercise is logged, so only the stored marker can make this a training day.
+	if err := service.SetDayType(db, "2026-02-09", service.DayTypeTraining); err != nil {
+		t.Fatalf("set day type: %v", err)
+	}
+
+	exported, err := service.ExportDataSnapshot(db)
+	if err != nil {
+		t.Fatalf("export snapshot: %v", err)
+	}
+	if len(exported.GoalSchedules) != 1 || len(exported.DayTypes) != 1 {
+		t.Fatalf("expected schedule and day type in export, got %+v / %+v", exported.GoalSchedules, exported.DayTypes)
+	}
+	if _, err := service.ImportDataSnapshotWithOptions(db, exported, service.ImportOptions{Mode: service.ImportModeReplace}); err != nil {
+		t.Fatalf("import snapshot: %v", err)
+	}
+
+	_, schedules, err := service.GoalSchedule(db, "2026-02-01")
+	if err != nil {
+		t.Fatalf("goal schedule: %v", err)
+	}
+	if len(schedules) != 1 || schedules[0].DayKey != "saturday" || schedules[0].Calories != 2400 {
+		t.Fatalf("expected saturday schedule after replace import, got %+v", schedules)
+	}
+	dayType, inferred, err := service.DayTypeFor(db, "2026-02-09")
+	if err != nil || inferred || dayType != service.DayTypeTraining {
+		t.Fatalf("expected marked training day after replace import, got %q inferred=%v err=%v", dayType, inferred, err)
+	}
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	NutrientTargetMin = "min"
+	NutrientTargetMax = "max"
+
+	ConfigAdherenceNutrientTargets = "adherence_nutrient_targets"
+)
+
+var nutrientTargetAmount = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*([a-zµ]*)$`)
+
+// builtinTargetUnits lists the nutrients stored as entry columns rather than
+// in micronutrients_json.
+var builtinTargetUnits = map[string]string{
+	"fiber":  "g",
+	"sugar":  "g",
+	"sodium": "mg",
+}
+
+type NutrientTarget struct {
+	Nutrient string  `json:"nutrient"`
+	Kind     string  `json:"kind"`
+	Amount   float64 `json:"amount"`
+	Unit     string  `json:"unit"`
+}
+
+type NutrientTargetStatus struct {
+	Nutrient  string  `json:"nutrient"`
+	Kind      string  `json:"kind"`
+	Target    float64 `json:"target"`
+	Unit      string  `json:"unit"`
+	Actual    float64 `json:"actual"`
+	Remaining float64 `json:"remaining"`
+	Over      float64 `json:"over"`
+	Met       bool    `json:"met"`
+}
+
+// ParseNutrientTarget parses a NAME=AMOUNT[UNIT] spec such as "fiber=30g" or
+// "vitamin_d=15ug". Fiber, sugar and sodium default to g, g and mg.
+func ParseNutrientTarget(kind, spec string) (NutrientTarget, error) {
+	name, amountRaw, ok := strings.Cut(spec, "=")
+	if !ok {
+		return NutrientTarget{}, fmt.Errorf("invalid nutrient target %q (expected NAME=AMOUNT[UNIT])", spec)
+	}
+	m := nutrientTargetAmount.FindStringSubmatch(strings.ToLower(strings.TrimSpace(amountRaw)))
+	if m == nil {
+		return NutrientTarget{}, fmt.Errorf("invalid nutrient target amount %q", amountRaw)
+	}
+	amount, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return NutrientTarget{}, fmt.Errorf("invalid nutrient target amount %q", amountRaw)
+	}
+	return normalizeNutrientTarget(NutrientTarget{Nutrient: name, Kind: kind, Amount: amount, Unit: m[2]})
+}
+
+func ParseNutrientTargetsJSON(value string) ([]NutrientTarget, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	var targets []NutrientTarget
+	if err := json.Unmarshal([]byte(value), &targets); err != nil {
+		return nil, fmt.Errorf("nutrient targets must be a valid JSON array: %w", err)
+	}
+	return targets, nil
+}
+
+func EncodeNutrientTargetsJSON(targets []NutrientTarget) (string, error) {
+	if len(targets) == 0 {
+		return "", nil
+	}
+	byKey := map[string]NutrientTarget{}
+	for _, t := range targets {
+		normalized, err := normalizeNutrientTarget(t)
+		if err != nil {
+			return "", err
+		}
+		byKey[normalized.Nutrient+":"+normalized.Kind] = normalized
+	}
+	out := make([]NutrientTarget, 0, len(byKey))
+	for _, t := range byKey {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Nutrient != out[j].Nutrient {
+			return out[i].Nutrient < out[j].Nutrient
+		}
+		return out[i].Kind > out[j].Kind
+	})
+	encoded, err := json.Marshal(out)
+	if err != nil {
+		return "", fmt.Errorf("marshal nutrient targets: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// NutrientTargetsAdherenceEnabled reports whether adherence should also require
+// nutrient targets to be met.
+func NutrientTargetsAdherenceEnabled(db *sql.DB) (bool, error) {
+	value, ok, err := GetConfig(db, ConfigAdherenceNutrientTargets)
+	if err != nil || !ok || strings.TrimSpace(value) == "" {
+		return false, err
+	}
+	enabled, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		return false, fmt.Errorf("invalid config %s=%q (expected true|false)", ConfigAdherenceNutrientTargets, value)
+	}
+	return enabled, nil
+}
+
+// EvaluateNutrientTargets compares the entries logged on date (YYYY-MM-DD)
+// against each target.
+func EvaluateNutrientTargets(db *sql.DB, date string, targets []NutrientTarget) ([]NutrientTargetStatus, error) {
+	if len(targets) == 0 {
+		return nil, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q (expected YYYY-MM-DD)", date)
+	}
+	totals, err := loadDayNutrientTotals(db, day)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]NutrientTargetStatus, 0, len(targets))
+	for _, t := range targets {
+		actual := totals.amount(t.Nutrient, t.Unit)
+		s := NutrientTargetStatus{Nutrient: t.Nutrient, Kind: t.Kind, Target: t.Amount, Unit: t.Unit, Actual: actual}
+		if t.Kind == NutrientTargetMax {
+			s.Met = actual <= t.Amount
+			s.Remaining = math.Max(0, t.Amount-actual)
+			s.Over = math.Max(0, actual-t.Amount)
+		} else {
+			s.Met = actual >= t.Amount
+			s.Remaining = math.Max(0, t.Amount-actual)
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func normalizeNutrientTarget(t NutrientTarget) (NutrientTarget, error) {
+	t.Nutrient = normalizeTargetNutrient(t.Nutrient)
+	if t.Nutrient == "" || !micronutrientKeyPattern.MatchString(t.Nutrient) {
+		return t, fmt.Errorf("invalid nutrient target name %q (expected lowercase snake_case)", t.Nutrient)
+	}
+	t.Kind = strings.ToLower(strings.TrimSpace(t.Kind))
+	if t.Kind != NutrientTargetMin && t.Kind != NutrientTargetMax {
+		return t, fmt.Errorf("invalid nutrient target kind %q (expected min|max)", t.Kind)
+	}
+	if err := validateNonNegativeFloat(t.Nutrient+" target", t.Amount); err != nil {
+		return t, err
+	}
+	t.Unit = labelMicroUnit(strings.ToLower(strings.TrimSpace(t.Unit)))
+	if builtin, ok := builtinTargetUnits[t.Nutrient]; ok {
+		if t.Unit == "" {
+			t.Unit = builtin
+		}
+		if !isMassUnit(t.Unit) {
+			return t, fmt.Errorf("%s target unit must be g, mg, or ug", t.Nutrient)
+		}
+	}
+	if t.Unit == "" {
+		return t, fmt.Errorf("%s target unit is required", t.Nutrient)
+	}
+	return t, nil
+}
+
+func normalizeTargetNutrient(raw string) string {
+	key := normalizeMicronutrientKey(raw)
+	switch key {
+	case "fiber", "fiber_g", "fibre", "dietary_fiber":
+		return "fiber"
+	case "sugar", "sugar_g", "sugars", "total_sugars":
+		return "sugar"
+	case "sodium", "sodium_mg":
+		return "sodium"
+	case "added_sugar", "added_sugars":
+		return "added_sugars"
+	default:
+		return key
+	}
+}
+
+type dayNutrientTotals struct {
+	fiberG   float64
+	sugarG   float64
+	sodiumMg float64
+	micros   map[string][]MicronutrientAmount
+}
+
+func (d dayNutrientTotals) amount(nutrient, unit string) float64 {
+	switch nutrient {
+	case "fiber":
+		return convertMass(d.fiberG, "g", unit)
+	case "sugar":
+		return convertMass(d.sugarG, "g", unit)
+	case "sodium":
+		return convertMass(d.sodiumMg, "mg", unit)
+	}
+	total := 0.0
+	for _, m := range d.micros[nutrient] {
+		from := labelMicroUnit(strings.ToLower(m.Unit))
+		switch {
+		case from == unit:
+			total += m.Value
+		case isMassUnit(from) && isMassUnit(unit):
+			total += convertMass(m.Value, from, unit)
+		}
+	}
+	return total
+}
+
+func loadDayNutrientTotals(db *sql.DB, day time.Time) (dayNutrientTotals, error) {
+	start := beginningOfDay(day)
+	rows, err := db.Query(`
+SELECT fiber_g, sugar_g, sodium_mg, IFNULL(micronutrients_json, '')
+FROM entries
+WHERE consumed_at >= ? AND consumed_at < ?
+`, start.Format(time.RFC3339), start.Add(24*time.Hour).Format(time.RFC3339))
+	if err != nil {
+		return dayNutrientTotals{}, fmt.Errorf("query day nutrient totals: %w", err)
+	}
+	defer rows.Close()
+
+	out := dayNutrientTotals{micros: map[string][]MicronutrientAmount{}}
+	for rows.Next() {
+		var fiber, sugar, sodium float64
+		var microsRaw string
+		if err := rows.Scan(&fiber, &sugar, &sodium, &microsRaw); err != nil {
+			return dayNutrientTotals{}, fmt.Errorf("scan day nutrient totals: %w", err)
+		}
+		out.fiberG += fiber
+		out.sugarG += sugar
+		out.sodiumMg += sodium
+		micros, err := ParseMicronutrientsJSON(microsRaw)
+		if err != nil {
+			continue
+		}
+		for k, v := range micros {
+			key := normalizeTargetNutrient(k)
+			out.micros[key] = append(out.micros[key], v)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return dayNutrientTotals{}, fmt.Errorf("iterate day nutrient totals: %w", err)
+	}
+	return out, nil
+}
+
+func isMassUnit(unit string) bool {
+	switch unit {
+	case "g", "mg", "ug":
+		return true
+	default:
+		return false
+	}
+}
+
+func convertMass(value float64, from, to string) float64 {
+	if from == to {
+		return value
+	}
+	mg := massToMg(value, from)
+	switch to {
+	case "g":
+		return mg / 1000
+	case "ug":
+		return mg * 1000
+	default:
+		return mg
+	}
+}
@@ -0,0 +1,145 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/service"
+)
+
+func TestParseNutrientTarget(t *testing.T) {
+	t.Parallel()
+
+	fiber, err := service.ParseNutrientTarget(service.NutrientTargetMin, "Fiber=30")
+	if err != nil {
+		t.Fatalf("parse fiber target: %v", err)
+	}
+	if fiber.Nutrient != "fiber" || fiber.Amount != 30 || fiber.Unit != "g" {
+		t.Fatalf("unexpected fiber target: %+v", fiber)
+	}
+	vitD, err := service.ParseNutrientTarget(service.NutrientTargetMin, "vitamin d=15mcg")
+	if err != nil {
+		t.Fatalf("parse vitamin d target: %v", err)
+	}
+	if vitD.Nutrient != "vitamin_d" || vitD.Unit != "ug" {
+		t.Fatalf("unexpected vitamin d target: %+v", vitD)
+	}
+	if _, err := service.ParseNutrientTarget(service.NutrientTargetMin, "iron=8"); err == nil {
+		t.Fatalf("expected micronutrient target without unit to fail")
+	}
+	if _, err := service.ParseNutrientTarget(service.NutrientTargetMax, "sodium=2iu"); err == nil {
+		t.Fatalf("expected sodium target with non-mass unit to fail")
+	}
+}
+
+func TestNutrientTargetsInTodayAndAdherence(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	if err := service.SetGoal(db, service.SetGoalInput{
+		Calories: 2000,
+		ProteinG: 40,
+		CarbsG:   50,
+		FatG:     15,
+		NutrientTargets: []service.NutrientTarget{
+			{Nutrient: "fiber", Kind: service.NutrientTargetMin, Amount: 30},
+			{Nutrient: "sodium", Kind: service.NutrientTargetMax, Amount: 2.3, Unit: "g"},
+			{Nutrient: "vitamin_d", Kind: service.NutrientTargetMin, Amount: 15, Unit: "ug"},
+		},
+		EffectiveDate: "2026-02-01",
+	}); err != nil {
+		t.Fatalf("set goal: %v", err)
+	}
+	if _, err := service.CreateEntry(db, service.CreateEntryInput{
+		Name:           "Oats",
+		Calories:       500,
+		ProteinG:       40,
+		CarbsG:         50,
+		FatG:           15,
+		FiberG:         12,
+		SodiumMg:       2500,
+		Micronutrients: `{"vitamin_d":{"value":0.02,"unit":"mg"}}`,
+		Category:       "breakfast",
+		Consumed:       time.Date(2026, 2, 10, 8, 0, 0, 0, time.Local),
+		SourceType:     "manual",
+	}); err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+
+	status, err := service.TodaySummary(db, time.Date(2026, 2, 10, 12, 0, 0, 0, time.Local))
+	if err != nil {
+		t.Fatalf("today summary: %v", err)
+	}
+	if len(status.NutrientTargets) != 3 {
+		t.Fatalf("expected 3 nutrient target statuses, got %+v", status.NutrientTargets)
+	}
+	byName := map[string]service.NutrientTargetStatus{}
+	for _, s := range status.NutrientTargets {
+		byName[s.Nutrient] = s
+	}
+	if fiber := byName["fiber"]; fiber.Met || fiber.Remaining != 18 {
+		t.Fatalf("expected fiber 18 g remaining, got %+v", fiber)
+	}
+	if sodium := byName["sodium"]; sodium.Met || sodium.Over < 0.199 || sodium.Over > 0.201 {
+		t.Fatalf("expected sodium 0.2 g over, got %+v", sodium)
+	}
+	if vitD := byName["vitamin_d"]; !vitD.Met || vitD.Actual != 20 {
+		t.Fatalf("expected vitamin d met at 20 ug, got %+v", vitD)
+	}
+
+	day := time.Date(2026, 2, 10, 0, 0, 0, 0, time.Local)
+	report, err := service.AnalyticsRange(db, day, day, 0.10)
+	if err != nil {
+		t.Fatalf("analytics without targets: %v", err)
+	}
+	if report.Adherence.WithinGoalDays != 1 || report.Adherence.IncludesNutrientTargets {
+		t.Fatalf("expected macro-only adherence to pass, got %+v", report.Adherence)
+	}
+	if err := service.SetConfig(db, service.ConfigAdherenceNutrientTargets, "true"); err != nil {
+		t.Fatalf("enable nutrient target adherence: %v", err)
+	}
+	report, err = service.AnalyticsRange(db, day, day, 0.10)
+	if err != nil {
+		t.Fatalf("analytics with targets: %v", err)
+	}
+	if report.Adherence.WithinGoalDays != 0 || !report.Adherence.IncludesNutrientTargets {
+		t.Fatalf("expected nutrient targets to fail adherence, got %+v", report.Adherence)
+	}
+}
+
+func TestSetGoalInheritsNutrientTargets(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	if err := service.SetGoal(db, service.SetGoalInput{
+		Calories:        2000,
+		NutrientTargets: []service.NutrientTarget{{Nutrient: "fiber", Kind: service.NutrientTargetMin, Amount: 30}},
+		EffectiveDate:   "2026-01-01",
+	}); err != nil {
+		t.Fatalf("set first goal: %v", err)
+	}
+	if err := service.SetGoal(db, service.SetGoalInput{Calories: 1800, InheritTargets: true, EffectiveDate: "2026-02-01"}); err != nil {
+		t.Fatalf("set inheriting goal: %v", err)
+	}
+	if err := service.SetGoal(db, service.SetGoalInput{Calories: 1700, EffectiveDate: "2026-03-01"}); err != nil {
+		t.Fatalf("set goal without targets: %v", err)
+	}
+
+	february, err := service.CurrentGoal(db, "2026-02-10")
+	if err != nil {
+		t.Fatalf("current february goal: %v", err)
+	}
+	targets, err := service.ParseNutrientTargetsJSON(february.NutrientTargets)
+	if err != nil || len(targets) != 1 || targets[0].Amount != 30 {
+		t.Fatalf("expected inherited fiber target, got %+v (%v)", targets, err)
+	}
+	march, err := service.CurrentGoal(db, "2026-03-10")
+	if err != nil {
+		t.Fatalf("current march goal: %v", err)
+	}
+	if march.NutrientTargets != "" {
+		t.Fatalf("expected march goal without targets, got %q", march.NutrientTargets)
+	}
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	LabelFormatUS = "us"
+	LabelFormatEU = "eu"
+
+	kJPerKcal        = 4.184
+	sodiumMgPerGSalt = 400.0 // 1 g salt = 0.4 g sodium
+)
+
+var (
+	labelDecimalComma = regexp.MustCompile(`(\d),(\d)`)
+	labelAmount       = regexp.MustCompile(`<?\s*(\d+(?:\.\d+)?)\s*(mg|mcg|µg|μg|ug|g|kj|kcal|cal|iu)?\b`)
+	labelEnergyKJ     = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*kj`)
+	labelEnergyKcal   = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*kcal`)
+	labelMetricServe  = regexp.MustCompile(`\(\s*(?:about\s+)?(\d+(?:\.\d+)?)\s*(g|ml)\s*\)`)
+	labelLeadingServe = regexp.MustCompile(`^(\d+(?:\.\d+)?(?:/\d+)?)\s*([a-z][a-z\-]*)`)
+	labelPer100       = regexp.MustCompile(`per\s+100\s*(g|ml)`)
+)
+
+var labelMicronutrients = []string{
+	"vitamin a", "vitamin b1", "vitamin b2", "vitamin b6", "vitamin b12", "vitamin c", "vitamin d", "vitamin e", "vitamin k",
+	"thiamin", "thiamine", "riboflavin", "niacin", "folate", "folic acid", "biotin", "pantothenic acid", "choline",
+	"calcium", "iron", "potassium", "magnesium", "zinc", "phosphorus", "selenium", "copper", "manganese",
+	"iodine", "chromium", "molybdenum", "chloride", "fluoride",
+}
+
+type NutritionLabel struct {
+	Format         string         `json:"format"`
+	ServingAmount  float64        `json:"serving_amount"`
+	ServingUnit    string         `json:"serving_unit"`
+	Calories       int            `json:"calories"`
+	ProteinG       float64        `json:"protein_g"`
+	CarbsG         float64        `json:"carbs_g"`
+	FatG           float64        `json:"fat_g"`
+	FiberG         float64        `json:"fiber_g"`
+	SugarG         float64        `json:"sugar_g"`
+	SodiumMg       float64        `json:"sodium_mg"`
+	Micronutrients Micronutrients `json:"micronutrients,omitempty"`
+	Notes          []string       `json:"notes,omitempty"`
+}
+
+// ParseNutritionLabel reads pasted US Nutrition Facts or EU nutrition
+// declaration text. EU tables with several columns use the first (per 100 g/ml)
+// column; salt is converted to sodium and kJ to kcal when kcal is not listed.
+func ParseNutritionLabel(text string) (NutritionLabel, error) {
+	label := NutritionLabel{Format: LabelFormatUS, ServingAmount: 1, ServingUnit: "serving", Micronutrients: Micronutrients{}}
+	normalized := strings.ToLower(text)
+	if strings.TrimSpace(normalized) == "" {
+		return label, fmt.Errorf("nutrition label text is empty")
+	}
+	if isEULabel(normalized) {
+		label.Format = LabelFormatEU
+		normalized = labelDecimalComma.ReplaceAllString(normalized, "$1.$2")
+	} else {
+		// US labels use commas as thousands separators (e.g. "1,200mg").
+		normalized = labelDecimalComma.ReplaceAllString(normalized, "$1$2")
+	}
+	if m := labelPer100.FindStringSubmatch(normalized); m != nil {
+		label.ServingAmount = 100
+		label.ServingUnit = m[1]
+	}
+
+	foundEnergy := false
+	foundAny := false
+	for _, raw := range strings.Split(normalized, "\n") {
+		line := cleanLabelLine(raw)
+		if line == "" {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "serving size") || strings.HasPrefix(line, "portion size"):
+			if label.Format == LabelFormatEU && label.ServingAmount == 100 {
+				continue
+			}
+			rest := strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(line, "serving size"), "portion size"))
+			if amount, unit, ok := parseLabelServing(rest); ok {
+				label.ServingAmount = amount
+				label.ServingUnit = unit
+			}
+		case strings.HasPrefix(line, "energy") || strings.HasPrefix(line, "calories") || strings.HasPrefix(line, "calorie"):
+			if strings.Contains(line, "from fat") || strings.Contains(line, "per gram") {
+				continue
+			}
+			if kcal, ok := parseLabelEnergy(line); ok && !foundEnergy {
+				label.Calories = kcal
+				foundEnergy = true
+			}
+		case hasLabelPrefix(line, "total fat", "fat"):
+			label.FatG, foundAny = firstLabelGrams(line, label.FatG, foundAny)
+		case hasLabelPrefix(line, "total carbohydrate", "total carbohydrates", "total carbs", "carbohydrate", "carbohydrates", "carbs"):
+			label.CarbsG, foundAny = firstLabelGrams(line, label.CarbsG, foundAny)
+		case hasLabelPrefix(line, "dietary fiber", "dietary fibre", "fiber", "fibre"):
+			label.FiberG, foundAny = firstLabelGrams(line, label.FiberG, foundAny)
+		case hasLabelPrefix(line, "includes", "added sugars") && strings.Contains(line, "added sugar"):
+			if grams, ok := firstLabelGrams(line, 0, false); ok {
+				label.Micronutrients["added_sugars"] = MicronutrientAmount{Value: grams, Unit: "g"}
+				foundAny = true
+			}
+		case hasLabelPrefix(line, "total sugars", "sugars", "sugar", "of which sugars"):
+			label.SugarG, foundAny = firstLabelGrams(line, label.SugarG, foundAny)
+		case hasLabelPrefix(line, "protein", "proteins"):
+			label.ProteinG, foundAny = firstLabelGrams(line, label.ProteinG, foundAny)
+		case hasLabelPrefix(line, "sodium"):
+			if value, unit, ok := firstLabelAmount(line); ok {
+				label.SodiumMg = massToMg(value, unit)
+				foundAny = true
+			}
+		case hasLabelPrefix(line, "salt"):
+			if value, unit, ok := firstLabelAmount(line); ok {
+				if label.SodiumMg == 0 {
+					label.SodiumMg = math.Round(massToMg(value, unit)/1000*sodiumMgPerGSalt*10) / 10
+					label.Notes = append(label.Notes, fmt.Sprintf("converted salt %.2f g to sodium %.0f mg", massToMg(value, unit)/1000, label.SodiumMg))
+				}
+				foundAny = true
+			}
+		default:
+			if name, ok := labelMicronutrientName(line); ok {
+				value, unit, ok := firstLabelAmount(strings.TrimPrefix(line, name))
+				if !ok || !isLabelMicroUnit(unit) {
+					continue
+				}
+				label.Micronutrients[normalizeMicronutrientKey(name)] = MicronutrientAmount{Value: value, Unit: labelMicroUnit(unit)}
+				foundAny = true
+			}
+		}
+	}
+	if !foundEnergy {
+		return label, fmt.Errorf("could not find calories or energy in nutrition label")
+	}
+	if !foundAny && label.Calories == 0 {
+		return label, fmt.Errorf("could not find any nutrients in nutrition label")
+	}
+	if len(label.Micronutrients) == 0 {
+		label.Micronutrients = nil
+	}
+	return label, nil
+}
+
+func SavedFoodInputFromLabel(label NutritionLabel) (CreateSavedFoodInput, error) {
+	micros, err := EncodeMicronutrientsJSON(label.Micronutrients)
+	if err != nil {
+		return CreateSavedFoodInput{}, err
+	}
+	return CreateSavedFoodInput{
+		Calories:    label.Calories,
+		ProteinG:    label.ProteinG,
+		CarbsG:      label.CarbsG,
+		FatG:        label.FatG,
+		FiberG:      label.FiberG,
+		SugarG:      label.SugarG,
+		SodiumMg:    label.SodiumMg,
+		Micros:      micros,
+		ServingAmt:  label.ServingAmount,
+		ServingUnit: label.ServingUnit,
+		SourceType:  "label",
+		Metadata:    fmt.Sprintf(`{"label_format":%q}`, label.Format),
+	}, nil
+}
+
+// isEULabel decides the format from the nutrient table itself, so words in an
+// ingredient list (e.g. "sea salt") do not switch a US label to EU parsing.
+func isEULabel(normalized string) bool {
+	if labelEnergyKJ.MatchString(normalized) || labelPer100.MatchString(normalized) {
+		return true
+	}
+	for _, raw := range strings.Split(normalized, "\n") {
+		if hasLabelPrefix(cleanLabelLine(raw), "salt") {
+			return true
+		}
+	}
+	return false
+}
+
+func cleanLabelLine(raw string) string {
+	return strings.Join(strings.Fields(strings.Trim(raw, " \t\r|:-•*")), " ")
+}
+
+func hasLabelPrefix(line string, prefixes ...string) bool {
+	for _, p := range prefixes {
+		if line == p || strings.HasPrefix(line, p+" ") || strings.HasPrefix(line, p+":") {
+			rest := strings.TrimSpace(strings.TrimPrefix(line, p))
+			if rest == "" || strings.ContainsAny(rest[:1], "0123456789<:(") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func firstLabelAmount(line string) (float64, string, bool) {
+	m := labelAmount.FindStringSubmatch(line)
+	if m == nil {
+		return 0, "", false
+	}
+	value, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, "", false
+	}
+	// A bare number followed by % is a daily value, not an amount.
+	idx := labelAmount.FindStringIndex(line)
+	if m[2] == "" && idx != nil && strings.HasPrefix(strings.TrimSpace(line[idx[1]:]), "%") {
+		return 0, "", false
+	}
+	return value, m[2], true
+}
+
+func firstLabelGrams(line string, current float64, found bool) (float64, bool) {
+	value, unit, ok := firstLabelAmount(line)
+	if !ok {
+		return current, found
+	}
+	if unit == "mg" {
+		value /= 1000
+	}
+	return value, true
+}
+
+func parseLabelEnergy(line string) (int, bool) {
+	if m := labelEnergyKcal.FindStringSubmatch(line); m != nil {
+		if v, err := strconv.ParseFloat(m[1], 64); err == nil {
+			return int(math.Round(v)), true
+		}
+	}
+	if m := labelEnergyKJ.FindStringSubmatch(line); m != nil {
+		if v, err := strconv.ParseFloat(m[1], 64); err == nil {
+			return int(math.Round(v / kJPerKcal)), true
+		}
+	}
+	value, unit, ok := firstLabelAmount(line)
+	if !ok || (unit != "" && unit != "cal") {
+		return 0, false
+	}
+	return int(math.Round(value)), true
+}
+
+func parseLabelServing(rest string) (float64, string, bool) {
+	if m := labelMetricServe.FindStringSubmatch(rest); m != nil {
+		if v, err := strconv.ParseFloat(m[1], 64); err == nil && v > 0 {
+			return v, m[2], true
+		}
+	}
+	m := labelLeadingServe.FindStringSubmatch(rest)
+	if m == nil {
+		return 0, "", false
+	}
+	value := 0.0
+	if num, den, ok := strings.Cut(m[1], "/"); ok {
+		n, err1 := strconv.ParseFloat(num, 64)
+		d, err2 := strconv.ParseFloat(den, 64)
+		if err1 != nil || err2 != nil || d == 0 {
+			return 0, "", false
+		}
+		value = n / d
+	} else {
+		v, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return 0, "", false
+		}
+		value = v
+	}
+	if value <= 0 {
+		return 0, "", false
+	}
+	return value, m[2], true
+}
+
+func labelMicronutrientName(line string) (string, bool) {
+	best := ""
+	for _, name := range labelMicronutrients {
+		if hasLabelPrefix(line, name) && len(name) > len(best) {
+			best = name
+		}
+	}
+	return best, best != ""
+}
+
+func massToMg(value float64, unit string) float64 {
+	switch unit {
+	case "g":
+		return value * 1000
+	case "mcg", "µg", "μg", "ug":
+		return value / 1000
+	default:
+		return value
+	}
+}
+
+func isLabelMicroUnit(unit string) bool {
+	switch unit {
+	case "mg", "mcg", "µg", "μg", "ug", "iu", "g":
+		return true
+	default:
+		return false
+	}
+}
+
+// labelMicroUnit folds microgram spellings, including both the micro sign
+// (U+00B5) and the Greek mu (U+03BC), to "ug".
+func labelMicroUnit(unit string) string {
+	switch unit {
+	case "mcg", "µg", "μg":
+		return "ug"
+	default:
+		return unit
+	}
+}
@@ -0,0 +1,128 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/saadjs/kcal-cli/internal/service"
+)
+
+func TestParseNutritionLabelUS(t *testing.T) {
+	t.Parallel()
+	text := `Nutrition Facts
+8 servings per container
+Serving size 2/3 cup (55g)
+Amount per serving
+Calories 230
+Total Fat 8g 10%
+  Saturated Fat 1g 5%
+  Trans Fat 0g
+Cholesterol 0mg 0%
+Sodium 1,160mg 50%
+Total Carbohydrate 37g 13%
+  Dietary Fiber 4g 14%
+  Total Sugars 12g
+    Includes 10g Added Sugars 20%
+Protein 3g
+Vitamin D 2mcg 10%
+Calcium 260mg 20%
+Iron 8mg 45%
+Vitamin A 10%
+`
+	label, err := service.ParseNutritionLabel(text)
+	if err != nil {
+		t.Fatalf("parse label: %v", err)
+	}
+	if label.Format != service.LabelFormatUS || label.ServingAmount != 55 || label.ServingUnit != "g" {
+		t.Fatalf("unexpected serving/format: %+v", label)
+	}
+	if label.Calories != 230 || label.FatG != 8 || label.CarbsG != 37 || label.FiberG != 4 || label.SugarG != 12 || label.ProteinG != 3 || label.SodiumMg != 1160 {
+		t.Fatalf("unexpected nutrients: %+v", label)
+	}
+	if got := label.Micronutrients["vitamin_d"]; got.Value != 2 || got.Unit != "ug" {
+		t.Fatalf("unexpected vitamin d: %+v", got)
+	}
+	if got := label.Micronutrients["added_sugars"]; got.Value != 10 || got.Unit != "g" {
+		t.Fatalf("unexpected added sugars: %+v", got)
+	}
+	if got := label.Micronutrients["iron"]; got.Value != 8 || got.Unit != "mg" {
+		t.Fatalf("unexpected iron: %+v", got)
+	}
+	if _, ok := label.Micronutrients["vitamin_a"]; ok {
+		t.Fatalf("expected percent-only vitamin a to be skipped")
+	}
+
+	in, err := service.SavedFoodInputFromLabel(label)
+	if err != nil {
+		t.Fatalf("saved food input from label: %v", err)
+	}
+	in.Name = "Granola"
+	db := newTestDB(t)
+	defer db.Close()
+	if _, err := service.CreateSavedFood(db, in); err != nil {
+		t.Fatalf("create saved food from label: %v", err)
+	}
+}
+
+func TestParseNutritionLabelUSWithSaltIngredient(t *testing.T) {
+	t.Parallel()
+	text := `Nutrition Facts
+Serving size 1 cup (240ml)
+Calories 90
+Total Fat 2g 3%
+Sodium 1,200mg 52%
+Total Carbohydrate 14g 5%
+Protein 4g
+Ingredients: tomatoes, water, sea salt, garlic.
+`
+	label, err := service.ParseNutritionLabel(text)
+	if err != nil {
+		t.Fatalf("parse label: %v", err)
+	}
+	if label.Format != service.LabelFormatUS {
+		t.Fatalf("expected salt in ingredients to keep US format, got %+v", label)
+	}
+	if label.SodiumMg != 1200 {
+		t.Fatalf("expected 1,200mg sodium, got %.1f", label.SodiumMg)
+	}
+}
+
+func TestParseNutritionLabelEUConvertsSaltAndKJ(t *testing.T) {
+	t.Parallel()
+	text := `Nutrition declaration | per 100 g | per portion (30 g)
+Energy 1850 kJ | 555 kJ
+Fat 17 g | 5,1 g
+of which saturates 2,1 g | 0,6 g
+Carbohydrate 63 g | 18,9 g
+of which sugars 22 g | 6,6 g
+Fibre 6,5 g | 2 g
+Protein 8,2 g | 2,5 g
+Salt 0,45 g | 0,14 g
+Vitamin C 12 mg | 3,6 mg
+`
+	label, err := service.ParseNutritionLabel(text)
+	if err != nil {
+		t.Fatalf("parse label: %v", err)
+	}
+	if label.Format != service.LabelFormatEU || label.ServingAmount != 100 || label.ServingUnit != "g" {
+		t.Fatalf("unexpected serving/format: %+v", label)
+	}
+	if label.Calories != 442 {
+		t.Fatalf("expected 1850 kJ to convert to 442 kcal, got %d", label.Calories)
+	}
+	if label.FatG != 17 || label.CarbsG != 63 || label.SugarG != 22 || label.FiberG != 6.5 || label.ProteinG != 8.2 {
+		t.Fatalf("unexpected nutrients: %+v", label)
+	}
+	if label.SodiumMg != 180 {
+		t.Fatalf("expected 0.45 g salt to convert to 180 mg sodium, got %.1f", label.SodiumMg)
+	}
+	if got := label.Micronutrients["vitamin_c"]; got.Value != 12 || got.Unit != "mg" {
+		t.Fatalf("unexpected vitamin c: %+v", got)
+	}
+}
+
+func TestParseNutritionLabelRequiresEnergy(t *testing.T) {
+	t.Parallel()
+	if _, err := service.ParseNutritionLabel("Protein 3g\nFat 1g"); err == nil {
+		t.Fatalf("expected missing energy to fail")
+	}
+}
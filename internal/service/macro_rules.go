@@ -0,0 +1,263 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/model"
+)
+
+const (
+	MacroRulePerKg     = "g_per_kg"
+	MacroRulePerLb     = "g_per_lb"
+	MacroRulePercent   = "percent"
+	MacroRuleRemainder = "remainder"
+
+	kgPerLb = 0.45359237
+)
+
+var macroRulePerWeight = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*g\s*/\s*(kg|lb)(?:[\s\-_]*(lean|lbm))?$`)
+
+// MacroRule expresses a macro target relative to body weight or calories
+// instead of fixed grams.
+type MacroRule struct {
+	Mode     string  `json:"mode"`
+	Value    float64 `json:"value,omitempty"`
+	LeanMass bool    `json:"lean_mass,omitempty"`
+}
+
+// MacroRules holds the rule per macro; nil means the goal's fixed grams.
+type MacroRules struct {
+	Protein *MacroRule `json:"protein,omitempty"`
+	Carbs   *MacroRule `json:"carbs,omitempty"`
+	Fat     *MacroRule `json:"fat,omitempty"`
+}
+
+type MacroResolution struct {
+	ProteinG   float64  `json:"protein_g"`
+	CarbsG     float64  `json:"carbs_g"`
+	FatG       float64  `json:"fat_g"`
+	WeightKg   *float64 `json:"weight_kg,omitempty"`
+	LeanMassKg *float64 `json:"lean_mass_kg,omitempty"`
+	MeasuredOn string   `json:"measured_on,omitempty"`
+	Notes      []string `json:"notes,omitempty"`
+}
+
+// ParseMacroSpec reads a goal macro flag: plain grams ("160" or "160g"),
+// grams per body weight ("2g/kg", "0.9g/lb", "2.4g/kg-lean"), a share of
+// calories ("30%"), or "remainder".
+func ParseMacroSpec(spec string) (float64, *MacroRule, error) {
+	value := strings.ToLower(strings.TrimSpace(spec))
+	if value == "" {
+		return 0, nil, fmt.Errorf("macro target is required")
+	}
+	if value == MacroRuleRemainder || value == "rest" {
+		return 0, &MacroRule{Mode: MacroRuleRemainder}, nil
+	}
+	if strings.HasSuffix(value, "%") {
+		pct, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(value, "%")), 64)
+		if err != nil || pct < 0 || pct > 100 {
+			return 0, nil, fmt.Errorf("invalid macro percentage %q (expected 0%%-100%%)", spec)
+		}
+		return 0, &MacroRule{Mode: MacroRulePercent, Value: pct}, nil
+	}
+	if m := macroRulePerWeight.FindStringSubmatch(value); m != nil {
+		amount, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return 0, nil, fmt.Errorf("invalid macro rule %q", spec)
+		}
+		mode := MacroRulePerKg
+		if m[2] == "lb" {
+			mode = MacroRulePerLb
+		}
+		return 0, &MacroRule{Mode: mode, Value: amount, LeanMass: m[3] != ""}, nil
+	}
+	grams, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(value, "g")), 64)
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid macro target %q (expected grams, g/kg, g/lb, N%%, or remainder)", spec)
+	}
+	if err := validateNonNegativeFloat("macro", grams); err != nil {
+		return 0, nil, err
+	}
+	return grams, nil, nil
+}
+
+func (r MacroRule) String() string {
+	amount := strconv.FormatFloat(r.Value, 'f', -1, 64)
+	switch r.Mode {
+	case MacroRulePercent:
+		return amount + "% kcal"
+	case MacroRuleRemainder:
+		return MacroRuleRemainder
+	}
+	unit := "kg"
+	if r.Mode == MacroRulePerLb {
+		unit = "lb"
+	}
+	if r.LeanMass {
+		return fmt.Sprintf("%s g/%s lean", amount, unit)
+	}
+	return fmt.Sprintf("%s g/%s", amount, unit)
+}
+
+func (r MacroRules) IsZero() bool {
+	return r.Protein == nil && r.Carbs == nil && r.Fat == nil
+}
+
+func (r MacroRules) validate() error {
+	remainders := 0
+	percent := 0.0
+	for _, rule := range []*MacroRule{r.Protein, r.Carbs, r.Fat} {
+		if rule == nil {
+			continue
+		}
+		switch rule.Mode {
+		case MacroRuleRemainder:
+			remainders++
+		case MacroRulePercent:
+			percent += rule.Value
+		case MacroRulePerKg, MacroRulePerLb:
+			if err := validateNonNegativeFloat("macro rule", rule.Value); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("invalid macro rule mode %q", rule.Mode)
+		}
+	}
+	if remainders > 1 {
+		return fmt.Errorf("only one macro can use the remainder")
+	}
+	if percent > 100 {
+		return fmt.Errorf("macro percentages add up to %.0f%% (must be <= 100%%)", percent)
+	}
+	return nil
+}
+
+func ParseMacroRulesJSON(value string) (MacroRules, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return MacroRules{}, nil
+	}
+	var rules MacroRules
+	if err := json.Unmarshal([]byte(value), &rules); err != nil {
+		return MacroRules{}, fmt.Errorf("macro rules must be a valid JSON object: %w", err)
+	}
+	return rules, nil
+}
+
+func EncodeMacroRulesJSON(rules MacroRules) (string, error) {
+	if rules.IsZero() {
+		return "", nil
+	}
+	if err := rules.validate(); err != nil {
+		return "", err
+	}
+	encoded, err := json.Marshal(rules)
+	if err != nil {
+		return "", fmt.Errorf("marshal macro rules: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// ResolveMacroRules turns goal's macro rules into grams for date using the
+// latest body measurement on or before it. Rules that cannot be resolved keep
+// the grams stored on the goal and add a note.
+func ResolveMacroRules(db *sql.DB, goal model.Goal, date string) (MacroResolution, error) {
+	out := MacroResolution{ProteinG: goal.ProteinG, CarbsG: goal.CarbsG, FatG: goal.FatG}
+	rules, err := ParseMacroRulesJSON(goal.MacroRules)
+	if err != nil || rules.IsZero() {
+		return out, err
+	}
+
+	if strings.TrimSpace(date) == "" {
+		date = time.Now().Format("2006-01-02")
+	}
+
+	needsWeight := false
+	for _, rule := range []*MacroRule{rules.Protein, rules.Carbs, rules.Fat} {
+		if rule != nil && (rule.Mode == MacroRulePerKg || rule.Mode == MacroRulePerLb) {
+			needsWeight = true
+		}
+	}
+	var measurement *model.BodyMeasurement
+	if needsWeight {
+		measurement, err = LatestBodyMeasurement(db, date)
+		if err != nil {
+			return out, err
+		}
+		if measurement != nil {
+			w := measurement.WeightKg
+			out.WeightKg = &w
+			out.MeasuredOn = measurement.MeasuredAt.Format("2006-01-02")
+			if measurement.BodyFatPct != nil {
+				lean := w * (1 - *measurement.BodyFatPct/100)
+				out.LeanMassKg = &lean
+			}
+		}
+	}
+
+	type macro struct {
+		name      string
+		rule      *MacroRule
+		grams     *float64
+		kcalPerG  float64
+		remainder bool
+	}
+	macros := []macro{
+		{name: "protein", rule: rules.Protein, grams: &out.ProteinG, kcalPerG: 4},
+		{name: "carbs", rule: rules.Carbs, grams: &out.CarbsG, kcalPerG: 4},
+		{name: "fat", rule: rules.Fat, grams: &out.FatG, kcalPerG: 9},
+	}
+	for i := range macros {
+		m := &macros[i]
+		if m.rule == nil {
+			continue
+		}
+		switch m.rule.Mode {
+		case MacroRulePercent:
+			*m.grams = float64(goal.Calories) * m.rule.Value / 100 / m.kcalPerG
+		case MacroRuleRemainder:
+			m.remainder = true
+		case MacroRulePerKg, MacroRulePerLb:
+			basis := out.WeightKg
+			if m.rule.LeanMass {
+				basis = out.LeanMassKg
+			}
+			if basis == nil {
+				if out.WeightKg == nil {
+					out.Notes = append(out.Notes, fmt.Sprintf("%s: no body measurement on or before %s", m.name, date))
+				} else {
+					out.Notes = append(out.Notes, fmt.Sprintf("%s: latest body measurement has no body-fat percentage", m.name))
+				}
+				continue
+			}
+			perKg := m.rule.Value
+			if m.rule.Mode == MacroRulePerLb {
+				perKg = m.rule.Value / kgPerLb
+			}
+			*m.grams = *basis * perKg
+		}
+	}
+	for _, m := range macros {
+		if !m.remainder {
+			continue
+		}
+		used := 0.0
+		for _, other := range macros {
+			if !other.remainder {
+				used += *other.grams * other.kcalPerG
+			}
+		}
+		*m.grams = math.Max(0, float64(goal.Calories)-used) / m.kcalPerG
+	}
+	out.ProteinG = math.Round(out.ProteinG*10) / 10
+	out.CarbsG = math.Round(out.CarbsG*10) / 10
+	out.FatG = math.Round(out.FatG*10) / 10
+	return out, nil
+}
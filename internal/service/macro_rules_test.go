@@ -0,0 +1,105 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/service"
+)
+
+func TestParseMacroSpec(t *testing.T) {
+	t.Parallel()
+
+	grams, rule, err := service.ParseMacroSpec("160g")
+	if err != nil || rule != nil || grams != 160 {
+		t.Fatalf("expected fixed 160 g, got %v %+v %v", grams, rule, err)
+	}
+	_, rule, err = service.ParseMacroSpec("2.4 g/kg-lean")
+	if err != nil || rule == nil || rule.Mode != service.MacroRulePerKg || rule.Value != 2.4 || !rule.LeanMass {
+		t.Fatalf("expected lean g/kg rule, got %+v %v", rule, err)
+	}
+	_, rule, err = service.ParseMacroSpec("0.9g/lb")
+	if err != nil || rule == nil || rule.Mode != service.MacroRulePerLb {
+		t.Fatalf("expected g/lb rule, got %+v %v", rule, err)
+	}
+	_, rule, err = service.ParseMacroSpec("30%")
+	if err != nil || rule == nil || rule.Mode != service.MacroRulePercent || rule.Value != 30 {
+		t.Fatalf("expected percent rule, got %+v %v", rule, err)
+	}
+	if _, _, err := service.ParseMacroSpec("lots"); err == nil {
+		t.Fatalf("expected invalid macro spec to fail")
+	}
+}
+
+func TestMacroRulesResolveFromLatestBodyMeasurement(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	bf := 20.0
+	if _, err := service.AddBodyMeasurement(db, service.BodyMeasurementInput{Weight: 80, Unit: "kg", BodyFatPct: &bf, MeasuredAt: time.Date(2026, 2, 1, 7, 0, 0, 0, time.Local)}); err != nil {
+		t.Fatalf("add first measurement: %v", err)
+	}
+	if err := service.SetGoal(db, service.SetGoalInput{
+		Calories: 2000,
+		MacroRules: service.MacroRules{
+			Protein: &service.MacroRule{Mode: service.MacroRulePerKg, Value: 2},
+			Fat:     &service.MacroRule{Mode: service.MacroRulePercent, Value: 27},
+			Carbs:   &service.MacroRule{Mode: service.MacroRuleRemainder},
+		},
+		EffectiveDate: "2026-02-01",
+	}); err != nil {
+		t.Fatalf("set rule goal: %v", err)
+	}
+	stored, err := service.CurrentGoal(db, "2026-02-01")
+	if err != nil {
+		t.Fatalf("current goal: %v", err)
+	}
+	if stored.ProteinG != 160 || stored.FatG != 60 || stored.CarbsG != 205 {
+		t.Fatalf("expected grams resolved at effective date, got %+v", stored)
+	}
+
+	if _, err := service.AddBodyMeasurement(db, service.BodyMeasurementInput{Weight: 75, Unit: "kg", MeasuredAt: time.Date(2026, 2, 10, 7, 0, 0, 0, time.Local)}); err != nil {
+		t.Fatalf("add second measurement: %v", err)
+	}
+	resolved, err := service.ResolveGoalForDate(db, "2026-02-12")
+	if err != nil {
+		t.Fatalf("resolve goal: %v", err)
+	}
+	if resolved.ProteinG != 150 || resolved.CarbsG != 215 {
+		t.Fatalf("expected protein to follow latest weight, got %+v", resolved)
+	}
+	early, err := service.ResolveGoalForDate(db, "2026-02-05")
+	if err != nil {
+		t.Fatalf("resolve early goal: %v", err)
+	}
+	if early.ProteinG != 160 {
+		t.Fatalf("expected protein from measurement on or before date, got %+v", early)
+	}
+
+	if err := service.SetGoal(db, service.SetGoalInput{
+		Calories:      2000,
+		FatG:          60,
+		CarbsG:        200,
+		MacroRules:    service.MacroRules{Protein: &service.MacroRule{Mode: service.MacroRulePerKg, Value: 2.5, LeanMass: true}},
+		EffectiveDate: "2026-03-01",
+	}); err != nil {
+		t.Fatalf("set lean goal: %v", err)
+	}
+	lean, err := service.ResolveGoalForDate(db, "2026-03-02")
+	if err != nil {
+		t.Fatalf("resolve lean goal: %v", err)
+	}
+	if len(lean.MacroNotes) != 1 || lean.FatG != 60 {
+		t.Fatalf("expected note for missing body fat and fixed fat grams, got %+v", lean)
+	}
+
+	err = service.SetGoal(db, service.SetGoalInput{
+		Calories:      2000,
+		MacroRules:    service.MacroRules{Carbs: &service.MacroRule{Mode: service.MacroRuleRemainder}, Fat: &service.MacroRule{Mode: service.MacroRuleRemainder}},
+		EffectiveDate: "2026-04-01",
+	})
+	if err == nil {
+		t.Fatalf("expected two remainder macros to fail")
+	}
+}
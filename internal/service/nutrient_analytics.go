@@ -0,0 +1,351 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	NutrientStatusAdequate         = "adequate"
+	NutrientStatusShortfall        = "shortfall"
+	NutrientStatusExcess           = "excess"
+	NutrientStatusInsufficientData = "insufficient_data"
+	NutrientStatusNoReference      = "no_reference"
+
+	// A shortfall or excess is chronic when it holds on at least this share
+	// of the days with data, over at least chronicNutrientMinDays days.
+	chronicNutrientDayShare = 0.6
+	chronicNutrientMinDays  = 3
+)
+
+type NutrientReference struct {
+	Kind   string   `json:"kind"`
+	Target float64  `json:"target"`
+	UL     *float64 `json:"ul,omitempty"`
+}
+
+// NutrientSummary aggregates one micronutrient over the days that reported
+// it. Days with logged food but no value for the nutrient are not counted as
+// zero, since most foods only carry a few micronutrients.
+type NutrientSummary struct {
+	Nutrient        string             `json:"nutrient"`
+	Unit            string             `json:"unit"`
+	DaysWithData    int                `json:"days_with_data"`
+	AvgPerDay       float64            `json:"avg_per_day"`
+	MinPerDay       float64            `json:"min_per_day"`
+	MaxPerDay       float64            `json:"max_per_day"`
+	Reference       *NutrientReference `json:"reference,omitempty"`
+	PercentOfTarget *float64           `json:"percent_of_target,omitempty"`
+	DaysBelowTarget int                `json:"days_below_target"`
+	DaysAboveUL     int                `json:"days_above_ul"`
+	Status          string             `json:"status"`
+}
+
+type NutrientDay struct {
+	Date    string             `json:"date"`
+	Amounts map[string]float64 `json:"amounts"`
+}
+
+type NutrientAnalyticsReport struct {
+	FromDate       string            `json:"from_date"`
+	ToDate         string            `json:"to_date"`
+	Sex            string            `json:"sex,omitempty"`
+	Age            *int              `json:"age,omitempty"`
+	ReferenceGroup string            `json:"reference_group,omitempty"`
+	LoggedDays     int               `json:"logged_days"`
+	Nutrients      []NutrientSummary `json:"nutrients"`
+	Shortfalls     []string          `json:"shortfalls"`
+	Excesses       []string          `json:"excesses"`
+	Days           []NutrientDay     `json:"days"`
+	Warnings       []string          `json:"warnings,omitempty"`
+}
+
+// AnalyticsNutrients totals micronutrients per day across entries, normalizes
+// their units and compares them with the reference intakes for the profile's
+// sex and age at the end of the range.
+func AnalyticsNutrients(db *sql.DB, from, to time.Time) (*NutrientAnalyticsReport, error) {
+	if from.After(to) {
+		return nil, fmt.Errorf("from date must be <= to date")
+	}
+	from = beginningOfDay(from)
+	to = beginningOfDay(to)
+	report := &NutrientAnalyticsReport{
+		FromDate:   from.Format("2006-01-02"),
+		ToDate:     to.Format("2006-01-02"),
+		Nutrients:  make([]NutrientSummary, 0),
+		Shortfalls: make([]string, 0),
+		Excesses:   make([]string, 0),
+		Days:       make([]NutrientDay, 0),
+	}
+
+	profile, err := GetProfile(db)
+	if err != nil {
+		return nil, err
+	}
+	age := -1
+	if profile != nil && profile.Sex != "" && profile.BirthDate != "" {
+		a, err := AgeOn(profile.BirthDate, to)
+		if err != nil {
+			return nil, err
+		}
+		age = a
+		report.Sex = profile.Sex
+		report.Age = &a
+		if a >= minReferenceAge {
+			report.ReferenceGroup = referenceGroupLabel(profile.Sex, a)
+		} else {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("reference intakes cover ages %d and up; nutrients are reported without targets", minReferenceAge))
+		}
+	} else {
+		report.Warnings = append(report.Warnings, "set sex and birth date with `kcal profile set` to compare against reference intakes")
+	}
+
+	totals, err := loadNutrientDayTotals(db, from, to)
+	if err != nil {
+		return nil, err
+	}
+	report.LoggedDays = len(totals.days)
+	report.Warnings = append(report.Warnings, totals.warnings()...)
+
+	for _, day := range totals.days {
+		amounts := map[string]float64{}
+		for nutrient, v := range totals.byDay[day] {
+			amounts[nutrient] = roundTo(v, 2)
+		}
+		report.Days = append(report.Days, NutrientDay{Date: day, Amounts: amounts})
+	}
+
+	for _, nutrient := range totals.nutrients() {
+		s := NutrientSummary{Nutrient: nutrient, Unit: totals.units[nutrient], Status: NutrientStatusNoReference}
+		values := make([]float64, 0, len(totals.days))
+		for _, day := range totals.days {
+			if v, ok := totals.byDay[day][nutrient]; ok {
+				values = append(values, v)
+			}
+		}
+		s.DaysWithData = len(values)
+		s.AvgPerDay = roundTo(avg(values), 2)
+		s.MinPerDay = roundTo(slices.Min(values), 2)
+		s.MaxPerDay = roundTo(slices.Max(values), 2)
+
+		if ref, ok := lookupNutrientReference(nutrient); ok && report.ReferenceGroup != "" {
+			if band, ok := ref.band(age); ok {
+				evaluateNutrientReference(&s, ref.Kind, band, report.Sex, values)
+			}
+		}
+		switch s.Status {
+		case NutrientStatusShortfall:
+			report.Shortfalls = append(report.Shortfalls, nutrient)
+		case NutrientStatusExcess:
+			report.Excesses = append(report.Excesses, nutrient)
+		}
+		report.Nutrients = append(report.Nutrients, s)
+	}
+	return report, nil
+}
+
+func evaluateNutrientReference(s *NutrientSummary, kind string, band driBand, sex string, values []float64) {
+	target := band.Male
+	if sex == SexFemale {
+		target = band.Female
+	}
+	s.Reference = &NutrientReference{Kind: kind, Target: target}
+	if band.UL > 0 {
+		ul := band.UL
+		s.Reference.UL = &ul
+	}
+	pct := roundTo(s.AvgPerDay/target*100, 1)
+	s.PercentOfTarget = &pct
+	for _, v := range values {
+		if v < target {
+			s.DaysBelowTarget++
+		}
+		if band.UL > 0 && v > band.UL {
+			s.DaysAboveUL++
+		}
+	}
+
+	chronic := func(days int) bool {
+		return float64(days) >= chronicNutrientDayShare*float64(len(values))
+	}
+	switch {
+	case len(values) < chronicNutrientMinDays:
+		s.Status = NutrientStatusInsufficientData
+	case chronic(s.DaysAboveUL) && s.DaysAboveUL > 0:
+		s.Status = NutrientStatusExcess
+	case chronic(s.DaysBelowTarget) && s.DaysBelowTarget > 0:
+		s.Status = NutrientStatusShortfall
+	default:
+		s.Status = NutrientStatusAdequate
+	}
+}
+
+// nutrientDayTotals holds per-day micronutrient totals in each nutrient's
+// normalized unit.
+type nutrientDayTotals struct {
+	days          []string
+	byDay         map[string]map[string]float64
+	units         map[string]string
+	invalidJSON   int
+	unconvertible map[nutrientUnit]int
+}
+
+type nutrientUnit struct {
+	nutrient string
+	unit     string
+}
+
+func (t nutrientDayTotals) add(day, nutrient, unit string, value float64) {
+	t.units[nutrient] = unit
+	t.byDay[day][nutrient] += value
+}
+
+// nutrients lists nutrients with data in reference table order, followed by
+// the rest alphabetically.
+func (t nutrientDayTotals) nutrients() []string {
+	out := make([]string, 0, len(t.units))
+	for nutrient := range t.units {
+		out = append(out, nutrient)
+	}
+	slices.SortFunc(out, func(a, b string) int {
+		ia, okA := nutrientReferenceIndex[a]
+		ib, okB := nutrientReferenceIndex[b]
+		switch {
+		case okA && okB:
+			return ia - ib
+		case okA:
+			return -1
+		case okB:
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+	return out
+}
+
+func (t nutrientDayTotals) warnings() []string {
+	var out []string
+	if t.invalidJSON > 0 {
+		out = append(out, fmt.Sprintf("skipped micronutrients on %d entries with invalid micronutrients JSON", t.invalidJSON))
+	}
+	keys := make([]nutrientUnit, 0, len(t.unconvertible))
+	for k := range t.unconvertible {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b nutrientUnit) int {
+		if a.nutrient != b.nutrient {
+			return strings.Compare(a.nutrient, b.nutrient)
+		}
+		return strings.Compare(a.unit, b.unit)
+	})
+	for _, k := range keys {
+		to := t.units[k.nutrient]
+		if ref, ok := lookupNutrientReference(k.nutrient); ok {
+			to = ref.Unit
+		}
+		out = append(out, fmt.Sprintf("skipped %d %s values in %s that could not be converted to %s", t.unconvertible[k], k.nutrient, k.unit, to))
+	}
+	return out
+}
+
+func loadNutrientDayTotals(db *sql.DB, from, to time.Time) (nutrientDayTotals, error) {
+	rows, err := db.Query(`
+SELECT substr(consumed_at, 1, 10) as day, sodium_mg, IFNULL(micronutrients_json, '')
+FROM entries
+WHERE consumed_at >= ? AND consumed_at < ?
+ORDER BY consumed_at ASC, id ASC
+`, from.Format(time.RFC3339), to.Add(24*time.Hour).Format(time.RFC3339))
+	if err != nil {
+		return nutrientDayTotals{}, fmt.Errorf("query entry micronutrients: %w", err)
+	}
+	defer rows.Close()
+
+	out := nutrientDayTotals{
+		byDay:         map[string]map[string]float64{},
+		units:         map[string]string{},
+		unconvertible: map[nutrientUnit]int{},
+	}
+	for rows.Next() {
+		var day, microsRaw string
+		var sodium float64
+		if err := rows.Scan(&day, &sodium, &microsRaw); err != nil {
+			return nutrientDayTotals{}, fmt.Errorf("scan entry micronutrients: %w", err)
+		}
+		if _, ok := out.byDay[day]; !ok {
+			out.days = append(out.days, day)
+			out.byDay[day] = map[string]float64{}
+		}
+		if sodium > 0 {
+			out.add(day, "sodium", "mg", sodium)
+		}
+		micros, err := ParseMicronutrientsJSON(microsRaw)
+		if err != nil {
+			out.invalidJSON++
+			continue
+		}
+		for nutrient, amount := range entryMicronutrients(micros) {
+			unit, value, ok := out.normalize(nutrient, amount)
+			if !ok {
+				out.unconvertible[nutrientUnit{nutrient: nutrient, unit: amount.Unit}]++
+				continue
+			}
+			out.add(day, nutrient, unit, value)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nutrientDayTotals{}, fmt.Errorf("iterate entry micronutrients: %w", err)
+	}
+	return out, nil
+}
+
+// normalize converts an amount to the nutrient's reference unit, or for
+// nutrients outside the table to the first unit seen for it. IU is converted
+// only where the table has a factor.
+func (t nutrientDayTotals) normalize(nutrient string, amount MicronutrientAmount) (string, float64, bool) {
+	from := labelMicroUnit(strings.ToLower(strings.TrimSpace(amount.Unit)))
+	to := from
+	ref, hasRef := lookupNutrientReference(nutrient)
+	if hasRef {
+		to = ref.Unit
+	} else if unit, ok := t.units[nutrient]; ok {
+		to = unit
+	}
+	switch {
+	case from == to:
+		return to, amount.Value, true
+	case isMassUnit(from) && isMassUnit(to):
+		return to, convertMass(amount.Value, from, to), true
+	case from == "iu" && hasRef && ref.IUFactor > 0:
+		return to, amount.Value * ref.IUFactor, true
+	}
+	return to, 0, false
+}
+
+// entryMicronutrients maps one entry's micronutrients to canonical names.
+// Sodium is read from the entry column instead. When several keys name the
+// same nutrient (USDA reports vitamin D in both ug and IU), a mass unit wins
+// so the entry is only counted once.
+func entryMicronutrients(micros Micronutrients) map[string]MicronutrientAmount {
+	keys := make([]string, 0, len(micros))
+	for k := range micros {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	out := map[string]MicronutrientAmount{}
+	for _, k := range keys {
+		nutrient := canonicalMicronutrient(k)
+		if nutrient == "sodium" {
+			continue
+		}
+		amount := micros[k]
+		current, ok := out[nutrient]
+		if ok && (isMassUnit(labelMicroUnit(strings.ToLower(current.Unit))) || !isMassUnit(labelMicroUnit(strings.ToLower(amount.Unit)))) {
+			continue
+		}
+		out[nutrient] = amount
+	}
+	return out
+}
@@ -0,0 +1,91 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/service"
+)
+
+func TestAnalyticsNutrientsAgainstReferenceIntakes(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)
+	to := time.Date(2026, 3, 5, 0, 0, 0, 0, time.Local)
+	for i := range 4 {
+		day := from.AddDate(0, 0, i)
+		entries := []service.CreateEntryInput{
+			// USDA-style keys, upper-case units and vitamin D reported in both ug and IU.
+			{Name: "lunch", Calories: 600, Category: "lunch", SodiumMg: 1800, Consumed: day.Add(12 * time.Hour),
+				Micronutrients: `{"iron_fe":{"value":6,"unit":"MG"},"vitamin_d":{"value":5,"unit":"ug"},"vitamin_d_iu":{"value":200,"unit":"IU"},"calcium_ca":{"value":0.6,"unit":"g"},"lycopene":{"value":300,"unit":"mcg"},"selenium":{"value":40,"unit":"μg"}}`},
+			{Name: "snack", Calories: 200, Category: "snacks", SodiumMg: 900, Consumed: day.Add(16 * time.Hour),
+				Micronutrients: `{"vitamin_a":{"value":1000,"unit":"IU"},"calcium":{"value":500,"unit":"mg"},"lycopene":{"value":1,"unit":"mg"},"selenium_se":{"value":0.02,"unit":"mg"}}`},
+		}
+		for _, in := range entries {
+			if _, err := service.CreateEntry(db, in); err != nil {
+				t.Fatalf("create entry: %v", err)
+			}
+		}
+	}
+
+	report, err := service.AnalyticsNutrients(db, from, to)
+	if err != nil {
+		t.Fatalf("nutrient analytics without profile: %v", err)
+	}
+	if report.ReferenceGroup != "" || len(report.Warnings) == 0 || report.Nutrients[0].Status != service.NutrientStatusNoReference {
+		t.Fatalf("expected no reference comparison without a profile, got %+v", report)
+	}
+
+	if _, err := service.SetProfile(db, service.ProfileInput{Sex: "female", BirthDate: "1990-01-01"}); err != nil {
+		t.Fatalf("set profile: %v", err)
+	}
+	report, err = service.AnalyticsNutrients(db, from, to)
+	if err != nil {
+		t.Fatalf("nutrient analytics: %v", err)
+	}
+	if report.LoggedDays != 4 || report.ReferenceGroup != "female 31-50" || len(report.Days) != 4 {
+		t.Fatalf("unexpected report header %+v", report)
+	}
+	byName := map[string]service.NutrientSummary{}
+	for _, n := range report.Nutrients {
+		byName[n.Nutrient] = n
+	}
+
+	// 1000 IU of vitamin A is 300 ug RAE; the IU duplicate of vitamin D is ignored.
+	if n := byName["vitamin_a"]; n.Unit != "ug" || n.AvgPerDay != 300 {
+		t.Fatalf("unexpected vitamin A %+v", n)
+	}
+	if n := byName["vitamin_d"]; n.AvgPerDay != 5 || n.Status != service.NutrientStatusShortfall {
+		t.Fatalf("unexpected vitamin D %+v", n)
+	}
+	if n := byName["iron"]; n.Unit != "mg" || n.Reference == nil || n.Reference.Target != 18 || n.DaysBelowTarget != 4 || n.Status != service.NutrientStatusShortfall {
+		t.Fatalf("expected iron shortfall against the female RDA, got %+v", n)
+	}
+	if n := byName["calcium"]; n.AvgPerDay != 1100 || n.PercentOfTarget == nil || *n.PercentOfTarget != 110 || n.Status != service.NutrientStatusAdequate {
+		t.Fatalf("unexpected calcium %+v", n)
+	}
+	if n := byName["sodium"]; n.AvgPerDay != 2700 || n.DaysAboveUL != 4 || n.Status != service.NutrientStatusExcess {
+		t.Fatalf("expected sodium excess, got %+v", n)
+	}
+	if n := byName["lycopene"]; n.Unit != "ug" || n.AvgPerDay != 1300 || n.Reference != nil || n.Status != service.NutrientStatusNoReference {
+		t.Fatalf("unexpected lycopene %+v", n)
+	}
+	// Greek mu (U+03BC) micrograms fold into ug like the micro sign.
+	if n := byName["selenium"]; n.Unit != "ug" || n.AvgPerDay != 60 || n.Status != service.NutrientStatusAdequate {
+		t.Fatalf("unexpected selenium %+v", n)
+	}
+	if len(report.Shortfalls) != 3 || report.Shortfalls[0] != "vitamin_a" || len(report.Excesses) != 1 || report.Excesses[0] != "sodium" {
+		t.Fatalf("unexpected shortfalls %v and excesses %v", report.Shortfalls, report.Excesses)
+	}
+
+	// Two days of data are not enough to call a shortfall chronic.
+	short, err := service.AnalyticsNutrients(db, from, from.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("short range: %v", err)
+	}
+	if short.Nutrients[0].Status != service.NutrientStatusInsufficientData || len(short.Shortfalls) != 0 {
+		t.Fatalf("expected insufficient data over two days, got %+v", short.Nutrients[0])
+	}
+}
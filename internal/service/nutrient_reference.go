@@ -0,0 +1,148 @@
+package service
+
+import "fmt"
+
+const (
+	NutrientReferenceRDA = "rda"
+	NutrientReferenceAI  = "ai"
+
+	// minReferenceAge is the youngest age the bundled table covers.
+	minReferenceAge = 14
+)
+
+// driBand holds the reference intake from FromAge until the next band. UL is
+// 0 when no tolerable upper intake level applies to food intake.
+type driBand struct {
+	FromAge int
+	Male    float64
+	Female  float64
+	UL      float64
+}
+
+// nutrientReference describes one micronutrient in the bundled table. IUFactor
+// converts International Units to Unit and is 0 when IU cannot be converted.
+type nutrientReference struct {
+	Nutrient string
+	Unit     string
+	Kind     string
+	IUFactor float64
+	Aliases  []string
+	Bands    []driBand
+}
+
+// nutrientReferences holds US Dietary Reference Intakes (RDA or AI, and UL)
+// for ages 14 and up, excluding pregnancy and lactation. ULs that only apply
+// to supplements or fortified foods (vitamin A as preformed retinol, vitamin
+// E, niacin, folic acid, magnesium) are left out; sodium uses the 2300 mg
+// chronic disease risk reduction intake as its upper level.
+var nutrientReferences = []nutrientReference{
+	{Nutrient: "vitamin_a", Unit: "ug", Kind: NutrientReferenceRDA, IUFactor: 0.3, Aliases: []string{"vitamin_a_rae", "vitamin_a_iu", "vitamin_a_ug"},
+		Bands: []driBand{{14, 900, 700, 0}}},
+	{Nutrient: "vitamin_c", Unit: "mg", Kind: NutrientReferenceRDA, Aliases: []string{"vitamin_c_total_ascorbic_acid", "ascorbic_acid"},
+		Bands: []driBand{{14, 75, 65, 1800}, {19, 90, 75, 2000}}},
+	{Nutrient: "vitamin_d", Unit: "ug", Kind: NutrientReferenceRDA, IUFactor: 0.025, Aliases: []string{"vitamin_d_d2_d3", "vitamin_d_d2_d3_international_units", "vitamin_d_iu", "vitamin_d3", "cholecalciferol"},
+		Bands: []driBand{{14, 15, 15, 100}, {71, 20, 20, 100}}},
+	{Nutrient: "vitamin_e", Unit: "mg", Kind: NutrientReferenceRDA, IUFactor: 0.67, Aliases: []string{"vitamin_e_alpha_tocopherol", "alpha_tocopherol"},
+		Bands: []driBand{{14, 15, 15, 0}}},
+	{Nutrient: "vitamin_k", Unit: "ug", Kind: NutrientReferenceAI, Aliases: []string{"vitamin_k_phylloquinone", "phylloquinone"},
+		Bands: []driBand{{14, 75, 75, 0}, {19, 120, 90, 0}}},
+	{Nutrient: "thiamin", Unit: "mg", Kind: NutrientReferenceRDA, Aliases: []string{"thiamine", "vitamin_b1"},
+		Bands: []driBand{{14, 1.2, 1.0, 0}, {19, 1.2, 1.1, 0}}},
+	{Nutrient: "riboflavin", Unit: "mg", Kind: NutrientReferenceRDA, Aliases: []string{"vitamin_b2"},
+		Bands: []driBand{{14, 1.3, 1.0, 0}, {19, 1.3, 1.1, 0}}},
+	{Nutrient: "niacin", Unit: "mg", Kind: NutrientReferenceRDA, Aliases: []string{"vitamin_b3", "vitamin_pp"},
+		Bands: []driBand{{14, 16, 14, 0}}},
+	{Nutrient: "vitamin_b6", Unit: "mg", Kind: NutrientReferenceRDA, Aliases: []string{"vitamin_b_6", "pyridoxine"},
+		Bands: []driBand{{14, 1.3, 1.2, 80}, {19, 1.3, 1.3, 100}, {51, 1.7, 1.5, 100}}},
+	{Nutrient: "folate", Unit: "ug", Kind: NutrientReferenceRDA, Aliases: []string{"folate_dfe", "folate_total", "folic_acid", "vitamin_b9"},
+		Bands: []driBand{{14, 400, 400, 0}}},
+	{Nutrient: "vitamin_b12", Unit: "ug", Kind: NutrientReferenceRDA, Aliases: []string{"vitamin_b_12", "cobalamin"},
+		Bands: []driBand{{14, 2.4, 2.4, 0}}},
+	{Nutrient: "pantothenic_acid", Unit: "mg", Kind: NutrientReferenceAI, Aliases: []string{"vitamin_b5"},
+		Bands: []driBand{{14, 5, 5, 0}}},
+	{Nutrient: "biotin", Unit: "ug", Kind: NutrientReferenceAI, Aliases: []string{"vitamin_b7"},
+		Bands: []driBand{{14, 25, 25, 0}, {19, 30, 30, 0}}},
+	{Nutrient: "choline", Unit: "mg", Kind: NutrientReferenceAI, Aliases: []string{"choline_total"},
+		Bands: []driBand{{14, 550, 400, 3000}, {19, 550, 425, 3500}}},
+	{Nutrient: "calcium", Unit: "mg", Kind: NutrientReferenceRDA, Aliases: []string{"calcium_ca"},
+		Bands: []driBand{{14, 1300, 1300, 3000}, {19, 1000, 1000, 2500}, {51, 1000, 1200, 2000}, {71, 1200, 1200, 2000}}},
+	{Nutrient: "iron", Unit: "mg", Kind: NutrientReferenceRDA, Aliases: []string{"iron_fe"},
+		Bands: []driBand{{14, 11, 15, 45}, {19, 8, 18, 45}, {51, 8, 8, 45}}},
+	{Nutrient: "magnesium", Unit: "mg", Kind: NutrientReferenceRDA, Aliases: []string{"magnesium_mg"},
+		Bands: []driBand{{14, 410, 360, 0}, {19, 400, 310, 0}, {31, 420, 320, 0}}},
+	{Nutrient: "phosphorus", Unit: "mg", Kind: NutrientReferenceRDA, Aliases: []string{"phosphorus_p"},
+		Bands: []driBand{{14, 1250, 1250, 4000}, {19, 700, 700, 4000}, {71, 700, 700, 3000}}},
+	{Nutrient: "potassium", Unit: "mg", Kind: NutrientReferenceAI, Aliases: []string{"potassium_k"},
+		Bands: []driBand{{14, 3000, 2300, 0}, {19, 3400, 2600, 0}}},
+	{Nutrient: "sodium", Unit: "mg", Kind: NutrientReferenceAI, Aliases: []string{"sodium_mg", "sodium_na"},
+		Bands: []driBand{{14, 1500, 1500, 2300}}},
+	{Nutrient: "zinc", Unit: "mg", Kind: NutrientReferenceRDA, Aliases: []string{"zinc_zn"},
+		Bands: []driBand{{14, 11, 9, 34}, {19, 11, 8, 40}}},
+	{Nutrient: "selenium", Unit: "ug", Kind: NutrientReferenceRDA, Aliases: []string{"selenium_se"},
+		Bands: []driBand{{14, 55, 55, 400}}},
+	{Nutrient: "copper", Unit: "ug", Kind: NutrientReferenceRDA, Aliases: []string{"copper_cu"},
+		Bands: []driBand{{14, 890, 890, 8000}, {19, 900, 900, 10000}}},
+	{Nutrient: "manganese", Unit: "mg", Kind: NutrientReferenceAI, Aliases: []string{"manganese_mn"},
+		Bands: []driBand{{14, 2.2, 1.6, 9}, {19, 2.3, 1.8, 11}}},
+	{Nutrient: "iodine", Unit: "ug", Kind: NutrientReferenceRDA, Aliases: []string{"iodine_i"},
+		Bands: []driBand{{14, 150, 150, 900}, {19, 150, 150, 1100}}},
+}
+
+// nutrientReferenceIndex maps each nutrient name and alias to its table
+// position.
+var nutrientReferenceIndex = func() map[string]int {
+	index := map[string]int{}
+	for i, ref := range nutrientReferences {
+		index[ref.Nutrient] = i
+		for _, alias := range ref.Aliases {
+			index[alias] = i
+		}
+	}
+	return index
+}()
+
+// canonicalMicronutrient maps a stored micronutrient key to its reference
+// table name; keys without a reference entry are returned unchanged.
+func canonicalMicronutrient(key string) string {
+	key = normalizeMicronutrientKey(key)
+	if i, ok := nutrientReferenceIndex[key]; ok {
+		return nutrientReferences[i].Nutrient
+	}
+	return key
+}
+
+func lookupNutrientReference(nutrient string) (nutrientReference, bool) {
+	i, ok := nutrientReferenceIndex[nutrient]
+	if !ok {
+		return nutrientReference{}, false
+	}
+	return nutrientReferences[i], true
+}
+
+// band returns the reference band for age, or false below the table's range.
+func (r nutrientReference) band(age int) (driBand, bool) {
+	var out driBand
+	found := false
+	for _, b := range r.Bands {
+		if age >= b.FromAge {
+			out, found = b, true
+		}
+	}
+	return out, found
+}
+
+// referenceGroupLabel names the age band used for a profile, e.g.
+// "female 31-50".
+func referenceGroupLabel(sex string, age int) string {
+	starts := []int{14, 19, 31, 51, 71}
+	for i := len(starts) - 1; i >= 0; i-- {
+		if age < starts[i] {
+			continue
+		}
+		if i == len(starts)-1 {
+			return fmt.Sprintf("%s %d+", sex, starts[i])
+		}
+		return fmt.Sprintf("%s %d-%d", sex, starts[i], starts[i+1]-1)
+	}
+	return ""
+}
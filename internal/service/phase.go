@@ -0,0 +1,364 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/model"
+)
+
+// PhaseInput defines one phase of a diet program. CalorieStrategy is fixed
+// calories ("2200"), "maintenance", an offset from maintenance ("-500",
+// "+10%"), or a weekly weight change ("-0.5kg/week", "cut", "bulk").
+type PhaseInput struct {
+	Name                string
+	StartDate           string
+	DurationDays        int
+	CalorieStrategy     string
+	MaintenanceCalories int
+	Protein             string
+	Carbs               string
+	Fat                 string
+}
+
+// PhaseStatus locates a date inside a phase; Week and Day are 1-based.
+type PhaseStatus struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	StartDate  string `json:"start_date"`
+	EndDate    string `json:"end_date"`
+	Week       int    `json:"week"`
+	TotalWeeks int    `json:"total_weeks"`
+	Day        int    `json:"day"`
+	TotalDays  int    `json:"total_days"`
+}
+
+// PhaseEndDate returns the last day of p.
+func PhaseEndDate(p model.Phase) string {
+	start, err := time.ParseInLocation("2006-01-02", p.StartDate, time.Local)
+	if err != nil {
+		return ""
+	}
+	return start.AddDate(0, 0, p.DurationDays-1).Format("2006-01-02")
+}
+
+// AddPhase stores a phase and writes the goal version that starts with it.
+// Without a start date the phase begins the day after the last phase ends.
+func AddPhase(db *sql.DB, in PhaseInput) (*model.Phase, error) {
+	p := model.Phase{
+		Name:            strings.TrimSpace(in.Name),
+		DurationDays:    in.DurationDays,
+		CalorieStrategy: strings.ToLower(strings.TrimSpace(in.CalorieStrategy)),
+		ProteinSpec:     strings.TrimSpace(in.Protein),
+		CarbsSpec:       strings.TrimSpace(in.Carbs),
+		FatSpec:         strings.TrimSpace(in.Fat),
+	}
+	if p.Name == "" {
+		return nil, fmt.Errorf("phase name is required")
+	}
+	if p.DurationDays <= 0 {
+		return nil, fmt.Errorf("phase duration must be > 0 days")
+	}
+
+	phases, err := ListPhases(db)
+	if err != nil {
+		return nil, err
+	}
+	start := strings.TrimSpace(in.StartDate)
+	if start == "" {
+		start = time.Now().Format("2006-01-02")
+		if n := len(phases); n > 0 {
+			last, _ := time.ParseInLocation("2006-01-02", PhaseEndDate(phases[n-1]), time.Local)
+			start = last.AddDate(0, 0, 1).Format("2006-01-02")
+		}
+	}
+	if _, err := time.ParseInLocation("2006-01-02", start, time.Local); err != nil {
+		return nil, fmt.Errorf("invalid phase start date %q (expected YYYY-MM-DD)", start)
+	}
+	p.StartDate = start
+	end := PhaseEndDate(p)
+	for _, other := range phases {
+		if p.StartDate <= PhaseEndDate(other) && other.StartDate <= end {
+			return nil, fmt.Errorf("phase overlaps %q (%s to %s)", other.Name, other.StartDate, PhaseEndDate(other))
+		}
+	}
+
+	if in.MaintenanceCalories < 0 {
+		return nil, fmt.Errorf("maintenance calories must be > 0")
+	}
+	maintenance := in.MaintenanceCalories
+	if calorieStrategyNeedsMaintenance(p.CalorieStrategy) && maintenance == 0 {
+		maintenance, err = profileMaintenance(db, p.StartDate)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if maintenance > 0 {
+		p.MaintenanceCalories = &maintenance
+	}
+	p.Calories, err = ResolveCalorieStrategy(p.CalorieStrategy, maintenance)
+	if err != nil {
+		return nil, err
+	}
+
+	goal := SetGoalInput{Calories: p.Calories, InheritTargets: true, EffectiveDate: p.StartDate}
+	for _, m := range []struct {
+		spec  string
+		grams *float64
+		rule  **MacroRule
+	}{
+		{p.ProteinSpec, &goal.ProteinG, &goal.MacroRules.Protein},
+		{p.CarbsSpec, &goal.CarbsG, &goal.MacroRules.Carbs},
+		{p.FatSpec, &goal.FatG, &goal.MacroRules.Fat},
+	} {
+		grams, rule, err := ParseMacroSpec(m.spec)
+		if err != nil {
+			return nil, err
+		}
+		*m.grams = grams
+		*m.rule = rule
+	}
+	row, err := prepareGoal(db, goal)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin add phase tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// The phase owns the goal version it writes, so it must not take over
+	// one the user set.
+	var existing int64
+	err = tx.QueryRow(`SELECT id FROM goals WHERE effective_date = ?`, p.StartDate).Scan(&existing)
+	if err == nil {
+		return nil, fmt.Errorf("a goal version already starts on %s; choose another phase start date", p.StartDate)
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("lookup goal on %s: %w", p.StartDate, err)
+	}
+	res, err := tx.Exec(`
+INSERT INTO goals(calories, protein_g, carbs_g, fat_g, nutrient_targets_json, macro_rules_json, effective_date)
+VALUES(?, ?, ?, ?, ?, ?, ?)
+`, row.Calories, row.ProteinG, row.CarbsG, row.FatG, row.NutrientTargets, row.MacroRules, row.EffectiveDate)
+	if err != nil {
+		return nil, fmt.Errorf("set phase goal: %w", err)
+	}
+	goalID, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("resolve phase goal id: %w", err)
+	}
+
+	res, err = tx.Exec(`
+INSERT INTO phases(name, start_date, duration_days, calorie_strategy, maintenance_calories, calories, protein_spec, carbs_spec, fat_spec, goal_id)
+VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`, p.Name, p.StartDate, p.DurationDays, p.CalorieStrategy, p.MaintenanceCalories, p.Calories, p.ProteinSpec, p.CarbsSpec, p.FatSpec, goalID)
+	if err != nil {
+		return nil, fmt.Errorf("add phase: %w", err)
+	}
+	p.ID, err = res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("resolve phase id: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit add phase: %w", err)
+	}
+	return &p, nil
+}
+
+// ResolveCalorieStrategy turns a phase calorie strategy into daily calories.
+func ResolveCalorieStrategy(strategy string, maintenance int) (int, error) {
+	value := strings.ToLower(strings.TrimSpace(strategy))
+	if value == "" {
+		return 0, fmt.Errorf("calorie strategy is required")
+	}
+	if !calorieStrategyNeedsMaintenance(value) {
+		calories, err := strconv.Atoi(value)
+		if err != nil || calories <= 0 {
+			return 0, fmt.Errorf("invalid calorie strategy %q (expected kcal, maintenance, -500, +10%%, or -0.5kg/week)", strategy)
+		}
+		return calories, nil
+	}
+	if maintenance <= 0 {
+		return 0, fmt.Errorf("calorie strategy %q needs maintenance calories", strategy)
+	}
+	switch {
+	case value == "maintenance" || value == "maintain":
+		return maintenance, nil
+	case strings.HasSuffix(value, "%"):
+		pct, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+		if err != nil || pct <= -100 {
+			return 0, fmt.Errorf("invalid calorie strategy %q", strategy)
+		}
+		return int(math.Round(float64(maintenance) * (1 + pct/100))), nil
+	case isPhasePace(value):
+		pace, err := ParseWeeklyPace(value)
+		if err != nil {
+			return 0, err
+		}
+		return maintenance + PaceCalorieDelta(pace), nil
+	default:
+		offset, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid calorie strategy %q (expected kcal, maintenance, -500, +10%%, or -0.5kg/week)", strategy)
+		}
+		if maintenance+offset <= 0 {
+			return 0, fmt.Errorf("calorie strategy %q leaves no calories", strategy)
+		}
+		return maintenance + offset, nil
+	}
+}
+
+// calorieStrategyNeedsMaintenance reports whether strategy is relative to
+// maintenance; plain numbers are fixed calories unless signed.
+func calorieStrategyNeedsMaintenance(strategy string) bool {
+	switch strategy {
+	case "maintenance", "maintain", "cut", "bulk":
+		return true
+	}
+	return strings.HasPrefix(strategy, "+") || strings.HasPrefix(strategy, "-") || strings.HasSuffix(strategy, "%") || isPhasePace(strategy)
+}
+
+// phasePacePattern matches a weekly weight change such as "-0.5kg/week" or
+// "1lb/wk"; unlike profile paces the unit and week suffix are required.
+var phasePacePattern = regexp.MustCompile(`^[+-]?\d+(?:\.\d+)?\s*(?:kg|lb|lbs)\s*/\s*(?:w|wk|week)$`)
+
+func isPhasePace(strategy string) bool {
+	return strategy == "cut" || strategy == "bulk" || phasePacePattern.MatchString(strategy)
+}
+
+// profileMaintenance estimates maintenance with Mifflin-St Jeor from the
+// profile and the latest body measurement on or before date.
+func profileMaintenance(db *sql.DB, date string) (int, error) {
+	hint := fmt.Errorf("relative calorie strategies need --maintenance-calories or a complete profile (kcal profile set) and a body measurement")
+	profile, err := GetProfile(db)
+	if err != nil {
+		return 0, err
+	}
+	latest, err := LatestBodyMeasurement(db, date)
+	if err != nil {
+		return 0, err
+	}
+	if profile == nil || latest == nil {
+		return 0, hint
+	}
+	asOf, _ := time.ParseInLocation("2006-01-02", date, time.Local)
+	estimates, err := EstimateMaintenance(*profile, latest.WeightKg, latest.BodyFatPct, asOf)
+	if err != nil {
+		return 0, hint
+	}
+	for _, e := range estimates {
+		if e.Formula == FormulaMifflinStJeor && e.Available {
+			return e.TDEE, nil
+		}
+	}
+	return 0, hint
+}
+
+func ListPhases(db *sql.DB) ([]model.Phase, error) {
+	rows, err := db.Query(`
+SELECT id, name, start_date, duration_days, calorie_strategy, maintenance_calories, calories, protein_spec, carbs_spec, fat_spec, created_at
+FROM phases
+ORDER BY start_date ASC
+`)
+	if err != nil {
+		return nil, fmt.Errorf("list phases: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]model.Phase, 0)
+	for rows.Next() {
+		var p model.Phase
+		var maintenance sql.NullInt64
+		var created string
+		if err := rows.Scan(&p.ID, &p.Name, &p.StartDate, &p.DurationDays, &p.CalorieStrategy, &maintenance, &p.Calories, &p.ProteinSpec, &p.CarbsSpec, &p.FatSpec, &created); err != nil {
+			return nil, fmt.Errorf("scan phase: %w", err)
+		}
+		if maintenance.Valid {
+			v := int(maintenance.Int64)
+			p.MaintenanceCalories = &v
+		}
+		p.CreatedAt, _ = time.Parse(time.RFC3339, created)
+		items = append(items, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate phases: %w", err)
+	}
+	return items, nil
+}
+
+// DeletePhase removes a phase together with the goal version it wrote.
+func DeletePhase(db *sql.DB, id int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin delete phase tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var goalID sql.NullInt64
+	if err := tx.QueryRow(`SELECT goal_id FROM phases WHERE id = ?`, id).Scan(&goalID); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("phase %d not found", id)
+		}
+		return fmt.Errorf("lookup phase %d: %w", id, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM phases WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("delete phase %d: %w", id, err)
+	}
+	if goalID.Valid {
+		if _, err := tx.Exec(`DELETE FROM goals WHERE id = ?`, goalID.Int64); err != nil {
+			return fmt.Errorf("delete goal for phase %d: %w", id, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit delete phase: %w", err)
+	}
+	return nil
+}
+
+// PhaseForDate returns the phase covering date (YYYY-MM-DD, default today), or
+// nil when no phase does.
+func PhaseForDate(db *sql.DB, date string) (*PhaseStatus, error) {
+	date, err := normalizeGoalDate(date)
+	if err != nil {
+		return nil, err
+	}
+	phases, err := ListPhases(db)
+	if err != nil {
+		return nil, err
+	}
+	return phaseStatusAt(phases, date), nil
+}
+
+func phaseStatusAt(phases []model.Phase, date string) *PhaseStatus {
+	day, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return nil
+	}
+	for _, p := range phases {
+		end := PhaseEndDate(p)
+		if date < p.StartDate || date > end {
+			continue
+		}
+		start, _ := time.ParseInLocation("2006-01-02", p.StartDate, time.Local)
+		dayIndex := int(math.Round(day.Sub(start).Hours() / 24))
+		return &PhaseStatus{
+			ID:         p.ID,
+			Name:       p.Name,
+			StartDate:  p.StartDate,
+			EndDate:    end,
+			Week:       dayIndex/7 + 1,
+			TotalWeeks: (p.DurationDays + 6) / 7,
+			Day:        dayIndex + 1,
+			TotalDays:  p.DurationDays,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package service_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/service"
+)
+
+func TestPhasesWriteGoalsAndReportWeek(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	cut, err := service.AddPhase(db, service.PhaseInput{
+		Name: "cut", StartDate: "2026-03-02", DurationDays: 84,
+		CalorieStrategy: "-500", MaintenanceCalories: 2600,
+		Protein: "180", Carbs: "remainder", Fat: "25%",
+	})
+	if err != nil {
+		t.Fatalf("add cut: %v", err)
+	}
+	if cut.Calories != 2100 {
+		t.Fatalf("expected 2100 kcal cut, got %d", cut.Calories)
+	}
+	diet, err := service.AddPhase(db, service.PhaseInput{
+		Name: "maintenance", DurationDays: 14, CalorieStrategy: "maintenance", MaintenanceCalories: 2600,
+		Protein: "160", Carbs: "300", Fat: "80",
+	})
+	if err != nil {
+		t.Fatalf("add maintenance phase: %v", err)
+	}
+	if diet.StartDate != "2026-05-25" || diet.Calories != 2600 {
+		t.Fatalf("expected maintenance phase to follow the cut, got %+v", diet)
+	}
+	if _, err := service.AddPhase(db, service.PhaseInput{
+		Name: "overlap", StartDate: "2026-06-01", DurationDays: 7, CalorieStrategy: "2000",
+		Protein: "150", Carbs: "200", Fat: "70",
+	}); err == nil || !strings.Contains(err.Error(), "overlaps") {
+		t.Fatalf("expected overlap error, got %v", err)
+	}
+	if _, err := service.AddPhase(db, service.PhaseInput{
+		Name: "bulk", StartDate: "2026-07-01", DurationDays: 7, CalorieStrategy: "+10%",
+		Protein: "150", Carbs: "200", Fat: "70",
+	}); err == nil || !strings.Contains(err.Error(), "maintenance") {
+		t.Fatalf("expected missing maintenance error, got %v", err)
+	}
+
+	goal, err := service.ResolveGoalForDate(db, "2026-03-20")
+	if err != nil {
+		t.Fatalf("resolve goal: %v", err)
+	}
+	if goal == nil || goal.Calories != 2100 || goal.ProteinG != 180 {
+		t.Fatalf("expected cut goal, got %+v", goal)
+	}
+	if fat := 2100 * 0.25 / 9; goal.FatG < fat-0.1 || goal.FatG > fat+0.1 {
+		t.Fatalf("expected fat as 25%% of calories, got %.1f", goal.FatG)
+	}
+
+	status, err := service.PhaseForDate(db, "2026-03-20")
+	if err != nil {
+		t.Fatalf("phase for date: %v", err)
+	}
+	if status == nil || status.Name != "cut" || status.Week != 3 || status.TotalWeeks != 12 || status.Day != 19 {
+		t.Fatalf("expected cut week 3 of 12, got %+v", status)
+	}
+
+	from := time.Date(2026, 5, 24, 0, 0, 0, 0, time.Local)
+	if _, err := service.CreateEntry(db, service.CreateEntryInput{Name: "Meal", Calories: 2000, ProteinG: 150, CarbsG: 200, FatG: 60, Category: "dinner", Consumed: from.Add(19 * time.Hour), SourceType: "manual"}); err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+	report, err := service.AnalyticsRange(db, from, from.AddDate(0, 0, 1), 0.10)
+	if err != nil {
+		t.Fatalf("analytics: %v", err)
+	}
+	if report.Phase == nil || report.Phase.Name != "maintenance" || report.Phase.Week != 1 {
+		t.Fatalf("expected maintenance phase at range end, got %+v", report.Phase)
+	}
+	if len(report.Days) != 1 || report.Days[0].Phase != "cut" || report.Days[0].PhaseWeek != 12 {
+		t.Fatalf("expected logged day in cut week 12, got %+v", report.Days)
+	}
+
+	if err := service.DeletePhase(db, diet.ID); err != nil {
+		t.Fatalf("delete phase: %v", err)
+	}
+	goal, err = service.ResolveGoalForDate(db, "2026-05-30")
+	if err != nil {
+		t.Fatalf("resolve goal after delete: %v", err)
+	}
+	if goal == nil || goal.Calories != 2100 {
+		t.Fatalf("expected cut goal to apply after deleting the next phase, got %+v", goal)
+	}
+}
+
+func TestAddPhaseRefusesExistingGoal(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	if err := service.SetGoal(db, service.SetGoalInput{Calories: 2300, ProteinG: 150, CarbsG: 250, FatG: 70, EffectiveDate: "2026-03-02"}); err != nil {
+		t.Fatalf("set goal: %v", err)
+	}
+	if _, err := service.AddPhase(db, service.PhaseInput{
+		Name: "cut", StartDate: "2026-03-02", DurationDays: 28, CalorieStrategy: "2000",
+		Protein: "180", Carbs: "200", Fat: "60",
+	}); err == nil || !strings.Contains(err.Error(), "already starts on 2026-03-02") {
+		t.Fatalf("expected existing goal error, got %v", err)
+	}
+	phases, err := service.ListPhases(db)
+	if err != nil {
+		t.Fatalf("list phases: %v", err)
+	}
+	if len(phases) != 0 {
+		t.Fatalf("expected no phase to be recorded, got %+v", phases)
+	}
+	goal, err := service.ResolveGoalForDate(db, "2026-03-02")
+	if err != nil {
+		t.Fatalf("resolve goal: %v", err)
+	}
+	if goal == nil || goal.Calories != 2300 {
+		t.Fatalf("expected user goal to be kept, got %+v", goal)
+	}
+
+	phase, err := service.AddPhase(db, service.PhaseInput{
+		Name: "cut", StartDate: "2026-03-09", DurationDays: 28, CalorieStrategy: "2000",
+		Protein: "180", Carbs: "200", Fat: "60",
+	})
+	if err != nil {
+		t.Fatalf("add phase: %v", err)
+	}
+	if err := service.DeletePhase(db, phase.ID); err != nil {
+		t.Fatalf("delete phase: %v", err)
+	}
+	history, err := service.GoalHistory(db)
+	if err != nil {
+		t.Fatalf("goal history: %v", err)
+	}
+	if len(history) != 1 || history[0].EffectiveDate != "2026-03-02" {
+		t.Fatalf("expected only the user goal to remain, got %+v", history)
+	}
+}
+
+func TestResolveCalorieStrategyPace(t *testing.T) {
+	t.Parallel()
+
+	for strategy, want := range map[string]int{
+		"-0.5kg/week": 2050,
+		"+1lb/wk":     3099,
+		"cut":         2100,
+		"-500":        2100,
+		"+10%":        2860,
+		"2200":        2200,
+	} {
+		got, err := service.ResolveCalorieStrategy(strategy, 2600)
+		if err != nil {
+			t.Fatalf("resolve %q: %v", strategy, err)
+		}
+		if got != want {
+			t.Fatalf("resolve %q: expected %d, got %d", strategy, want, got)
+		}
+	}
+	for _, strategy := range []string{"lean", "bulk up", "-0.5kg", "1l", "0.5kg/month"} {
+		if _, err := service.ResolveCalorieStrategy(strategy, 2600); err == nil || !strings.Contains(err.Error(), "invalid calorie strategy") {
+			t.Fatalf("expected %q to be rejected, got %v", strategy, err)
+		}
+	}
+}
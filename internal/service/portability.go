@@ -38,6 +38,23 @@ type ExportRecipeIngredient struct {
 	FatG       float64 `json:"fat_g"`
 }
 
+type ExportGoalSchedule struct {
+	GoalEffectiveDate string `json:"goal_effective_date"`
+	GoalScheduleEntry
+}
+
+// ExportBodyMeasurement carries derived metrics for readers of the export;
+// import ignores them.
+type ExportBodyMeasurement struct {
+	model.BodyMeasurement
+	Metrics *BodyMetrics `json:"metrics,omitempty"`
+}
+
+type ExportDayType struct {
+	Date    string `json:"date"`
+	DayType string `json:"day_type"`
+}
+
 type ExportSavedFood struct {
 	Name            string         `json:"name"`
 	NameNorm        string         `json:"name_norm"`
@@ -63,6 +80,20 @@ type ExportSavedFood struct {
 	ArchivedAt      string         `json:"archived_at,omitempty"`
 }
 
+type ExportSavedExercise struct {
+	Name               string  `json:"name"`
+	NameNorm           string  `json:"name_norm"`
+	ExerciseType       string  `json:"exercise_type"`
+	DefaultDurationMin *int    `json:"default_duration_min,omitempty"`
+	DistanceUnit       string  `json:"distance_unit,omitempty"`
+	CalorieFormula     string  `json:"calorie_formula"`
+	CalorieValue       float64 `json:"calorie_value"`
+	Intensity          string  `json:"intensity,omitempty"`
+	Notes              string  `json:"notes"`
+	UsageCount         int     `json:"usage_count"`
+	LastUsedAt         string  `json:"last_used_at,omitempty"`
+}
+
 type ExportSavedMeal struct {
 	Name            string         `json:"name"`
 	NameNorm        string         `json:"name_norm"`
@@ -102,13 +133,18 @@ type ExportData struct {
 	Categories          []string                   `json:"categories"`
 	Entries             []ExportEntry              `json:"entries"`
 	Goals               []model.Goal               `json:"goals"`
-	BodyMeasurements    []model.BodyMeasurement    `json:"body_measurements"`
+	GoalSchedules       []ExportGoalSchedule       `json:"goal_schedules"`
+	DayTypes            []ExportDayType            `json:"day_types"`
+	BodyMeasurements    []ExportBodyMeasurement    `json:"body_measurements"`
 	BodyGoals           []model.BodyGoal           `json:"body_goals"`
+	Profile             *model.Profile             `json:"profile,omitempty"`
+	Phases              []model.Phase              `json:"phases,omitempty"`
 	Recipes             []model.Recipe             `json:"recipes"`
 	RecipeIngredients   []ExportRecipeIngredient   `json:"recipe_ingredients"`
 	SavedFoods          []ExportSavedFood          `json:"saved_foods"`
 	SavedMeals          []ExportSavedMeal          `json:"saved_meals"`
 	SavedMealComponents []ExportSavedMealComponent `json:"saved_meal_components"`
+	SavedExercises      []ExportSavedExercise      `json:"saved_exercises,omitempty"`
 }
 
 type ImportMode string
@@ -175,14 +211,14 @@ ORDER BY e.consumed_at ASC`)
 	}
 	_ = entryRows.Close()
 
-	goalRows, err := db.Query(`SELECT id, calories, protein_g, carbs_g, fat_g, effective_date, created_at FROM goals ORDER BY effective_date ASC`)
+	goalRows, err := db.Query(`SELECT id, calories, protein_g, carbs_g, fat_g, IFNULL(nutrient_targets_json,''), IFNULL(macro_rules_json,''), effective_date, created_at FROM goals ORDER BY effective_date ASC`)
 	if err != nil {
 		return nil, fmt.Errorf("export goals: %w", err)
 	}
 	for goalRows.Next() {
 		var g model.Goal
 		var created string
-		if err := goalRows.Scan(&g.ID, &g.Calories, &g.ProteinG, &g.CarbsG, &g.FatG, &g.EffectiveDate, &created); err != nil {
+		if err := goalRows.Scan(&g.ID, &g.Calories, &g.ProteinG, &g.CarbsG, &g.FatG, &g.NutrientTargets, &g.MacroRules, &g.EffectiveDate, &created); err != nil {
 			_ = goalRows.Close()
 			return nil, fmt.Errorf("scan export goal: %w", err)
 		}
@@ -191,24 +227,50 @@ ORDER BY e.consumed_at ASC`)
 	}
 	_ = goalRows.Close()
 
-	bodyRows, err := db.Query(`SELECT id, measured_at, weight_kg, body_fat_pct, IFNULL(notes,'') FROM body_measurements ORDER BY measured_at ASC`)
+	scheduleRows, err := db.Query(`
+SELECT g.effective_date, s.day_key, s.calories, s.protein_g, s.carbs_g, s.fat_g
+FROM goal_schedules s
+JOIN goals g ON g.id = s.goal_id
+ORDER BY g.effective_date ASC, s.day_key ASC
+`)
+	if err != nil {
+		return nil, fmt.Errorf("export goal schedules: %w", err)
+	}
+	for scheduleRows.Next() {
+		var gs ExportGoalSchedule
+		if err := scheduleRows.Scan(&gs.GoalEffectiveDate, &gs.DayKey, &gs.Calories, &gs.ProteinG, &gs.CarbsG, &gs.FatG); err != nil {
+			_ = scheduleRows.Close()
+			return nil, fmt.Errorf("scan export goal schedule: %w", err)
+		}
+		out.GoalSchedules = append(out.GoalSchedules, gs)
+	}
+	_ = scheduleRows.Close()
+
+	dayTypeRows, err := db.Query(`SELECT date, day_type FROM day_types ORDER BY date ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("export day types: %w", err)
+	}
+	for dayTypeRows.Next() {
+		var dt ExportDayType
+		if err := dayTypeRows.Scan(&dt.Date, &dt.DayType); err != nil {
+			_ = dayTypeRows.Close()
+			return nil, fmt.Errorf("scan export day type: %w", err)
+		}
+		out.DayTypes = append(out.DayTypes, dt)
+	}
+	_ = dayTypeRows.Close()
+
+	bodyRows, err := db.Query(`SELECT ` + bodyMeasurementColumns + ` FROM body_measurements ORDER BY measured_at ASC`)
 	if err != nil {
 		return nil, fmt.Errorf("export body measurements: %w", err)
 	}
 	for bodyRows.Next() {
-		var b model.BodyMeasurement
-		var measured string
-		var bf sql.NullFloat64
-		if err := bodyRows.Scan(&b.ID, &measured, &b.WeightKg, &bf, &b.Notes); err != nil {
+		b, err := scanBodyMeasurement(bodyRows.Scan)
+		if err != nil {
 			_ = bodyRows.Close()
 			return nil, fmt.Errorf("scan export body measurement: %w", err)
 		}
-		b.MeasuredAt, _ = time.Parse(time.RFC3339, measured)
-		if bf.Valid {
-			v := bf.Float64
-			b.BodyFatPct = &v
-		}
-		out.BodyMeasurements = append(out.BodyMeasurements, b)
+		out.BodyMeasurements = append(out.BodyMeasurements, ExportBodyMeasurement{BodyMeasurement: *b})
 	}
 	_ = bodyRows.Close()
 
@@ -233,6 +295,21 @@ ORDER BY e.consumed_at ASC`)
 	}
 	_ = bodyGoalRows.Close()
 
+	profile, err := GetProfile(db)
+	if err != nil {
+		return nil, fmt.Errorf("export profile: %w", err)
+	}
+	out.Profile = profile
+	for i := range out.BodyMeasurements {
+		out.BodyMeasurements[i].Metrics = MeasurementMetrics(profile, out.BodyMeasurements[i].BodyMeasurement)
+	}
+
+	phases, err := ListPhases(db)
+	if err != nil {
+		return nil, fmt.Errorf("export phases: %w", err)
+	}
+	out.Phases = phases
+
 	recipeRows, err := db.Query(`SELECT id, name, calories_total, protein_total_g, carbs_total_g, fat_total_g, servings, IFNULL(notes,''), created_at, updated_at FROM recipes ORDER BY name ASC`)
 	if err != nil {
 		return nil, fmt.Errorf("export recipes: %w", err)
@@ -358,6 +435,28 @@ ORDER BY sm.name_norm ASC, c.position ASC, c.id ASC`)
 	}
 	_ = savedMealComponentRows.Close()
 
+	savedExerciseRows, err := db.Query(`
+SELECT name, name_norm, exercise_type, default_duration_min, distance_unit, calorie_formula, calorie_value, intensity, notes, usage_count, IFNULL(last_used_at,'')
+FROM saved_exercises
+ORDER BY name_norm ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("export saved exercises: %w", err)
+	}
+	for savedExerciseRows.Next() {
+		var item ExportSavedExercise
+		var duration sql.NullInt64
+		if err := savedExerciseRows.Scan(&item.Name, &item.NameNorm, &item.ExerciseType, &duration, &item.DistanceUnit, &item.CalorieFormula, &item.CalorieValue, &item.Intensity, &item.Notes, &item.UsageCount, &item.LastUsedAt); err != nil {
+			_ = savedExerciseRows.Close()
+			return nil, fmt.Errorf("scan export saved exercise: %w", err)
+		}
+		if duration.Valid {
+			v := int(duration.Int64)
+			item.DefaultDurationMin = &v
+		}
+		out.SavedExercises = append(out.SavedExercises, item)
+	}
+	_ = savedExerciseRows.Close()
+
 	return out, nil
 }
 
@@ -398,17 +497,38 @@ func ImportDataSnapshotWithOptions(db *sql.DB, data *ExportData, opts ImportOpti
 			report.Inserted++
 			continue
 		}
-		if _, err := tx.Exec(`INSERT OR IGNORE INTO goals(calories, protein_g, carbs_g, fat_g, effective_date) VALUES(?, ?, ?, ?, ?)`, g.Calories, g.ProteinG, g.CarbsG, g.FatG, g.EffectiveDate); err != nil {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO goals(calories, protein_g, carbs_g, fat_g, nutrient_targets_json, macro_rules_json, effective_date) VALUES(?, ?, ?, ?, ?, ?, ?)`, g.Calories, g.ProteinG, g.CarbsG, g.FatG, g.NutrientTargets, g.MacroRules, g.EffectiveDate); err != nil {
 			return report, fmt.Errorf("import goal %q: %w", g.EffectiveDate, err)
 		}
 	}
+	for _, gs := range data.GoalSchedules {
+		if opts.DryRun {
+			report.Inserted++
+			continue
+		}
+		if _, err := tx.Exec(`
+INSERT OR IGNORE INTO goal_schedules(goal_id, day_key, calories, protein_g, carbs_g, fat_g)
+SELECT id, ?, ?, ?, ?, ? FROM goals WHERE effective_date = ?
+`, gs.DayKey, gs.Calories, gs.ProteinG, gs.CarbsG, gs.FatG, gs.GoalEffectiveDate); err != nil {
+			return report, fmt.Errorf("import goal schedule %s %s: %w", gs.GoalEffectiveDate, gs.DayKey, err)
+		}
+	}
+	for _, dt := range data.DayTypes {
+		if opts.DryRun {
+			report.Inserted++
+			continue
+		}
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO day_types(date, day_type) VALUES(?, ?)`, dt.Date, dt.DayType); err != nil {
+			return report, fmt.Errorf("import day type %s: %w", dt.Date, err)
+		}
+	}
 
 	for _, b := range data.BodyMeasurements {
 		if opts.DryRun {
 			report.Inserted++
 			continue
 		}
-		if _, err := tx.Exec(`INSERT INTO body_measurements(measured_at, weight_kg, body_fat_pct, notes) VALUES(?, ?, ?, ?)`, b.MeasuredAt.Format(time.RFC3339), b.WeightKg, b.BodyFatPct, b.Notes); err != nil {
+		if _, err := tx.Exec(`INSERT INTO body_measurements(measured_at, weight_kg, body_fat_pct, notes, waist_cm, hip_cm, neck_cm, chest_cm, arm_cm, thigh_cm) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, b.MeasuredAt.Format(time.RFC3339), b.WeightKg, b.BodyFatPct, b.Notes, b.WaistCm, b.HipCm, b.NeckCm, b.ChestCm, b.ArmCm, b.ThighCm); err != nil {
 			return report, fmt.Errorf("import body measurement %s: %w", b.MeasuredAt.Format(time.RFC3339), err)
 		}
 	}
@@ -421,6 +541,25 @@ func ImportDataSnapshotWithOptions(db *sql.DB, data *ExportData, opts ImportOpti
 			return report, fmt.Errorf("import body goal %q: %w", g.EffectiveDate, err)
 		}
 	}
+	if p := data.Profile; p != nil {
+		if opts.DryRun {
+			report.Inserted++
+		} else if _, err := tx.Exec(`INSERT OR IGNORE INTO user_profile(id, sex, birth_date, height_cm, activity_level) VALUES(1, ?, ?, ?, ?)`, p.Sex, p.BirthDate, p.HeightCm, p.ActivityLevel); err != nil {
+			return report, fmt.Errorf("import profile: %w", err)
+		}
+	}
+	for _, p := range data.Phases {
+		if opts.DryRun {
+			report.Inserted++
+			continue
+		}
+		if _, err := tx.Exec(`
+INSERT OR IGNORE INTO phases(name, start_date, duration_days, calorie_strategy, maintenance_calories, calories, protein_spec, carbs_spec, fat_spec, goal_id)
+VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, (SELECT id FROM goals WHERE effective_date = ?))
+`, p.Name, p.StartDate, p.DurationDays, p.CalorieStrategy, p.MaintenanceCalories, p.Calories, p.ProteinSpec, p.CarbsSpec, p.FatSpec, p.StartDate); err != nil {
+			return report, fmt.Errorf("import phase %q: %w", p.Name, err)
+		}
+	}
 	for _, r := range data.Recipes {
 		if opts.DryRun {
 			report.Inserted++
@@ -508,6 +647,53 @@ VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		report.Inserted++
 	}
 
+	for _, se := range data.SavedExercises {
+		if strings.TrimSpace(se.Name) == "" {
+			continue
+		}
+		if opts.DryRun {
+			report.Inserted++
+			continue
+		}
+		nameNorm := normalizeName(se.Name)
+		if strings.TrimSpace(se.NameNorm) != "" {
+			nameNorm = normalizeName(se.NameNorm)
+		}
+		var existingID int64
+		err := tx.QueryRow(`SELECT id FROM saved_exercises WHERE name_norm = ?`, nameNorm).Scan(&existingID)
+		if err != nil && err != sql.ErrNoRows {
+			return report, fmt.Errorf("find saved exercise %q: %w", se.Name, err)
+		}
+		lastUsed := nullableTimeString(strings.TrimSpace(se.LastUsedAt))
+		if err == nil && existingID > 0 {
+			switch mode {
+			case ImportModeFail:
+				report.Conflicts++
+				return report, fmt.Errorf("import conflict for saved exercise %q", se.Name)
+			case ImportModeSkip:
+				report.Skipped++
+				continue
+			case ImportModeMerge, ImportModeReplace:
+				if _, err := tx.Exec(`
+UPDATE saved_exercises
+SET name=?, name_norm=?, exercise_type=?, default_duration_min=?, distance_unit=?, calorie_formula=?, calorie_value=?, intensity=?, notes=?, usage_count=?, last_used_at=?, updated_at=CURRENT_TIMESTAMP
+WHERE id = ?
+`, se.Name, nameNorm, se.ExerciseType, se.DefaultDurationMin, se.DistanceUnit, se.CalorieFormula, se.CalorieValue, se.Intensity, se.Notes, se.UsageCount, lastUsed, existingID); err != nil {
+					return report, fmt.Errorf("update saved exercise %q: %w", se.Name, err)
+				}
+				report.Updated++
+				continue
+			}
+		}
+		if _, err := tx.Exec(`
+INSERT INTO saved_exercises(name, name_norm, exercise_type, default_duration_min, distance_unit, calorie_formula, calorie_value, intensity, notes, usage_count, last_used_at)
+VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`, se.Name, nameNorm, se.ExerciseType, se.DefaultDurationMin, se.DistanceUnit, se.CalorieFormula, se.CalorieValue, se.Intensity, se.Notes, se.UsageCount, lastUsed); err != nil {
+			return report, fmt.Errorf("insert saved exercise %q: %w", se.Name, err)
+		}
+		report.Inserted++
+	}
+
 	componentsByMeal := map[string][]ExportSavedMealComponent{}
 	for _, c := range data.SavedMealComponents {
 		key := normalizeName(c.MealName)
@@ -730,12 +916,17 @@ func clearUserData(tx *sql.Tx) error {
 		`DELETE FROM saved_meal_components`,
 		`DELETE FROM saved_meals`,
 		`DELETE FROM saved_foods`,
+		`DELETE FROM saved_exercises`,
 		`DELETE FROM recipe_ingredients`,
 		`DELETE FROM entries`,
 		`DELETE FROM recipes`,
+		`DELETE FROM goal_schedules`,
+		`DELETE FROM day_types`,
 		`DELETE FROM goals`,
 		`DELETE FROM body_measurements`,
 		`DELETE FROM body_goals`,
+		`DELETE FROM user_profile`,
+		`DELETE FROM phases`,
 		`DELETE FROM categories WHERE is_default = 0`,
 	}
 	for _, s := range stmts {
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/model"
+)
+
+const (
+	SexMale   = "male"
+	SexFemale = "female"
+
+	FormulaMifflinStJeor  = "mifflin"
+	FormulaHarrisBenedict = "harris"
+	FormulaKatchMcArdle   = "katch"
+
+	cmPerInch = 2.54
+)
+
+// ActivityMultipliers maps activity levels to the factor applied to BMR.
+var ActivityMultipliers = map[string]float64{
+	"sedentary":   1.2,
+	"light":       1.375,
+	"moderate":    1.55,
+	"active":      1.725,
+	"very_active": 1.9,
+}
+
+// Formulas lists the supported BMR formulas in display order.
+var Formulas = []string{FormulaMifflinStJeor, FormulaHarrisBenedict, FormulaKatchMcArdle}
+
+var formulaNames = map[string]string{
+	FormulaMifflinStJeor:  "Mifflin-St Jeor",
+	FormulaHarrisBenedict: "Harris-Benedict",
+	FormulaKatchMcArdle:   "Katch-McArdle",
+}
+
+var weeklyPacePattern = regexp.MustCompile(`^([+-]?\d+(?:\.\d+)?)\s*(kg|lb|lbs)?(?:\s*/\s*(?:w|wk|week))?$`)
+
+// ProfileInput updates the stored profile; empty fields keep their current
+// value.
+type ProfileInput struct {
+	Sex           string
+	BirthDate     string
+	Height        float64
+	HeightUnit    string
+	ActivityLevel string
+	// ClearHeight removes the stored height; Height is ignored.
+	ClearHeight bool
+}
+
+type MaintenanceEstimate struct {
+	Formula   string  `json:"formula"`
+	Name      string  `json:"name"`
+	BMR       int     `json:"bmr,omitempty"`
+	TDEE      int     `json:"tdee,omitempty"`
+	Available bool    `json:"available"`
+	Note      string  `json:"note,omitempty"`
+	LeanMass  float64 `json:"lean_mass_kg,omitempty"`
+}
+
+func GetProfile(db *sql.DB) (*model.Profile, error) {
+	var p model.Profile
+	var height sql.NullFloat64
+	var updated string
+	err := db.QueryRow(`
+SELECT sex, birth_date, height_cm, activity_level, updated_at
+FROM user_profile
+WHERE id = 1
+`).Scan(&p.Sex, &p.BirthDate, &height, &p.ActivityLevel, &updated)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get profile: %w", err)
+	}
+	if height.Valid {
+		v := height.Float64
+		p.HeightCm = &v
+	}
+	p.UpdatedAt, _ = time.Parse(time.RFC3339, updated)
+	return &p, nil
+}
+
+func SetProfile(db *sql.DB, in ProfileInput) (*model.Profile, error) {
+	current, err := GetProfile(db)
+	if err != nil {
+		return nil, err
+	}
+	p := model.Profile{}
+	if current != nil {
+		p = *current
+	}
+
+	if strings.TrimSpace(in.Sex) != "" {
+		sex, err := normalizeSex(in.Sex)
+		if err != nil {
+			return nil, err
+		}
+		p.Sex = sex
+	}
+	if strings.TrimSpace(in.BirthDate) != "" {
+		birth, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(in.BirthDate), time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid birth date %q (expected YYYY-MM-DD)", in.BirthDate)
+		}
+		if birth.After(time.Now()) {
+			return nil, fmt.Errorf("birth date cannot be in the future")
+		}
+		p.BirthDate = birth.Format("2006-01-02")
+	}
+	if in.ClearHeight {
+		p.HeightCm = nil
+	} else if in.Height != 0 {
+		heightCm, err := convertHeightToCm(in.Height, in.HeightUnit)
+		if err != nil {
+			return nil, err
+		}
+		p.HeightCm = &heightCm
+	}
+	if strings.TrimSpace(in.ActivityLevel) != "" {
+		level := normalizeName(strings.ReplaceAll(in.ActivityLevel, "-", "_"))
+		if _, ok := ActivityMultipliers[level]; !ok {
+			return nil, fmt.Errorf("invalid activity level %q (use sedentary, light, moderate, active, or very_active)", in.ActivityLevel)
+		}
+		p.ActivityLevel = level
+	}
+
+	if _, err := db.Exec(`
+INSERT INTO user_profile(id, sex, birth_date, height_cm, activity_level, updated_at)
+VALUES(1, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+ON CONFLICT(id) DO UPDATE SET
+  sex = excluded.sex,
+  birth_date = excluded.birth_date,
+  height_cm = excluded.height_cm,
+  activity_level = excluded.activity_level,
+  updated_at = excluded.updated_at
+`, p.Sex, p.BirthDate, p.HeightCm, p.ActivityLevel); err != nil {
+		return nil, fmt.Errorf("set profile: %w", err)
+	}
+	return GetProfile(db)
+}
+
+func normalizeSex(value string) (string, error) {
+	switch normalizeName(value) {
+	case "m", SexMale:
+		return SexMale, nil
+	case "f", SexFemale:
+		return SexFemale, nil
+	default:
+		return "", fmt.Errorf("invalid sex %q (use male or female)", value)
+	}
+}
+
+func convertHeightToCm(value float64, unit string) (float64, error) {
+	if value <= 0 {
+		return 0, fmt.Errorf("height must be > 0")
+	}
+	switch normalizeName(unit) {
+	case "", "cm":
+		return value, nil
+	case "in":
+		return value * cmPerInch, nil
+	default:
+		return 0, fmt.Errorf("invalid height unit %q (use cm or in)", unit)
+	}
+}
+
+// AgeOn returns whole years between the profile birth date and date.
+func AgeOn(birthDate string, date time.Time) (int, error) {
+	birth, err := time.ParseInLocation("2006-01-02", birthDate, time.Local)
+	if err != nil {
+		return 0, fmt.Errorf("invalid birth date %q (expected YYYY-MM-DD)", birthDate)
+	}
+	age := date.Year() - birth.Year()
+	if date.Month() < birth.Month() || (date.Month() == birth.Month() && date.Day() < birth.Day()) {
+		age--
+	}
+	return age, nil
+}
+
+// EstimateMaintenance computes BMR and activity-adjusted maintenance with each
+// supported formula. Formulas missing an input are returned unavailable with a
+// note instead of failing the whole comparison.
+func EstimateMaintenance(p model.Profile, weightKg float64, bodyFatPct *float64, date time.Time) ([]MaintenanceEstimate, error) {
+	multiplier, ok := ActivityMultipliers[p.ActivityLevel]
+	if !ok {
+		return nil, fmt.Errorf("profile activity level is not set (kcal profile set --activity)")
+	}
+	if weightKg <= 0 {
+		return nil, fmt.Errorf("weight must be > 0")
+	}
+
+	var missing []string
+	if p.Sex == "" {
+		missing = append(missing, "sex")
+	}
+	if p.BirthDate == "" {
+		missing = append(missing, "birth date")
+	}
+	if p.HeightCm == nil {
+		missing = append(missing, "height")
+	}
+	age := 0
+	if p.BirthDate != "" {
+		var err error
+		age, err = AgeOn(p.BirthDate, date)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]MaintenanceEstimate, 0, len(Formulas))
+	for _, formula := range Formulas {
+		est := MaintenanceEstimate{Formula: formula, Name: formulaNames[formula]}
+		var bmr float64
+		switch formula {
+		case FormulaMifflinStJeor, FormulaHarrisBenedict:
+			if len(missing) > 0 {
+				est.Note = "profile is missing " + strings.Join(missing, ", ")
+				out = append(out, est)
+				continue
+			}
+			bmr = demographicBMR(formula, p.Sex, weightKg, *p.HeightCm, float64(age))
+		case FormulaKatchMcArdle:
+			if bodyFatPct == nil {
+				est.Note = "needs a body-fat percentage in body measurements"
+				out = append(out, est)
+				continue
+			}
+			est.LeanMass = math.Round(leanMassKg(weightKg, *bodyFatPct)*10) / 10
+			bmr = 370 + 21.6*leanMassKg(weightKg, *bodyFatPct)
+		}
+		est.Available = true
+		est.BMR = int(math.Round(bmr))
+		est.TDEE = int(math.Round(bmr * multiplier))
+		out = append(out, est)
+	}
+	return out, nil
+}
+
+func demographicBMR(formula, sex string, weightKg, heightCm, age float64) float64 {
+	if formula == FormulaHarrisBenedict {
+		// Roza and Shizgal (1984) revision.
+		if sex == SexMale {
+			return 88.362 + 13.397*weightKg + 4.799*heightCm - 5.677*age
+		}
+		return 447.593 + 9.247*weightKg + 3.098*heightCm - 4.330*age
+	}
+	bmr := 10*weightKg + 6.25*heightCm - 5*age
+	if sex == SexMale {
+		return bmr + 5
+	}
+	return bmr - 161
+}
+
+// NormalizeFormula accepts formula names with or without their full spelling.
+func NormalizeFormula(value string) (string, error) {
+	switch normalizeName(value) {
+	case "", "mifflin", "mifflin-st-jeor", "msj":
+		return FormulaMifflinStJeor, nil
+	case "harris", "harris-benedict", "hb":
+		return FormulaHarrisBenedict, nil
+	case "katch", "katch-mcardle", "km":
+		return FormulaKatchMcArdle, nil
+	default:
+		return "", fmt.Errorf("invalid formula %q (use mifflin, harris, or katch)", value)
+	}
+}
+
+// ParseWeeklyPace reads a target weekly weight change such as "-0.5kg",
+// "+1lb/week" or "0" and returns kg per week. The presets "maintain", "cut"
+// and "bulk" map to the changes a 0, -500 and +300 kcal/day balance produce.
+func ParseWeeklyPace(raw string) (float64, error) {
+	value := normalizeName(raw)
+	switch value {
+	case "maintain":
+		return 0, nil
+	case "cut":
+		return -500 * 7 / float64(KcalPerKgBodyWeight), nil
+	case "bulk":
+		return 300 * 7 / float64(KcalPerKgBodyWeight), nil
+	}
+	m := weeklyPacePattern.FindStringSubmatch(value)
+	if m == nil {
+		return 0, fmt.Errorf("invalid pace %q (use maintain, cut, bulk, or a weekly change like -0.5kg or +1lb)", raw)
+	}
+	amount, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid pace %q", raw)
+	}
+	if m[2] == "lb" || m[2] == "lbs" {
+		amount *= kgPerLb
+	}
+	if math.Abs(amount) > 1.5 {
+		return 0, fmt.Errorf("pace %q is outside the supported range of +/-1.5 kg per week", raw)
+	}
+	return amount, nil
+}
+
+// PaceCalorieDelta converts a weekly weight change into a daily calorie
+// adjustment.
+func PaceCalorieDelta(kgPerWeek float64) int {
+	return int(math.Round(kgPerWeek * KcalPerKgBodyWeight / 7))
+}
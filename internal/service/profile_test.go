@@ -0,0 +1,84 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/service"
+)
+
+func TestProfileAndMaintenanceFormulas(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	if p, err := service.GetProfile(db); err != nil || p != nil {
+		t.Fatalf("expected no profile before set, got %+v (%v)", p, err)
+	}
+	if _, err := service.SetProfile(db, service.ProfileInput{Sex: "male", BirthDate: "1996-03-15", Height: 180, HeightUnit: "cm"}); err != nil {
+		t.Fatalf("set profile: %v", err)
+	}
+	p, err := service.SetProfile(db, service.ProfileInput{ActivityLevel: "moderate"})
+	if err != nil {
+		t.Fatalf("update activity: %v", err)
+	}
+	if p.Sex != service.SexMale || p.HeightCm == nil || *p.HeightCm != 180 || p.ActivityLevel != "moderate" {
+		t.Fatalf("expected partial update to keep earlier fields, got %+v", p)
+	}
+	if _, err := service.SetProfile(db, service.ProfileInput{ActivityLevel: "couch"}); err == nil {
+		t.Fatalf("expected invalid activity level to fail")
+	}
+	cleared, err := service.SetProfile(db, service.ProfileInput{ClearHeight: true})
+	if err != nil {
+		t.Fatalf("clear height: %v", err)
+	}
+	if cleared.HeightCm != nil || cleared.Sex != service.SexMale {
+		t.Fatalf("expected height cleared and other fields kept, got %+v", cleared)
+	}
+	if p, err = service.SetProfile(db, service.ProfileInput{Height: 180, HeightUnit: "cm"}); err != nil {
+		t.Fatalf("restore height: %v", err)
+	}
+
+	asOf := time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)
+	estimates, err := service.EstimateMaintenance(*p, 80, nil, asOf)
+	if err != nil {
+		t.Fatalf("estimate maintenance: %v", err)
+	}
+	if estimates[0].Formula != service.FormulaMifflinStJeor || estimates[0].BMR != 1785 || estimates[0].TDEE != 2767 {
+		t.Fatalf("unexpected Mifflin-St Jeor estimate for 29-year-old male: %+v", estimates[0])
+	}
+	if !estimates[1].Available || estimates[1].BMR <= estimates[0].BMR {
+		t.Fatalf("expected Harris-Benedict estimate above Mifflin-St Jeor, got %+v", estimates[1])
+	}
+	if estimates[2].Available || estimates[2].Note == "" {
+		t.Fatalf("expected Katch-McArdle to need body fat, got %+v", estimates[2])
+	}
+	bodyFat := 20.0
+	estimates, err = service.EstimateMaintenance(*p, 80, &bodyFat, asOf)
+	if err != nil {
+		t.Fatalf("estimate maintenance with body fat: %v", err)
+	}
+	if estimates[2].BMR != 1752 || estimates[2].LeanMass != 64 {
+		t.Fatalf("unexpected Katch-McArdle estimate: %+v", estimates[2])
+	}
+}
+
+func TestParseWeeklyPace(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]int{"maintain": 0, "cut": -500, "bulk": 300, "-0.5kg": -550, "+1lb/week": 499, "0.25": 275}
+	for raw, want := range cases {
+		kg, err := service.ParseWeeklyPace(raw)
+		if err != nil {
+			t.Fatalf("parse pace %q: %v", raw, err)
+		}
+		if got := service.PaceCalorieDelta(kg); got != want {
+			t.Fatalf("pace %q: expected %d kcal/day, got %d", raw, want, got)
+		}
+	}
+	for _, raw := range []string{"fast", "-3kg", "1stone"} {
+		if _, err := service.ParseWeeklyPace(raw); err == nil {
+			t.Fatalf("expected pace %q to be rejected", raw)
+		}
+	}
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/model"
+)
+
+const (
+	CalorieFormulaFixed     = "fixed"
+	CalorieFormulaPerMinute = "per_minute"
+	CalorieFormulaMET       = "met"
+)
+
+type CreateSavedExerciseInput struct {
+	Name               string
+	ExerciseType       string
+	DefaultDurationMin *int
+	DistanceUnit       string
+	// Formula is a spec accepted by ParseCalorieFormula.
+	Formula   string
+	Intensity string
+	Notes     string
+}
+
+type ListSavedExercisesFilter struct {
+	Limit int
+	Query string
+}
+
+// LogSavedExerciseInput overrides the template's defaults for one session.
+type LogSavedExerciseInput struct {
+	Identifier  string
+	DurationMin *int
+	Distance    *float64
+	Intensity   string
+	PerformedAt time.Time
+	Notes       string
+}
+
+// SavedExerciseLogResult describes the exercise log written from a template.
+type SavedExerciseLogResult struct {
+	ExerciseLogID int64
+	Calories      int
+	Estimate      *ExerciseEstimate
+}
+
+// ParseCalorieFormula parses fixed:KCAL, per-minute:KCAL or met[:VALUE]. A
+// bare number is a fixed amount; met without a value uses the bundled MET
+// table for the exercise type.
+func ParseCalorieFormula(spec string) (string, float64, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	if spec == "" {
+		return "", 0, fmt.Errorf("calorie formula is required (fixed:KCAL, per-minute:KCAL or met[:VALUE])")
+	}
+	kind, raw, hasValue := strings.Cut(spec, ":")
+	if !hasValue {
+		if v, err := strconv.ParseFloat(kind, 64); err == nil {
+			kind, raw, hasValue = CalorieFormulaFixed, strconv.FormatFloat(v, 'f', -1, 64), true
+		}
+	}
+	switch kind {
+	case CalorieFormulaFixed, "per-minute", CalorieFormulaPerMinute, CalorieFormulaMET:
+	default:
+		return "", 0, fmt.Errorf("invalid calorie formula %q (use fixed:KCAL, per-minute:KCAL or met[:VALUE])", spec)
+	}
+	kind = strings.ReplaceAll(kind, "-", "_")
+	if !hasValue {
+		if kind != CalorieFormulaMET {
+			return "", 0, fmt.Errorf("calorie formula %q needs a value", spec)
+		}
+		return kind, 0, nil
+	}
+	v, err := strconv.ParseFloat(strings.TrimSuffix(raw, "/min"), 64)
+	if err != nil || v <= 0 {
+		return "", 0, fmt.Errorf("invalid calorie formula value in %q (must be > 0)", spec)
+	}
+	return kind, v, nil
+}
+
+// FormatCalorieFormula renders a stored formula in ParseCalorieFormula syntax.
+func FormatCalorieFormula(formula string, value float64) string {
+	v := strconv.FormatFloat(value, 'f', -1, 64)
+	switch formula {
+	case CalorieFormulaPerMinute:
+		return "per-minute:" + v
+	case CalorieFormulaMET:
+		if value == 0 {
+			return "met"
+		}
+		return "met:" + v
+	default:
+		return "fixed:" + v
+	}
+}
+
+func CreateSavedExercise(db *sql.DB, in CreateSavedExerciseInput) (int64, error) {
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return 0, fmt.Errorf("saved exercise name is required")
+	}
+	exerciseType := strings.ToLower(strings.TrimSpace(in.ExerciseType))
+	if exerciseType == "" {
+		return 0, fmt.Errorf("exercise type is required")
+	}
+	if in.DefaultDurationMin != nil && *in.DefaultDurationMin <= 0 {
+		return 0, fmt.Errorf("default duration must be > 0")
+	}
+	unit := strings.ToLower(strings.TrimSpace(in.DistanceUnit))
+	if unit != "" && unit != "km" && unit != "mi" {
+		return 0, fmt.Errorf("invalid distance unit %q (use km or mi)", in.DistanceUnit)
+	}
+	formula, value, err := ParseCalorieFormula(in.Formula)
+	if err != nil {
+		return 0, err
+	}
+	intensity := strings.ToLower(strings.TrimSpace(in.Intensity))
+	if intensity != "" && !slices.Contains(ExerciseIntensities, intensity) {
+		return 0, fmt.Errorf("invalid intensity %q (use light, moderate or vigorous)", in.Intensity)
+	}
+	if formula == CalorieFormulaMET && value == 0 {
+		if _, _, _, err := ExerciseMET(exerciseType, intensity, nil); err != nil {
+			return 0, err
+		}
+	}
+
+	res, err := db.Exec(`
+INSERT INTO saved_exercises(name, name_norm, exercise_type, default_duration_min, distance_unit, calorie_formula, calorie_value, intensity, notes)
+VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?)
+`, name, normalizeName(name), exerciseType, in.DefaultDurationMin, unit, formula, value, intensity, strings.TrimSpace(in.Notes))
+	if err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "unique") {
+			return 0, fmt.Errorf("saved exercise %q already exists", name)
+		}
+		return 0, fmt.Errorf("create saved exercise: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("resolve saved exercise id: %w", err)
+	}
+	return id, nil
+}
+
+func ResolveSavedExercise(db *sql.DB, idOrName string) (*model.SavedExercise, error) {
+	idOrName = strings.TrimSpace(idOrName)
+	if idOrName == "" {
+		return nil, fmt.Errorf("saved exercise identifier is required")
+	}
+	var row *sql.Row
+	if id, err := parseIDLoose(idOrName); err == nil {
+		row = db.QueryRow(savedExerciseSelectBase+` WHERE id = ?`, id)
+	} else {
+		row = db.QueryRow(savedExerciseSelectBase+` WHERE name_norm = ?`, normalizeName(idOrName))
+	}
+	item, err := scanSavedExercise(row.Scan)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("saved exercise %q not found", idOrName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("resolve saved exercise %q: %w", idOrName, err)
+	}
+	return item, nil
+}
+
+func ListSavedExercises(db *sql.DB, f ListSavedExercisesFilter) ([]model.SavedExercise, error) {
+	query := savedExerciseSelectBase + ` WHERE 1=1`
+	args := make([]any, 0, 2)
+	if strings.TrimSpace(f.Query) != "" {
+		query += ` AND name_norm LIKE ?`
+		args = append(args, "%"+normalizeName(f.Query)+"%")
+	}
+	query += ` ORDER BY usage_count DESC, last_used_at DESC, updated_at DESC, name ASC`
+	if f.Limit <= 0 {
+		f.Limit = 100
+	}
+	query += ` LIMIT ?`
+	args = append(args, f.Limit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list saved exercises: %w", err)
+	}
+	defer rows.Close()
+	out := make([]model.SavedExercise, 0)
+	for rows.Next() {
+		item, err := scanSavedExercise(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("scan saved exercise: %w", err)
+		}
+		out = append(out, *item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate saved exercises: %w", err)
+	}
+	return out, nil
+}
+
+// LogSavedExercise writes an exercise log from a template and bumps its usage
+// count. Per-minute and MET formulas need a duration, from the input or the
+// template default.
+func LogSavedExercise(db *sql.DB, in LogSavedExerciseInput) (*SavedExerciseLogResult, error) {
+	item, err := ResolveSavedExercise(db, in.Identifier)
+	if err != nil {
+		return nil, err
+	}
+	if in.PerformedAt.IsZero() {
+		in.PerformedAt = time.Now()
+	}
+	duration := in.DurationMin
+	if duration == nil {
+		duration = item.DefaultDurationMin
+	}
+	metadata, err := json.Marshal(map[string]any{"saved_exercise": item.Name, "saved_exercise_id": item.ID})
+	if err != nil {
+		return nil, fmt.Errorf("marshal saved exercise metadata: %w", err)
+	}
+	log := ExerciseLogInput{
+		ExerciseType: item.ExerciseType,
+		DurationMin:  duration,
+		PerformedAt:  in.PerformedAt,
+		Notes:        strings.TrimSpace(in.Notes),
+		Metadata:     string(metadata),
+	}
+	if in.Distance != nil {
+		if item.DistanceUnit == "" {
+			return nil, fmt.Errorf("saved exercise %q has no distance unit", item.Name)
+		}
+		log.Distance, log.DistanceUnit = in.Distance, item.DistanceUnit
+	}
+
+	out := &SavedExerciseLogResult{}
+	switch item.CalorieFormula {
+	case CalorieFormulaFixed:
+		log.CaloriesBurned = int(math.Round(item.CalorieValue))
+	case CalorieFormulaPerMinute:
+		if duration == nil {
+			return nil, fmt.Errorf("saved exercise %q needs a duration for its per-minute formula", item.Name)
+		}
+		log.CaloriesBurned = int(math.Round(item.CalorieValue * float64(*duration)))
+	case CalorieFormulaMET:
+		if duration == nil {
+			return nil, fmt.Errorf("saved exercise %q needs a duration for its MET formula", item.Name)
+		}
+		intensity := strings.TrimSpace(in.Intensity)
+		if intensity == "" {
+			intensity = item.Intensity
+		}
+		log, out.Estimate, err = estimateExerciseCalories(db, log, intensity, item.CalorieValue)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("saved exercise %q has unknown calorie formula %q", item.Name, item.CalorieFormula)
+	}
+
+	id, err := CreateExerciseLog(db, log)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(`UPDATE saved_exercises SET usage_count = usage_count + 1, last_used_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE id = ?`, item.ID); err != nil {
+		return nil, fmt.Errorf("update saved exercise usage: %w", err)
+	}
+	out.ExerciseLogID = id
+	out.Calories = log.CaloriesBurned
+	return out, nil
+}
+
+const savedExerciseSelectBase = `
+SELECT id, name, name_norm, exercise_type, default_duration_min, distance_unit, calorie_formula, calorie_value, intensity,
+       notes, usage_count, last_used_at, created_at, updated_at
+FROM saved_exercises`
+
+// scanSavedExercise reads one row selected with savedExerciseSelectBase. Scan
+// errors are returned unwrapped so callers can check for sql.ErrNoRows.
+func scanSavedExercise(scan func(dest ...any) error) (*model.SavedExercise, error) {
+	var item model.SavedExercise
+	var duration sql.NullInt64
+	var lastUsed sql.NullString
+	if err := scan(
+		&item.ID,
+		&item.Name,
+		&item.NameNorm,
+		&item.ExerciseType,
+		&duration,
+		&item.DistanceUnit,
+		&item.CalorieFormula,
+		&item.CalorieValue,
+		&item.Intensity,
+		&item.Notes,
+		&item.UsageCount,
+		&lastUsed,
+		&item.CreatedAt,
+		&item.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if duration.Valid {
+		v := int(duration.Int64)
+		item.DefaultDurationMin = &v
+	}
+	if lastUsed.Valid {
+		t, err := time.Parse(time.RFC3339, lastUsed.String)
+		if err == nil {
+			item.LastUsedAt = &t
+		}
+	}
+	return &item, nil
+}
@@ -0,0 +1,140 @@
+package service_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/db"
+	"github.com/saadjs/kcal-cli/internal/service"
+)
+
+func TestParseCalorieFormula(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		spec    string
+		formula string
+		value   float64
+	}{
+		{"450", service.CalorieFormulaFixed, 450},
+		{"fixed:300", service.CalorieFormulaFixed, 300},
+		{"per-minute:9.5", service.CalorieFormulaPerMinute, 9.5},
+		{"per_minute:11/min", service.CalorieFormulaPerMinute, 11},
+		{"met", service.CalorieFormulaMET, 0},
+		{"MET:8.5", service.CalorieFormulaMET, 8.5},
+	}
+	for _, tc := range cases {
+		formula, value, err := service.ParseCalorieFormula(tc.spec)
+		if err != nil || formula != tc.formula || value != tc.value {
+			t.Fatalf("%q: expected %s %.1f, got %s %.1f err=%v", tc.spec, tc.formula, tc.value, formula, value, err)
+		}
+		if round, _, _ := service.ParseCalorieFormula(service.FormatCalorieFormula(formula, value)); round != formula {
+			t.Fatalf("%q: formatted formula does not parse back", tc.spec)
+		}
+	}
+	for _, spec := range []string{"", "fixed", "per-minute:0", "watts:200"} {
+		if _, _, err := service.ParseCalorieFormula(spec); err == nil {
+			t.Fatalf("expected error for %q", spec)
+		}
+	}
+}
+
+func TestLogSavedExerciseFormulasAndPortability(t *testing.T) {
+	t.Parallel()
+	src := newTestDB(t)
+	defer src.Close()
+
+	day := time.Date(2026, 7, 1, 7, 0, 0, 0, time.Local)
+	if _, err := service.AddBodyMeasurement(src, service.BodyMeasurementInput{Weight: 70, Unit: "kg", MeasuredAt: day}); err != nil {
+		t.Fatalf("add measurement: %v", err)
+	}
+	thirty := 30
+	for _, in := range []service.CreateSavedExerciseInput{
+		{Name: "Morning Run", ExerciseType: "running", DefaultDurationMin: &thirty, DistanceUnit: "km", Formula: "met"},
+		{Name: "Spin", ExerciseType: "cycling", Formula: "per-minute:10"},
+		{Name: "Climbing", ExerciseType: "climbing", Formula: "fixed:450"},
+		{Name: "Rucking", ExerciseType: "rucking", DefaultDurationMin: &thirty, Formula: "met:6.5"},
+	} {
+		if _, err := service.CreateSavedExercise(src, in); err != nil {
+			t.Fatalf("create %s: %v", in.Name, err)
+		}
+	}
+	if _, err := service.CreateSavedExercise(src, service.CreateSavedExerciseInput{Name: "Curling", ExerciseType: "curling", Formula: "met"}); err == nil {
+		t.Fatalf("expected MET table lookup error for unknown type")
+	}
+	if _, err := service.CreateSavedExercise(src, service.CreateSavedExerciseInput{Name: "spin", ExerciseType: "cycling", Formula: "100"}); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected duplicate name error, got %v", err)
+	}
+
+	// Running MET 9.8 x 70 kg x 0.5 h from the template duration.
+	res, err := service.LogSavedExercise(src, service.LogSavedExerciseInput{Identifier: "morning run", PerformedAt: day})
+	if err != nil {
+		t.Fatalf("log MET template: %v", err)
+	}
+	if res.Calories != 343 || res.Estimate == nil || res.Estimate.MET != 9.8 {
+		t.Fatalf("expected 343 kcal from the MET table, got %+v", res)
+	}
+	if _, err := service.LogSavedExercise(src, service.LogSavedExerciseInput{Identifier: "spin", PerformedAt: day}); err == nil {
+		t.Fatalf("expected per-minute template without duration to fail")
+	}
+	fortyFive := 45
+	if res, err = service.LogSavedExercise(src, service.LogSavedExerciseInput{Identifier: "spin", DurationMin: &fortyFive, PerformedAt: day}); err != nil || res.Calories != 450 {
+		t.Fatalf("expected 450 kcal per-minute log, got %+v err=%v", res, err)
+	}
+	if res, err = service.LogSavedExercise(src, service.LogSavedExerciseInput{Identifier: "climbing", PerformedAt: day}); err != nil || res.Calories != 450 {
+		t.Fatalf("expected fixed 450 kcal log, got %+v err=%v", res, err)
+	}
+	// A template MET overrides the table: 6.5 x 70 x 0.5.
+	if res, err = service.LogSavedExercise(src, service.LogSavedExerciseInput{Identifier: "rucking", PerformedAt: day}); err != nil || res.Calories != 228 {
+		t.Fatalf("expected 228 kcal from template MET, got %+v err=%v", res, err)
+	}
+	if _, err := service.LogSavedExercise(src, service.LogSavedExerciseInput{Identifier: "morning run", PerformedAt: day.AddDate(0, 0, 1)}); err != nil {
+		t.Fatalf("log MET template again: %v", err)
+	}
+
+	items, err := service.ListSavedExercises(src, service.ListSavedExercisesFilter{})
+	if err != nil {
+		t.Fatalf("list saved exercises: %v", err)
+	}
+	if len(items) != 4 || items[0].Name != "Morning Run" || items[0].UsageCount != 2 {
+		t.Fatalf("expected most used template first, got %+v", items)
+	}
+	logs, err := service.ListExerciseLogs(src, service.ListExerciseFilter{Date: "2026-07-01"})
+	if err != nil {
+		t.Fatalf("list exercise logs: %v", err)
+	}
+	if len(logs) != 4 || !strings.Contains(logs[0].Metadata, `"saved_exercise"`) {
+		t.Fatalf("expected logs tagged with their template, got %+v", logs)
+	}
+
+	exported, err := service.ExportDataSnapshot(src)
+	if err != nil {
+		t.Fatalf("export snapshot: %v", err)
+	}
+	if len(exported.SavedExercises) != 4 {
+		t.Fatalf("expected 4 saved exercises in export, got %d", len(exported.SavedExercises))
+	}
+	dst, err := db.Open(filepath.Join(t.TempDir(), "dst.db"))
+	if err != nil {
+		t.Fatalf("open dst db: %v", err)
+	}
+	defer dst.Close()
+	if err := db.ApplyMigrations(dst); err != nil {
+		t.Fatalf("apply migrations on dst: %v", err)
+	}
+	if _, err := service.ImportDataSnapshotWithOptions(dst, exported, service.ImportOptions{Mode: service.ImportModeMerge}); err != nil {
+		t.Fatalf("import snapshot: %v", err)
+	}
+	imported, err := service.ResolveSavedExercise(dst, "Morning Run")
+	if err != nil {
+		t.Fatalf("resolve imported saved exercise: %v", err)
+	}
+	if imported.UsageCount != 2 || imported.CalorieFormula != service.CalorieFormulaMET || imported.DefaultDurationMin == nil || *imported.DefaultDurationMin != 30 || imported.DistanceUnit != "km" {
+		t.Fatalf("unexpected imported saved exercise %+v", imported)
+	}
+	if _, err := service.ImportDataSnapshotWithOptions(dst, exported, service.ImportOptions{Mode: service.ImportModeFail}); err == nil {
+		t.Fatalf("expected conflict in fail mode")
+	}
+}
@@ -53,6 +53,10 @@ type UpdateSavedFoodInput struct {
 	Metadata    string
 }
 
+type UpdateSavedFoodOptions struct {
+	Propagate bool
+}
+
 type ListSavedFoodsFilter struct {
 	IncludeArchived bool
 	Limit           int
@@ -240,33 +244,43 @@ func ListSavedFoods(db *sql.DB, f ListSavedFoodsFilter) ([]model.SavedFood, erro
 }
 
 func UpdateSavedFood(db *sql.DB, idOrName string, in UpdateSavedFoodInput) error {
+	_, err := UpdateSavedFoodWithOptions(db, idOrName, in, UpdateSavedFoodOptions{})
+	return err
+}
+
+// UpdateSavedFoodWithOptions updates a saved food and, when opts.Propagate is
+// set, re-snapshots dependent meal components and recalculates their meals in
+// the same transaction.
+func UpdateSavedFoodWithOptions(db *sql.DB, idOrName string, in UpdateSavedFoodInput, opts UpdateSavedFoodOptions) (SavedFoodPropagationReport, error) {
+	report := SavedFoodPropagationReport{}
 	item, err := ResolveSavedFood(db, idOrName)
 	if err != nil {
-		return err
+		return report, err
 	}
+	report.SavedFoodID = item.ID
 	if strings.TrimSpace(in.Name) == "" {
-		return fmt.Errorf("saved food name is required")
+		return report, fmt.Errorf("saved food name is required")
 	}
 	if err := validateNonNegativeInt("calories", in.Calories); err != nil {
-		return err
+		return report, err
 	}
 	if err := validateNonNegativeFloat("protein", in.ProteinG); err != nil {
-		return err
+		return report, err
 	}
 	if err := validateNonNegativeFloat("carbs", in.CarbsG); err != nil {
-		return err
+		return report, err
 	}
 	if err := validateNonNegativeFloat("fat", in.FatG); err != nil {
-		return err
+		return report, err
 	}
 	if err := validateNonNegativeFloat("fiber", in.FiberG); err != nil {
-		return err
+		return report, err
 	}
 	if err := validateNonNegativeFloat("sugar", in.SugarG); err != nil {
-		return err
+		return report, err
 	}
 	if err := validateNonNegativeFloat("sodium", in.SodiumMg); err != nil {
-		return err
+		return report, err
 	}
 	if in.ServingAmt <= 0 {
 		in.ServingAmt = 1
@@ -279,17 +293,23 @@ func UpdateSavedFood(db *sql.DB, idOrName string, in UpdateSavedFoodInput) error
 	}
 	catID, err := resolveCategoryIDWithDefault(db, in.Category)
 	if err != nil {
-		return err
+		return report, err
 	}
 	micros, err := normalizeMicronutrientsJSON(in.Micros)
 	if err != nil {
-		return err
+		return report, err
 	}
 	metadata, err := normalizeEntryMetadata(in.Metadata)
 	if err != nil {
-		return err
+		return report, err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return report, fmt.Errorf("begin tx: %w", err)
 	}
-	_, err = db.Exec(`
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.Exec(`
 UPDATE saved_foods
 SET name = ?, name_norm = ?, brand = ?, default_category_id = ?,
     calories = ?, protein_g = ?, carbs_g = ?, fat_g = ?, fiber_g = ?, sugar_g = ?, sodium_mg = ?, micronutrients_json = ?,
@@ -319,9 +339,17 @@ WHERE id = ?
 		item.ID,
 	)
 	if err != nil {
-		return fmt.Errorf("update saved food %q: %w", idOrName, err)
+		return report, fmt.Errorf("update saved food %q: %w", idOrName, err)
 	}
-	return nil
+	if opts.Propagate {
+		if err := propagateSavedFoodTx(tx, item.ID, &report); err != nil {
+			return report, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return report, fmt.Errorf("commit saved food update: %w", err)
+	}
+	return report, nil
 }
 
 func ArchiveSavedFood(db *sql.DB, idOrName string) error {
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/saadjs/kcal-cli/internal/model"
+)
+
+const (
+	defaultDedupeNameThreshold     = 0.8
+	defaultDedupeNutrientTolerance = 0.05
+)
+
+type DedupeSavedFoodsOptions struct {
+	IncludeArchived   bool
+	NameThreshold     float64
+	NutrientTolerance float64
+}
+
+type SavedFoodDuplicateCandidate struct {
+	First             model.SavedFood `json:"first"`
+	Second            model.SavedFood `json:"second"`
+	NameSimilarity    float64         `json:"name_similarity"`
+	SameSource        bool            `json:"same_source"`
+	SimilarNutrients  bool            `json:"similar_nutrients"`
+	Score             float64         `json:"score"`
+	Reasons           []string        `json:"reasons"`
+	SuggestedKeepID   int64           `json:"suggested_keep_id"`
+	SuggestedDropID   int64           `json:"suggested_drop_id"`
+	SuggestedKeepName string          `json:"suggested_keep_name"`
+}
+
+type MergeSavedFoodsResult struct {
+	KeepID              int64   `json:"keep_id"`
+	KeepName            string  `json:"keep_name"`
+	DroppedIDs          []int64 `json:"dropped_ids"`
+	ComponentsRepointed int64   `json:"components_repointed"`
+	UsageCount          int     `json:"usage_count"`
+}
+
+// FindSavedFoodDuplicates compares saved foods pairwise and returns likely
+// duplicates: identical provider references, near-identical normalized names
+// (ignoring brand tokens), or similar names with near-identical nutrients.
+func FindSavedFoodDuplicates(db *sql.DB, opts DedupeSavedFoodsOptions) ([]SavedFoodDuplicateCandidate, error) {
+	if opts.NameThreshold <= 0 {
+		opts.NameThreshold = defaultDedupeNameThreshold
+	}
+	if opts.NameThreshold > 1 {
+		return nil, fmt.Errorf("name threshold must be between 0 and 1")
+	}
+	if opts.NutrientTolerance <= 0 {
+		opts.NutrientTolerance = defaultDedupeNutrientTolerance
+	}
+	foods, err := ListSavedFoods(db, ListSavedFoodsFilter{IncludeArchived: opts.IncludeArchived, Limit: math.MaxInt32})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]SavedFoodDuplicateCandidate, 0)
+	for i := 0; i < len(foods); i++ {
+		for j := i + 1; j < len(foods); j++ {
+			a := foods[i]
+			b := foods[j]
+			c := SavedFoodDuplicateCandidate{
+				First:            a,
+				Second:           b,
+				NameSimilarity:   savedFoodNameSimilarity(a, b),
+				SameSource:       sameSavedFoodSource(a, b),
+				SimilarNutrients: similarSavedFoodNutrients(a, b, opts.NutrientTolerance),
+			}
+			isCandidate := c.SameSource ||
+				c.NameSimilarity >= opts.NameThreshold ||
+				(c.SimilarNutrients && c.NameSimilarity >= 0.5)
+			if !isCandidate {
+				continue
+			}
+			if c.SameSource {
+				c.Reasons = append(c.Reasons, fmt.Sprintf("same source %s:%s", a.SourceProvider, a.SourceRef))
+			}
+			if c.NameSimilarity >= opts.NameThreshold {
+				c.Reasons = append(c.Reasons, fmt.Sprintf("similar name (%.2f)", c.NameSimilarity))
+			}
+			if c.SimilarNutrients {
+				c.Reasons = append(c.Reasons, "near-identical nutrients")
+			}
+			c.Score = c.NameSimilarity * 0.5
+			if c.SameSource {
+				c.Score += 0.3
+			}
+			if c.SimilarNutrients {
+				c.Score += 0.2
+			}
+			c.Score = clamp01(c.Score)
+			keep, drop := preferredSavedFoodKeep(a, b)
+			c.SuggestedKeepID = keep.ID
+			c.SuggestedKeepName = keep.Name
+			c.SuggestedDropID = drop.ID
+			out = append(out, c)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		if out[i].First.ID != out[j].First.ID {
+			return out[i].First.ID < out[j].First.ID
+		}
+		return out[i].Second.ID < out[j].Second.ID
+	})
+	return out, nil
+}
+
+// MergeSavedFoods folds the dropped saved foods into keep: meal components are
+// repointed, usage counts are summed, and the dropped foods are archived.
+func MergeSavedFoods(db *sql.DB, keepIdentifier string, dropIdentifiers []string) (MergeSavedFoodsResult, error) {
+	result := MergeSavedFoodsResult{}
+	if len(dropIdentifiers) == 0 {
+		return result, fmt.Errorf("at least one saved food to merge is required")
+	}
+	keep, err := ResolveSavedFood(db, keepIdentifier)
+	if err != nil {
+		return result, err
+	}
+	if keep.ArchivedAt != nil {
+		return result, fmt.Errorf("saved food %q is archived", keep.Name)
+	}
+	drops := make([]*model.SavedFood, 0, len(dropIdentifiers))
+	seen := map[int64]bool{keep.ID: true}
+	for _, identifier := range dropIdentifiers {
+		drop, err := ResolveSavedFood(db, identifier)
+		if err != nil {
+			return result, err
+		}
+		if drop.ID == keep.ID {
+			return result, fmt.Errorf("cannot merge saved food %q into itself", keep.Name)
+		}
+		if seen[drop.ID] {
+			return result, fmt.Errorf("saved food %q listed more than once", drop.Name)
+		}
+		seen[drop.ID] = true
+		drops = append(drops, drop)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return result, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	usage := keep.UsageCount
+	for _, drop := range drops {
+		res, err := tx.Exec(`UPDATE saved_meal_components SET saved_food_id = ?, updated_at = CURRENT_TIMESTAMP WHERE saved_food_id = ?`, keep.ID, drop.ID)
+		if err != nil {
+			return result, fmt.Errorf("repoint components from saved food %q: %w", drop.Name, err)
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return result, fmt.Errorf("rows affected for saved food %q: %w", drop.Name, err)
+		}
+		result.ComponentsRepointed += affected
+		usage += drop.UsageCount
+		if _, err := tx.Exec(`
+UPDATE saved_foods
+SET usage_count = 0, archived_at = COALESCE(archived_at, CURRENT_TIMESTAMP), updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`, drop.ID); err != nil {
+			return result, fmt.Errorf("archive merged saved food %q: %w", drop.Name, err)
+		}
+		result.DroppedIDs = append(result.DroppedIDs, drop.ID)
+	}
+	if _, err := tx.Exec(`
+UPDATE saved_foods
+SET usage_count = ?,
+    last_used_at = (SELECT MAX(last_used_at) FROM saved_foods WHERE id = ? OR id IN (`+placeholders(len(result.DroppedIDs))+`)),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`, append(append([]any{usage, keep.ID}, int64sToAny(result.DroppedIDs)...), keep.ID)...); err != nil {
+		return result, fmt.Errorf("update merged saved food %q: %w", keep.Name, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("commit merge saved foods: %w", err)
+	}
+	result.KeepID = keep.ID
+	result.KeepName = keep.Name
+	result.UsageCount = usage
+	return result, nil
+}
+
+func savedFoodNameSimilarity(a, b model.SavedFood) float64 {
+	brandTokens := map[string]bool{}
+	for _, t := range tokenize(a.Brand) {
+		brandTokens[t] = true
+	}
+	for _, t := range tokenize(b.Brand) {
+		brandTokens[t] = true
+	}
+	setA := map[string]bool{}
+	for _, t := range tokenize(a.NameNorm) {
+		if !brandTokens[t] {
+			setA[t] = true
+		}
+	}
+	setB := map[string]bool{}
+	for _, t := range tokenize(b.NameNorm) {
+		if !brandTokens[t] {
+			setB[t] = true
+		}
+	}
+	if len(setA) == 0 && len(setB) == 0 {
+		return 1
+	}
+	intersection := 0
+	for t := range setA {
+		if setB[t] {
+			intersection++
+		}
+	}
+	union := len(setA) + len(setB) - intersection
+	if union == 0 {
+		return 0
+	}
+	return clamp01(float64(intersection) / float64(union))
+}
+
+func sameSavedFoodSource(a, b model.SavedFood) bool {
+	refA := strings.TrimSpace(a.SourceRef)
+	refB := strings.TrimSpace(b.SourceRef)
+	if refA == "" || refB == "" {
+		return false
+	}
+	return strings.EqualFold(strings.TrimSpace(a.SourceProvider), strings.TrimSpace(b.SourceProvider)) &&
+		strings.EqualFold(a.SourceType, b.SourceType) &&
+		refA == refB
+}
+
+func similarSavedFoodNutrients(a, b model.SavedFood, tolerance float64) bool {
+	pairs := [][2]float64{
+		{float64(a.Calories), float64(b.Calories)},
+		{a.ProteinG, b.ProteinG},
+		{a.CarbsG, b.CarbsG},
+		{a.FatG, b.FatG},
+	}
+	for _, p := range pairs {
+		if !nearlyEqual(p[0], p[1], tolerance) {
+			return false
+		}
+	}
+	return true
+}
+
+func nearlyEqual(a, b, tolerance float64) bool {
+	diff := math.Abs(a - b)
+	if diff <= 0.5 {
+		return true
+	}
+	return diff <= tolerance*math.Max(math.Abs(a), math.Abs(b))
+}
+
+// preferredSavedFoodKeep keeps the active, most used, oldest food of a pair.
+func preferredSavedFoodKeep(a, b model.SavedFood) (model.SavedFood, model.SavedFood) {
+	if (a.ArchivedAt == nil) != (b.ArchivedAt == nil) {
+		if a.ArchivedAt == nil {
+			return a, b
+		}
+		return b, a
+	}
+	if a.UsageCount != b.UsageCount {
+		if a.UsageCount > b.UsageCount {
+			return a, b
+		}
+		return b, a
+	}
+	if a.ID < b.ID {
+		return a, b
+	}
+	return b, a
+}
+
+func placeholders(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func int64sToAny(values []int64) []any {
+	out := make([]any, 0, len(values))
+	for _, v := range values {
+		out = append(out, v)
+	}
+	return out
+}
@@ -0,0 +1,98 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/saadjs/kcal-cli/internal/service"
+)
+
+func TestFindSavedFoodDuplicates(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	inputs := []service.CreateSavedFoodInput{
+		{Name: "Chobani Greek Yogurt", Calories: 150, ProteinG: 15, CarbsG: 10, FatG: 5},
+		{Name: "Greek Yogurt", Brand: "Chobani", Calories: 151, ProteinG: 15, CarbsG: 10, FatG: 5},
+		{Name: "Hazelnut Spread", Calories: 200, SourceType: "barcode", SourceProv: "openfoodfacts", SourceRef: "3017620422003"},
+		{Name: "Nutella", Calories: 210, SourceType: "barcode", SourceProv: "openfoodfacts", SourceRef: "3017620422003"},
+		{Name: "Banana", Calories: 105, CarbsG: 27},
+	}
+	for _, in := range inputs {
+		if _, err := service.CreateSavedFood(db, in); err != nil {
+			t.Fatalf("create saved food: %v", err)
+		}
+	}
+
+	items, err := service.FindSavedFoodDuplicates(db, service.DedupeSavedFoodsOptions{})
+	if err != nil {
+		t.Fatalf("find duplicates: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 duplicate pairs, got %d: %+v", len(items), items)
+	}
+	foundName, foundSource := false, false
+	for _, it := range items {
+		if it.NameSimilarity == 1 && it.SimilarNutrients {
+			foundName = true
+		}
+		if it.SameSource {
+			foundSource = true
+		}
+	}
+	if !foundName || !foundSource {
+		t.Fatalf("expected name and source duplicates, got %+v", items)
+	}
+}
+
+func TestMergeSavedFoodsRepointsComponents(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	keepID, err := service.CreateSavedFood(db, service.CreateSavedFoodInput{Name: "Oats", Calories: 150, CarbsG: 27})
+	if err != nil {
+		t.Fatalf("create saved food: %v", err)
+	}
+	dropID, err := service.CreateSavedFood(db, service.CreateSavedFoodInput{Name: "Rolled Oats", Calories: 150, CarbsG: 27})
+	if err != nil {
+		t.Fatalf("create saved food: %v", err)
+	}
+	if _, err := db.Exec(`UPDATE saved_foods SET usage_count = 3 WHERE id = ?`, keepID); err != nil {
+		t.Fatalf("seed usage: %v", err)
+	}
+	if _, err := db.Exec(`UPDATE saved_foods SET usage_count = 2 WHERE id = ?`, dropID); err != nil {
+		t.Fatalf("seed usage: %v", err)
+	}
+	if _, err := service.CreateSavedMeal(db, service.CreateSavedMealInput{Name: "Breakfast bowl"}); err != nil {
+		t.Fatalf("create saved meal: %v", err)
+	}
+	if _, err := service.AddSavedMealComponent(db, "Breakfast bowl", service.SavedMealComponentInput{SavedFoodIdentifier: "Rolled Oats"}); err != nil {
+		t.Fatalf("add component: %v", err)
+	}
+
+	res, err := service.MergeSavedFoods(db, "Oats", []string{"Rolled Oats"})
+	if err != nil {
+		t.Fatalf("merge saved foods: %v", err)
+	}
+	if res.ComponentsRepointed != 1 || res.UsageCount != 5 {
+		t.Fatalf("unexpected merge result: %+v", res)
+	}
+	var foodID int64
+	if err := db.QueryRow(`SELECT saved_food_id FROM saved_meal_components`).Scan(&foodID); err != nil {
+		t.Fatalf("query component: %v", err)
+	}
+	if foodID != keepID {
+		t.Fatalf("expected component to point at %d, got %d", keepID, foodID)
+	}
+	dropped, err := service.ResolveSavedFood(db, "Rolled Oats")
+	if err != nil {
+		t.Fatalf("resolve dropped: %v", err)
+	}
+	if dropped.ArchivedAt == nil {
+		t.Fatalf("expected dropped saved food to be archived")
+	}
+	if _, err := service.MergeSavedFoods(db, "Oats", []string{"Oats"}); err == nil {
+		t.Fatalf("expected self-merge to fail")
+	}
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const ConfigSavedFoodPropagate = "saved_food_propagate"
+
+type SavedFoodPropagationReport struct {
+	SavedFoodID       int64                     `json:"saved_food_id"`
+	ComponentsUpdated int                       `json:"components_updated"`
+	Meals             []SavedMealPropagateDelta `json:"meals"`
+}
+
+type SavedMealPropagateDelta struct {
+	MealID         int64   `json:"meal_id"`
+	Name           string  `json:"name"`
+	CaloriesBefore int     `json:"calories_before"`
+	CaloriesAfter  int     `json:"calories_after"`
+	CaloriesDelta  int     `json:"calories_delta"`
+	ProteinDeltaG  float64 `json:"protein_delta_g"`
+	CarbsDeltaG    float64 `json:"carbs_delta_g"`
+	FatDeltaG      float64 `json:"fat_delta_g"`
+}
+
+// SavedFoodPropagateDefault reports the configured default for
+// `saved-food update --propagate`.
+func SavedFoodPropagateDefault(db *sql.DB) (bool, error) {
+	value, ok, err := GetConfig(db, ConfigSavedFoodPropagate)
+	if err != nil || !ok || strings.TrimSpace(value) == "" {
+		return false, err
+	}
+	enabled, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		return false, fmt.Errorf("invalid config %s=%q (expected true|false)", ConfigSavedFoodPropagate, value)
+	}
+	return enabled, nil
+}
+
+type savedMealTotals struct {
+	name     string
+	calories int
+	protein  float64
+	carbs    float64
+	fat      float64
+}
+
+func propagateSavedFoodTx(tx *sql.Tx, foodID int64, report *SavedFoodPropagationReport) error {
+	rows, err := tx.Query(`
+SELECT DISTINCT sm.id, sm.name, sm.calories_total, sm.protein_total_g, sm.carbs_total_g, sm.fat_total_g
+FROM saved_meal_components smc
+JOIN saved_meals sm ON sm.id = smc.saved_meal_id
+WHERE smc.saved_food_id = ?
+ORDER BY sm.id ASC
+`, foodID)
+	if err != nil {
+		return fmt.Errorf("list saved meals using saved food: %w", err)
+	}
+	mealIDs := make([]int64, 0)
+	before := map[int64]savedMealTotals{}
+	for rows.Next() {
+		var id int64
+		var t savedMealTotals
+		if err := rows.Scan(&id, &t.name, &t.calories, &t.protein, &t.carbs, &t.fat); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan saved meal using saved food: %w", err)
+		}
+		mealIDs = append(mealIDs, id)
+		before[id] = t
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return fmt.Errorf("iterate saved meals using saved food: %w", err)
+	}
+	rows.Close()
+
+	res, err := tx.Exec(`
+UPDATE saved_meal_components
+SET calories = sf.calories, protein_g = sf.protein_g, carbs_g = sf.carbs_g, fat_g = sf.fat_g,
+    fiber_g = sf.fiber_g, sugar_g = sf.sugar_g, sodium_mg = sf.sodium_mg, micronutrients_json = sf.micronutrients_json,
+    updated_at = CURRENT_TIMESTAMP
+FROM (SELECT calories, protein_g, carbs_g, fat_g, fiber_g, sugar_g, sodium_mg, micronutrients_json FROM saved_foods WHERE id = ?) AS sf
+WHERE saved_meal_components.saved_food_id = ?
+`, foodID, foodID)
+	if err != nil {
+		return fmt.Errorf("re-snapshot saved meal components: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected for saved meal components: %w", err)
+	}
+	report.ComponentsUpdated = int(affected)
+
+	for _, mealID := range mealIDs {
+		if err := recalcSavedMealTotalsTx(tx, mealID); err != nil {
+			return err
+		}
+		var after savedMealTotals
+		if err := tx.QueryRow(`SELECT name, calories_total, protein_total_g, carbs_total_g, fat_total_g FROM saved_meals WHERE id = ?`, mealID).Scan(&after.name, &after.calories, &after.protein, &after.carbs, &after.fat); err != nil {
+			return fmt.Errorf("load recalculated saved meal %d: %w", mealID, err)
+		}
+		prev := before[mealID]
+		if after.calories == prev.calories && after.protein == prev.protein && after.carbs == prev.carbs && after.fat == prev.fat {
+			continue
+		}
+		report.Meals = append(report.Meals, SavedMealPropagateDelta{
+			MealID:         mealID,
+			Name:           after.name,
+			CaloriesBefore: prev.calories,
+			CaloriesAfter:  after.calories,
+			CaloriesDelta:  after.calories - prev.calories,
+			ProteinDeltaG:  after.protein - prev.protein,
+			CarbsDeltaG:    after.carbs - prev.carbs,
+			FatDeltaG:      after.fat - prev.fat,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/saadjs/kcal-cli/internal/service"
+)
+
+func TestUpdateSavedFoodPropagatesToMeals(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	if _, err := service.CreateSavedFood(db, service.CreateSavedFoodInput{Name: "Granola", Calories: 200, ProteinG: 5, CarbsG: 30, FatG: 7}); err != nil {
+		t.Fatalf("create saved food: %v", err)
+	}
+	if _, err := service.CreateSavedMeal(db, service.CreateSavedMealInput{Name: "Parfait"}); err != nil {
+		t.Fatalf("create saved meal: %v", err)
+	}
+	if _, err := service.AddSavedMealComponent(db, "Parfait", service.SavedMealComponentInput{SavedFoodIdentifier: "Granola"}); err != nil {
+		t.Fatalf("add component: %v", err)
+	}
+	if _, err := service.AddSavedMealComponent(db, "Parfait", service.SavedMealComponentInput{Name: "Berries", Calories: 50, CarbsG: 12}); err != nil {
+		t.Fatalf("add component: %v", err)
+	}
+	if err := service.RecalcSavedMealTotals(db, "Parfait"); err != nil {
+		t.Fatalf("recalc saved meal: %v", err)
+	}
+
+	update := service.UpdateSavedFoodInput{Name: "Granola", Calories: 240, ProteinG: 6, CarbsG: 34, FatG: 9}
+	if err := service.UpdateSavedFood(db, "Granola", update); err != nil {
+		t.Fatalf("update saved food: %v", err)
+	}
+	meal, err := service.ResolveSavedMeal(db, "Parfait")
+	if err != nil {
+		t.Fatalf("resolve saved meal: %v", err)
+	}
+	if meal.CaloriesTotal != 250 {
+		t.Fatalf("expected meal unchanged without propagate, got %d", meal.CaloriesTotal)
+	}
+
+	report, err := service.UpdateSavedFoodWithOptions(db, "Granola", update, service.UpdateSavedFoodOptions{Propagate: true})
+	if err != nil {
+		t.Fatalf("update saved food with propagate: %v", err)
+	}
+	if report.ComponentsUpdated != 1 || len(report.Meals) != 1 {
+		t.Fatalf("unexpected propagation report: %+v", report)
+	}
+	delta := report.Meals[0]
+	if delta.CaloriesBefore != 250 || delta.CaloriesAfter != 290 || delta.CaloriesDelta != 40 || delta.FatDeltaG != 2 {
+		t.Fatalf("unexpected meal delta: %+v", delta)
+	}
+	meal, err = service.ResolveSavedMeal(db, "Parfait")
+	if err != nil {
+		t.Fatalf("resolve saved meal: %v", err)
+	}
+	if meal.CaloriesTotal != 290 {
+		t.Fatalf("expected recalculated meal total 290, got %d", meal.CaloriesTotal)
+	}
+}
+
+func TestSavedFoodPropagateDefaultConfig(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	enabled, err := service.SavedFoodPropagateDefault(db)
+	if err != nil || enabled {
+		t.Fatalf("expected propagate disabled by default, got %v (%v)", enabled, err)
+	}
+	if err := service.SetConfig(db, service.ConfigSavedFoodPropagate, "true"); err != nil {
+		t.Fatalf("set config: %v", err)
+	}
+	enabled, err = service.SavedFoodPropagateDefault(db)
+	if err != nil || !enabled {
+		t.Fatalf("expected propagate enabled from config, got %v (%v)", enabled, err)
+	}
+	if err := service.SetConfig(db, service.ConfigSavedFoodPropagate, "sometimes"); err != nil {
+		t.Fatalf("set config: %v", err)
+	}
+	if _, err := service.SavedFoodPropagateDefault(db); err == nil {
+		t.Fatalf("expected invalid config value to fail")
+	}
+}
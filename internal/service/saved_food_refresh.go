@@ -0,0 +1,322 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/model"
+	"github.com/saadjs/kcal-cli/internal/provider/openfoodfacts"
+	"github.com/saadjs/kcal-cli/internal/provider/upcitemdb"
+	"github.com/saadjs/kcal-cli/internal/provider/usda"
+)
+
+const (
+	SavedFoodRefreshUnchanged = "unchanged"
+	SavedFoodRefreshChanged   = "changed"
+	SavedFoodRefreshVanished  = "vanished"
+	SavedFoodRefreshSkipped   = "skipped"
+	SavedFoodRefreshFailed    = "error"
+)
+
+type SavedFoodRefreshOptions struct {
+	All       bool
+	Apply     bool
+	Propagate bool
+	// Credentials resolves API key options per provider; nil means no keys.
+	Credentials func(provider string) (BarcodeLookupOptions, error)
+}
+
+// refreshClient looks up barcodes and searches a single provider.
+type refreshClient interface {
+	barcodeClient
+	searchClient
+}
+
+const savedFoodRefreshSearchLimit = 10
+
+type SavedFoodFieldDiff struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+type SavedFoodRefreshResult struct {
+	SavedFoodID int64                       `json:"saved_food_id"`
+	Name        string                      `json:"name"`
+	Provider    string                      `json:"provider"`
+	SourceRef   string                      `json:"source_ref"`
+	Status      string                      `json:"status"`
+	Message     string                      `json:"message,omitempty"`
+	Diffs       []SavedFoodFieldDiff        `json:"diffs,omitempty"`
+	Applied     bool                        `json:"applied"`
+	Propagation *SavedFoodPropagationReport `json:"propagation,omitempty"`
+}
+
+// RefreshSavedFoods re-fetches saved foods from their recorded provider and
+// reports field-level differences. Changes are written only when opts.Apply is set.
+func RefreshSavedFoods(db *sql.DB, identifier string, opts SavedFoodRefreshOptions) ([]SavedFoodRefreshResult, error) {
+	return refreshSavedFoodsWithClient(db, identifier, opts, newRefreshClient)
+}
+
+func refreshSavedFoodsWithClient(db *sql.DB, identifier string, opts SavedFoodRefreshOptions, newClient func(provider string, options BarcodeLookupOptions) (refreshClient, error)) ([]SavedFoodRefreshResult, error) {
+	identifier = strings.TrimSpace(identifier)
+	if opts.All == (identifier != "") {
+		return nil, fmt.Errorf("provide a saved food identifier or --all")
+	}
+	foods := make([]model.SavedFood, 0)
+	if opts.All {
+		items, err := ListSavedFoods(db, ListSavedFoodsFilter{Limit: math.MaxInt32})
+		if err != nil {
+			return nil, err
+		}
+		foods = items
+	} else {
+		item, err := ResolveSavedFood(db, identifier)
+		if err != nil {
+			return nil, err
+		}
+		foods = append(foods, *item)
+	}
+
+	out := make([]SavedFoodRefreshResult, 0, len(foods))
+	for _, food := range foods {
+		res := SavedFoodRefreshResult{
+			SavedFoodID: food.ID,
+			Name:        food.Name,
+			Provider:    food.SourceProvider,
+			SourceRef:   food.SourceRef,
+		}
+		if strings.TrimSpace(food.SourceProvider) == "" {
+			res.Status = SavedFoodRefreshSkipped
+			res.Message = "no provider source recorded"
+			out = append(out, res)
+			continue
+		}
+		fresh, err := fetchSavedFoodSource(db, food, opts, newClient)
+		if err != nil {
+			if errors.Is(err, ErrProviderNotFound) {
+				res.Status = SavedFoodRefreshVanished
+			} else {
+				res.Status = SavedFoodRefreshFailed
+			}
+			res.Message = err.Error()
+			out = append(out, res)
+			continue
+		}
+		res.Diffs = diffSavedFoodNutrition(food, fresh)
+		if len(res.Diffs) == 0 {
+			res.Status = SavedFoodRefreshUnchanged
+			out = append(out, res)
+			continue
+		}
+		res.Status = SavedFoodRefreshChanged
+		if opts.Apply {
+			report, err := applySavedFoodRefresh(db, food, fresh, opts.Propagate)
+			if err != nil {
+				return out, err
+			}
+			res.Applied = true
+			if opts.Propagate {
+				res.Propagation = &report
+			}
+		}
+		out = append(out, res)
+	}
+	return out, nil
+}
+
+func newRefreshClient(provider string, options BarcodeLookupOptions) (refreshClient, error) {
+	switch provider {
+	case BarcodeProviderUSDA:
+		return &usdaClientAdapter{client: &usda.Client{APIKey: options.APIKey}}, nil
+	case BarcodeProviderOpenFoodFacts:
+		return &openFoodFactsClientAdapter{client: &openfoodfacts.Client{}}, nil
+	case BarcodeProviderUPCItemDB:
+		return &upcItemDBClientAdapter{client: &upcitemdb.Client{APIKey: options.APIKey, APIKeyType: options.APIKeyType}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported provider %q", provider)
+	}
+}
+
+// fetchSavedFoodSource re-fetches a saved food from its provider without
+// reading the caches. The barcode and search caches are only replaced, or
+// purged when the record vanished, if opts.Apply is set.
+func fetchSavedFoodSource(db *sql.DB, food model.SavedFood, opts SavedFoodRefreshOptions, newClient func(string, BarcodeLookupOptions) (refreshClient, error)) (BarcodeLookupResult, error) {
+	provider := normalizeBarcodeProvider(food.SourceProvider)
+	options := BarcodeLookupOptions{}
+	if opts.Credentials != nil {
+		resolved, err := opts.Credentials(provider)
+		if err != nil {
+			return BarcodeLookupResult{}, err
+		}
+		options = resolved
+	}
+	client, err := newClient(provider, options)
+	if err != nil {
+		return BarcodeLookupResult{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	ref := strings.TrimSpace(food.SourceRef)
+	if isValidBarcode(ref) {
+		result, raw, err := client.LookupBarcode(ctx, ref)
+		if err != nil {
+			if opts.Apply && errors.Is(err, ErrProviderNotFound) {
+				if _, purgeErr := PurgeBarcodeCache(db, provider, ref, false); purgeErr != nil {
+					return BarcodeLookupResult{}, purgeErr
+				}
+			}
+			return BarcodeLookupResult{}, err
+		}
+		result.Provider = provider
+		result.Barcode = ref
+		result.SourceTier = "provider"
+		result.NutritionCompleteness = deriveNutritionCompleteness(result)
+		applyBarcodeConfidence(&result, DefaultVerifiedMinScore)
+		if opts.Apply {
+			if err := upsertBarcodeCache(db, result, raw, time.Now().Add(defaultBarcodeTTL)); err != nil {
+				return BarcodeLookupResult{}, err
+			}
+		}
+		return result, nil
+	}
+
+	query := strings.TrimSpace(food.Name + " " + food.Brand)
+	items, raw, err := client.SearchFoods(ctx, query, savedFoodRefreshSearchLimit)
+	if err != nil {
+		if opts.Apply && errors.Is(err, ErrProviderNotFound) {
+			if _, purgeErr := PurgeProviderSearchCache(db, provider, query, false); purgeErr != nil {
+				return BarcodeLookupResult{}, purgeErr
+			}
+		}
+		return BarcodeLookupResult{}, err
+	}
+	for i := range items {
+		items[i].Provider = provider
+		items[i].SourceTier = "provider"
+		items[i].NutritionCompleteness = deriveNutritionCompleteness(items[i])
+		applySearchConfidence(&items[i], query, DefaultVerifiedMinScore)
+	}
+	if opts.Apply {
+		if _, err := PurgeProviderSearchCache(db, provider, query, false); err != nil {
+			return BarcodeLookupResult{}, err
+		}
+		if err := upsertProviderSearchCache(db, provider, query, savedFoodRefreshSearchLimit, items, raw, time.Now().Add(defaultProviderSearchTTL)); err != nil {
+			return BarcodeLookupResult{}, err
+		}
+	}
+	wantKey := canonicalSearchKey(food.Name, food.Brand)
+	for _, item := range items {
+		if ref != "" && strconv.FormatInt(item.SourceID, 10) == ref {
+			return item, nil
+		}
+		if ref == "" && canonicalSearchKey(item.Description, item.Brand) == wantKey {
+			return item, nil
+		}
+	}
+	return BarcodeLookupResult{}, fmt.Errorf("match %s search for %q: %w", provider, query, ErrProviderNotFound)
+}
+
+func diffSavedFoodNutrition(food model.SavedFood, fresh BarcodeLookupResult) []SavedFoodFieldDiff {
+	out := make([]SavedFoodFieldDiff, 0)
+	addFloat := func(field string, oldValue, newValue float64) {
+		if math.Abs(oldValue-newValue) > 0.05 {
+			out = append(out, SavedFoodFieldDiff{Field: field, Old: formatRefreshFloat(oldValue), New: formatRefreshFloat(newValue)})
+		}
+	}
+	if newCalories := int(fresh.Calories); newCalories != food.Calories {
+		out = append(out, SavedFoodFieldDiff{Field: "calories", Old: strconv.Itoa(food.Calories), New: strconv.Itoa(newCalories)})
+	}
+	addFloat("protein_g", food.ProteinG, fresh.ProteinG)
+	addFloat("carbs_g", food.CarbsG, fresh.CarbsG)
+	addFloat("fat_g", food.FatG, fresh.FatG)
+	addFloat("fiber_g", food.FiberG, fresh.FiberG)
+	addFloat("sugar_g", food.SugarG, fresh.SugarG)
+	addFloat("sodium_mg", food.SodiumMg, fresh.SodiumMg)
+	if fresh.ServingAmount > 0 {
+		addFloat("serving_amount", food.ServingAmount, fresh.ServingAmount)
+	}
+	if unit := strings.TrimSpace(fresh.ServingUnit); unit != "" && unit != food.ServingUnit {
+		out = append(out, SavedFoodFieldDiff{Field: "serving_unit", Old: food.ServingUnit, New: unit})
+	}
+
+	oldMicros, err := ParseMicronutrientsJSON(food.Micronutrients)
+	if err != nil {
+		oldMicros = Micronutrients{}
+	}
+	keys := map[string]bool{}
+	for k := range oldMicros {
+		keys[k] = true
+	}
+	for k := range fresh.Micronutrients {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	for _, k := range sorted {
+		o, hasOld := oldMicros[k]
+		n, hasNew := fresh.Micronutrients[k]
+		if hasOld && hasNew && o.Unit == n.Unit && math.Abs(o.Value-n.Value) <= 0.05 {
+			continue
+		}
+		out = append(out, SavedFoodFieldDiff{Field: "micronutrients." + k, Old: formatRefreshMicro(o, hasOld), New: formatRefreshMicro(n, hasNew)})
+	}
+	return out
+}
+
+func applySavedFoodRefresh(db *sql.DB, food model.SavedFood, fresh BarcodeLookupResult, propagate bool) (SavedFoodPropagationReport, error) {
+	servingAmount := food.ServingAmount
+	if fresh.ServingAmount > 0 {
+		servingAmount = fresh.ServingAmount
+	}
+	servingUnit := food.ServingUnit
+	if strings.TrimSpace(fresh.ServingUnit) != "" {
+		servingUnit = fresh.ServingUnit
+	}
+	micros, err := EncodeMicronutrientsJSON(fresh.Micronutrients)
+	if err != nil {
+		return SavedFoodPropagationReport{}, err
+	}
+	return UpdateSavedFoodWithOptions(db, strconv.FormatInt(food.ID, 10), UpdateSavedFoodInput{
+		Name:        food.Name,
+		Brand:       food.Brand,
+		Category:    food.DefaultCategory,
+		Calories:    int(fresh.Calories),
+		ProteinG:    fresh.ProteinG,
+		CarbsG:      fresh.CarbsG,
+		FatG:        fresh.FatG,
+		FiberG:      fresh.FiberG,
+		SugarG:      fresh.SugarG,
+		SodiumMg:    fresh.SodiumMg,
+		Micros:      micros,
+		ServingAmt:  servingAmount,
+		ServingUnit: servingUnit,
+		SourceType:  food.SourceType,
+		SourceProv:  food.SourceProvider,
+		SourceRef:   food.SourceRef,
+		Notes:       food.Notes,
+		Metadata:    food.Metadata,
+	}, UpdateSavedFoodOptions{Propagate: propagate})
+}
+
+func formatRefreshFloat(v float64) string {
+	return strconv.FormatFloat(math.Round(v*10)/10, 'f', -1, 64)
+}
+
+func formatRefreshMicro(m MicronutrientAmount, ok bool) string {
+	if !ok {
+		return "-"
+	}
+	return formatRefreshFloat(m.Value) + " " + m.Unit
+}
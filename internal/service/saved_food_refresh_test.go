@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type fakeRefreshClient struct {
+	items map[string]BarcodeLookupResult
+}
+
+func (f *fakeRefreshClient) LookupBarcode(ctx context.Context, barcode string) (BarcodeLookupResult, []byte, error) {
+	_ = ctx
+	item, ok := f.items[barcode]
+	if !ok {
+		return BarcodeLookupResult{}, nil, fmt.Errorf("lookup %s: %w", barcode, ErrProviderNotFound)
+	}
+	return item, []byte(`{"ok":true}`), nil
+}
+
+func (f *fakeRefreshClient) SearchFoods(ctx context.Context, query string, limit int) ([]BarcodeLookupResult, []byte, error) {
+	_ = ctx
+	_ = limit
+	return nil, nil, fmt.Errorf("search %q: %w", query, ErrProviderNotFound)
+}
+
+func TestRefreshSavedFoodsReportsDiffAndAppliesOnlyWhenRequested(t *testing.T) {
+	sqldb := newServiceDB(t)
+	defer sqldb.Close()
+
+	if _, err := CreateSavedFood(sqldb, CreateSavedFoodInput{
+		Name: "Protein Bar", Calories: 200, ProteinG: 20, CarbsG: 22, FatG: 7, ServingAmt: 1, ServingUnit: "bar",
+		SourceType: "barcode", SourceProv: BarcodeProviderOpenFoodFacts, SourceRef: "012345678905",
+		Micros: `{"iron_mg":{"value":2,"unit":"mg"}}`,
+	}); err != nil {
+		t.Fatalf("create saved food: %v", err)
+	}
+	if _, err := CreateSavedFood(sqldb, CreateSavedFoodInput{
+		Name: "Old Cereal", Calories: 150, SourceType: "barcode", SourceProv: BarcodeProviderOpenFoodFacts, SourceRef: "0000000000017",
+	}); err != nil {
+		t.Fatalf("create saved food: %v", err)
+	}
+	if _, err := CreateSavedFood(sqldb, CreateSavedFoodInput{Name: "Homemade Soup", Calories: 300}); err != nil {
+		t.Fatalf("create saved food: %v", err)
+	}
+	for _, barcode := range []string{"012345678905", "0000000000017"} {
+		stale := BarcodeLookupResult{Provider: BarcodeProviderOpenFoodFacts, Barcode: barcode, Description: "Stale", Calories: 100}
+		if err := upsertBarcodeCache(sqldb, stale, []byte(`{}`), time.Now().Add(defaultBarcodeTTL)); err != nil {
+			t.Fatalf("seed barcode cache: %v", err)
+		}
+	}
+
+	client := &fakeRefreshClient{items: map[string]BarcodeLookupResult{
+		"012345678905": {
+			Description: "Protein Bar", Calories: 210, ProteinG: 20, CarbsG: 24, FatG: 7, ServingAmount: 1, ServingUnit: "bar",
+			Micronutrients: Micronutrients{"iron_mg": {Value: 2, Unit: "mg"}, "calcium_mg": {Value: 100, Unit: "mg"}},
+		},
+	}}
+	newClient := func(string, BarcodeLookupOptions) (refreshClient, error) { return client, nil }
+	cacheDescriptions := func() map[string]string {
+		t.Helper()
+		items, err := ListBarcodeCache(sqldb, BarcodeProviderOpenFoodFacts, 10)
+		if err != nil {
+			t.Fatalf("list barcode cache: %v", err)
+		}
+		out := map[string]string{}
+		for _, item := range items {
+			out[item.Barcode] = item.Description
+		}
+		return out
+	}
+
+	results, err := refreshSavedFoodsWithClient(sqldb, "", SavedFoodRefreshOptions{All: true}, newClient)
+	if err != nil {
+		t.Fatalf("refresh saved foods: %v", err)
+	}
+	statuses := map[string]SavedFoodRefreshResult{}
+	for _, r := range results {
+		statuses[r.Name] = r
+	}
+	bar := statuses["Protein Bar"]
+	if bar.Status != SavedFoodRefreshChanged || bar.Applied {
+		t.Fatalf("expected unapplied change for protein bar, got %+v", bar)
+	}
+	fields := map[string]bool{}
+	for _, d := range bar.Diffs {
+		fields[d.Field] = true
+	}
+	if !fields["calories"] || !fields["carbs_g"] || !fields["micronutrients.calcium_mg"] || fields["protein_g"] || fields["micronutrients.iron_mg"] {
+		t.Fatalf("unexpected diff fields: %+v", bar.Diffs)
+	}
+	if statuses["Old Cereal"].Status != SavedFoodRefreshVanished {
+		t.Fatalf("expected vanished provider record, got %+v", statuses["Old Cereal"])
+	}
+	if statuses["Homemade Soup"].Status != SavedFoodRefreshSkipped {
+		t.Fatalf("expected manual food skipped, got %+v", statuses["Homemade Soup"])
+	}
+	food, err := ResolveSavedFood(sqldb, "Protein Bar")
+	if err != nil {
+		t.Fatalf("resolve saved food: %v", err)
+	}
+	if food.Calories != 200 {
+		t.Fatalf("expected dry run to keep calories, got %d", food.Calories)
+	}
+	if cache := cacheDescriptions(); cache["012345678905"] != "Stale" || cache["0000000000017"] != "Stale" {
+		t.Fatalf("expected dry run to leave barcode cache untouched, got %+v", cache)
+	}
+
+	if _, err := refreshSavedFoodsWithClient(sqldb, "", SavedFoodRefreshOptions{All: true, Apply: true}, newClient); err != nil {
+		t.Fatalf("apply refresh: %v", err)
+	}
+	food, err = ResolveSavedFood(sqldb, "Protein Bar")
+	if err != nil {
+		t.Fatalf("resolve saved food: %v", err)
+	}
+	if food.Calories != 210 || food.CarbsG != 24 || food.SourceRef != "012345678905" {
+		t.Fatalf("expected refreshed values applied, got %+v", food)
+	}
+	cache := cacheDescriptions()
+	if cache["012345678905"] != "Protein Bar" {
+		t.Fatalf("expected apply to replace cached barcode, got %+v", cache)
+	}
+	if _, ok := cache["0000000000017"]; ok {
+		t.Fatalf("expected apply to purge vanished barcode, got %+v", cache)
+	}
+
+	if _, err := refreshSavedFoodsWithClient(sqldb, "Protein Bar", SavedFoodRefreshOptions{All: true}, newClient); err == nil {
+		t.Fatalf("expected identifier with --all to fail")
+	}
+}
@@ -0,0 +1,443 @@
+package service
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/model"
+)
+
+const (
+	ConfigStepsCalories = "steps_calories"
+	ConfigStepsStrideCm = "steps_stride_cm"
+	ConfigStepsGoal     = "steps_goal"
+
+	// StepsCaloriesNone records steps without a calorie estimate. Exercise
+	// adds the estimate to exercise calories; baseline reports it as part of
+	// the activity level already counted in maintenance.
+	StepsCaloriesNone     = "none"
+	StepsCaloriesExercise = "exercise"
+	StepsCaloriesBaseline = "baseline"
+
+	// StepsExerciseType is the exercise type step calories are credited under
+	// by per-type eat-back policies.
+	StepsExerciseType = "steps"
+
+	// walkingKcalPerKgKm is the net cost of walking above rest.
+	walkingKcalPerKgKm = 0.5
+	// strideHeightRatio estimates walking stride length from height.
+	strideHeightRatio = 0.414
+	defaultStrideCm   = 76.0
+)
+
+var StepsCaloriesModes = []string{StepsCaloriesNone, StepsCaloriesExercise, StepsCaloriesBaseline}
+
+type StepsSettings struct {
+	CaloriesMode string  `json:"calories_mode"`
+	StrideCm     float64 `json:"stride_cm,omitempty"`
+	Goal         int     `json:"goal,omitempty"`
+}
+
+// StepsDay is one day's step total with its calorie estimate, when enabled
+// and a body weight is recorded on or before the day.
+type StepsDay struct {
+	Date         string  `json:"date"`
+	Steps        int     `json:"steps"`
+	Source       string  `json:"source"`
+	StrideCm     float64 `json:"stride_cm,omitempty"`
+	StrideSource string  `json:"stride_source,omitempty"`
+	DistanceKm   float64 `json:"distance_km,omitempty"`
+	WeightKg     float64 `json:"weight_kg,omitempty"`
+	Calories     int     `json:"calories,omitempty"`
+	CountsAs     string  `json:"counts_as,omitempty"`
+}
+
+type StepsImportOptions struct {
+	// DateColumn and StepsColumn name the header to read; empty detects them.
+	DateColumn  string
+	StepsColumn string
+	DryRun      bool
+}
+
+type StepsImportReport struct {
+	DryRun    bool     `json:"dry_run"`
+	Rows      int      `json:"rows"`
+	Days      int      `json:"days"`
+	Inserted  int      `json:"inserted"`
+	Updated   int      `json:"updated"`
+	Unchanged int      `json:"unchanged"`
+	Skipped   int      `json:"skipped"`
+	Columns   []string `json:"columns"`
+	FirstDate string   `json:"first_date,omitempty"`
+	LastDate  string   `json:"last_date,omitempty"`
+	Warnings  []string `json:"warnings,omitempty"`
+}
+
+var (
+	stepsDateHeaders  = []string{"date", "day", "start", "start date", "startdate", "timestamp", "datetime"}
+	stepsCountHeaders = []string{"steps", "step count", "step_count", "stepcount", "count", "value"}
+)
+
+// ParseStepsCaloriesMode accepts none, exercise or baseline; empty is none.
+func ParseStepsCaloriesMode(raw string) (string, error) {
+	value := strings.ToLower(strings.TrimSpace(raw))
+	switch value {
+	case "":
+		return StepsCaloriesNone, nil
+	case StepsCaloriesNone, StepsCaloriesExercise, StepsCaloriesBaseline:
+		return value, nil
+	default:
+		return "", fmt.Errorf("invalid steps calories mode %q (use none, exercise, or baseline)", raw)
+	}
+}
+
+func LoadStepsSettings(db *sql.DB) (StepsSettings, error) {
+	cfg, err := ListConfig(db)
+	if err != nil {
+		return StepsSettings{}, err
+	}
+	out := StepsSettings{}
+	out.CaloriesMode, err = ParseStepsCaloriesMode(cfg[ConfigStepsCalories])
+	if err != nil {
+		return out, fmt.Errorf("config %s: %w", ConfigStepsCalories, err)
+	}
+	if raw := strings.TrimSpace(cfg[ConfigStepsStrideCm]); raw != "" {
+		out.StrideCm, err = strconv.ParseFloat(raw, 64)
+		if err != nil || out.StrideCm <= 0 {
+			return out, fmt.Errorf("invalid config %s=%q (expected centimeters > 0)", ConfigStepsStrideCm, raw)
+		}
+	}
+	if raw := strings.TrimSpace(cfg[ConfigStepsGoal]); raw != "" {
+		out.Goal, err = strconv.Atoi(raw)
+		if err != nil || out.Goal < 0 {
+			return out, fmt.Errorf("invalid config %s=%q (expected steps >= 0)", ConfigStepsGoal, raw)
+		}
+	}
+	return out, nil
+}
+
+// SetDailySteps stores the step total for date, replacing any earlier total.
+func SetDailySteps(db *sql.DB, date string, steps int) error {
+	date, err := normalizeGoalDate(date)
+	if err != nil {
+		return err
+	}
+	if steps < 0 {
+		return fmt.Errorf("step count must be >= 0")
+	}
+	return setDailySteps(db, date, steps, "manual")
+}
+
+func setDailySteps(exec sqlExecutor, date string, steps int, source string) error {
+	if _, err := exec.Exec(`
+INSERT INTO daily_steps(date, steps, source) VALUES(?, ?, ?)
+ON CONFLICT(date) DO UPDATE SET steps=excluded.steps, source=excluded.source, updated_at=CURRENT_TIMESTAMP
+`, date, steps, source); err != nil {
+		return fmt.Errorf("set steps for %s: %w", date, err)
+	}
+	return nil
+}
+
+func DeleteDailySteps(db *sql.DB, date string) error {
+	date, err := normalizeGoalDate(date)
+	if err != nil {
+		return err
+	}
+	res, err := db.Exec(`DELETE FROM daily_steps WHERE date = ?`, date)
+	if err != nil {
+		return fmt.Errorf("delete steps for %s: %w", date, err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("read rows affected: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("no steps recorded for %s", date)
+	}
+	return nil
+}
+
+// ListDailySteps returns step totals between two dates inclusive, oldest
+// first, with calorie estimates per the steps settings.
+func ListDailySteps(db *sql.DB, fromDate, toDate string) ([]StepsDay, error) {
+	query := `SELECT date, steps, source, created_at, updated_at FROM daily_steps WHERE 1=1`
+	args := make([]any, 0, 2)
+	if strings.TrimSpace(fromDate) != "" {
+		from, err := normalizeGoalDate(fromDate)
+		if err != nil {
+			return nil, err
+		}
+		query += ` AND date >= ?`
+		args = append(args, from)
+	}
+	if strings.TrimSpace(toDate) != "" {
+		to, err := normalizeGoalDate(toDate)
+		if err != nil {
+			return nil, err
+		}
+		query += ` AND date <= ?`
+		args = append(args, to)
+	}
+	query += ` ORDER BY date ASC`
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list steps: %w", err)
+	}
+	defer rows.Close()
+	items := make([]model.DailySteps, 0)
+	for rows.Next() {
+		var item model.DailySteps
+		if err := rows.Scan(&item.Date, &item.Steps, &item.Source, &item.CreatedAt, &item.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan steps: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate steps: %w", err)
+	}
+	_ = rows.Close()
+	return estimateStepsDays(db, items)
+}
+
+// loadStepsByDay returns the steps recorded between from and to, keyed by
+// date, with calorie estimates per the steps settings.
+func loadStepsByDay(db *sql.DB, from, to time.Time) (map[string]StepsDay, error) {
+	days, err := ListDailySteps(db, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]StepsDay, len(days))
+	for _, d := range days {
+		out[d.Date] = d
+	}
+	return out, nil
+}
+
+func estimateStepsDays(db *sql.DB, items []model.DailySteps) ([]StepsDay, error) {
+	out := make([]StepsDay, 0, len(items))
+	if len(items) == 0 {
+		return out, nil
+	}
+	settings, err := LoadStepsSettings(db)
+	if err != nil {
+		return nil, err
+	}
+	stride, strideSource := settings.StrideCm, "config"
+	if stride <= 0 {
+		profile, err := GetProfile(db)
+		if err != nil {
+			return nil, err
+		}
+		stride, strideSource = defaultStrideCm, "default"
+		if profile != nil && profile.HeightCm != nil {
+			stride, strideSource = roundTo(*profile.HeightCm*strideHeightRatio, 1), "height"
+		}
+	}
+	for _, item := range items {
+		day := StepsDay{Date: item.Date, Steps: item.Steps, Source: item.Source}
+		if settings.CaloriesMode != StepsCaloriesNone && item.Steps > 0 {
+			latest, err := LatestBodyMeasurement(db, item.Date)
+			if err != nil {
+				return nil, err
+			}
+			if latest != nil {
+				km := float64(item.Steps) * stride / 100000
+				day.StrideCm, day.StrideSource = stride, strideSource
+				day.DistanceKm = roundTo(km, 2)
+				day.WeightKg = roundTo(latest.WeightKg, 2)
+				day.Calories = int(math.Round(km * latest.WeightKg * walkingKcalPerKgKm))
+				day.CountsAs = settings.CaloriesMode
+			}
+		}
+		out = append(out, day)
+	}
+	return out, nil
+}
+
+// exerciseCalories returns the step calories that count as exercise, which is
+// zero unless the steps calories mode is exercise.
+func (s StepsDay) exerciseCalories() int {
+	if s.CountsAs != StepsCaloriesExercise {
+		return 0
+	}
+	return s.Calories
+}
+
+// applyStepsToDays fills each day's steps and step calories, and takes step
+// calories that count as exercise off net calories. ExerciseCalories stays
+// logged exercise only.
+func applyStepsToDays(db *sql.DB, days []DaySummary, from, to time.Time) error {
+	steps, err := loadStepsByDay(db, from, to)
+	if err != nil {
+		return err
+	}
+	for i := range days {
+		if s, ok := steps[days[i].Date]; ok {
+			days[i].Steps = s.Steps
+			days[i].StepCalories = s.Calories
+			days[i].stepExerciseCalories = s.exerciseCalories()
+			days[i].NetCalories -= days[i].stepExerciseCalories
+		}
+	}
+	return nil
+}
+
+// ImportStepsCSV reads a CSV of dates and step counts, sums rows that fall on
+// the same day (per-hour or per-sample exports), and sets each day's total.
+// With DryRun the report is computed but nothing is written.
+func ImportStepsCSV(db *sql.DB, r io.Reader, opts StepsImportOptions) (*StepsImportReport, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read steps csv: %w", err)
+	}
+	raw = bytes.TrimPrefix(raw, []byte("\xef\xbb\xbf"))
+	reader := csv.NewReader(bytes.NewReader(raw))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if firstLine, _, _ := bytes.Cut(raw, []byte("\n")); bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parse steps csv: %w", err)
+	}
+	if len(records) <= 1 {
+		return nil, fmt.Errorf("steps csv contains no data rows")
+	}
+
+	header := records[0]
+	dateCol, err := findStepsColumn(header, opts.DateColumn, stepsDateHeaders, "date")
+	if err != nil {
+		return nil, err
+	}
+	stepsCol, err := findStepsColumn(header, opts.StepsColumn, stepsCountHeaders, "steps")
+	if err != nil {
+		return nil, err
+	}
+	report := &StepsImportReport{
+		DryRun:  opts.DryRun,
+		Columns: []string{"date=" + strings.TrimSpace(header[dateCol]), "steps=" + strings.TrimSpace(header[stepsCol])},
+	}
+
+	totals := map[string]int{}
+	for i, rec := range records[1:] {
+		line := i + 2
+		if bodyImportBlankRecord(rec) {
+			continue
+		}
+		report.Rows++
+		if dateCol >= len(rec) || stepsCol >= len(rec) {
+			report.Skipped++
+			report.Warnings = append(report.Warnings, fmt.Sprintf("row %d: missing columns", line))
+			continue
+		}
+		at, err := parseBodyImportTime(rec[dateCol], time.Local)
+		if err != nil {
+			report.Skipped++
+			report.Warnings = append(report.Warnings, fmt.Sprintf("row %d: %v", line, err))
+			continue
+		}
+		count, err := parseStepCount(rec[stepsCol])
+		if err != nil {
+			report.Skipped++
+			report.Warnings = append(report.Warnings, fmt.Sprintf("row %d: %v", line, err))
+			continue
+		}
+		totals[at.In(time.Local).Format("2006-01-02")] += count
+	}
+	dates := make([]string, 0, len(totals))
+	for date := range totals {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+	report.Days = len(dates)
+	if len(dates) > 0 {
+		report.FirstDate, report.LastDate = dates[0], dates[len(dates)-1]
+	}
+
+	existing := map[string]int{}
+	if len(dates) > 0 {
+		items, err := ListDailySteps(db, dates[0], dates[len(dates)-1])
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			existing[item.Date] = item.Steps
+		}
+	}
+	changed := make([]string, 0, len(dates))
+	for _, date := range dates {
+		prev, ok := existing[date]
+		switch {
+		case ok && prev == totals[date]:
+			report.Unchanged++
+			continue
+		case ok:
+			report.Updated++
+		default:
+			report.Inserted++
+		}
+		changed = append(changed, date)
+	}
+	if opts.DryRun {
+		return report, nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin steps import tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	for _, date := range changed {
+		if err := setDailySteps(tx, date, totals[date], "import"); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit steps import: %w", err)
+	}
+	return report, nil
+}
+
+func findStepsColumn(header []string, name string, candidates []string, field string) (int, error) {
+	if strings.TrimSpace(name) != "" {
+		candidates = []string{name}
+	}
+	for _, candidate := range candidates {
+		for i, h := range header {
+			h = strings.ToLower(strings.TrimSpace(h))
+			if m := headerUnitPattern.FindStringSubmatch(h); m != nil {
+				h = strings.TrimSpace(h[:len(h)-len(m[0])])
+			}
+			if h == strings.ToLower(strings.TrimSpace(candidate)) {
+				return i, nil
+			}
+		}
+	}
+	if strings.TrimSpace(name) != "" {
+		return 0, fmt.Errorf("column %q for %s not found in header", name, field)
+	}
+	return 0, fmt.Errorf("no %s column found (use --%s-column)", field, field)
+}
+
+// parseStepCount accepts whole counts with thousands separators ("8,432") and
+// rounds fractional counts from per-sample exports.
+func parseStepCount(value string) (int, error) {
+	v := strings.NewReplacer(",", "", " ", "", "_", "").Replace(strings.TrimSpace(value))
+	if v == "" {
+		return 0, fmt.Errorf("missing step count")
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid step count %q", value)
+	}
+	return int(math.Round(n)), nil
+}
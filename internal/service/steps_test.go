@@ -0,0 +1,183 @@
+package service_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/service"
+)
+
+func TestDailyStepsCaloriesModes(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	day := time.Date(2026, 6, 10, 0, 0, 0, 0, time.Local)
+	if _, err := service.AddBodyMeasurement(db, service.BodyMeasurementInput{Weight: 80, Unit: "kg", MeasuredAt: day.Add(7 * time.Hour)}); err != nil {
+		t.Fatalf("add measurement: %v", err)
+	}
+	if err := service.SetDailySteps(db, "2026-06-10", 10000); err != nil {
+		t.Fatalf("set steps: %v", err)
+	}
+	if err := service.SetDailySteps(db, "2026-06-10", -1); err == nil {
+		t.Fatalf("expected negative step count error")
+	}
+	if _, err := service.CreateExerciseLog(db, service.ExerciseLogInput{ExerciseType: "running", CaloriesBurned: 300, PerformedAt: day.Add(18 * time.Hour)}); err != nil {
+		t.Fatalf("add exercise: %v", err)
+	}
+
+	// Without a mode, steps are recorded but not estimated.
+	report, err := service.AnalyticsRange(db, day, day, 0.10)
+	if err != nil {
+		t.Fatalf("analytics without mode: %v", err)
+	}
+	if len(report.Days) != 1 || report.Days[0].Steps != 10000 || report.Days[0].StepCalories != 0 || report.Days[0].ExerciseCalories != 300 {
+		t.Fatalf("expected steps without calories, got %+v", report.Days)
+	}
+
+	// 10000 steps x 76 cm default stride = 7.6 km x 80 kg x 0.5 = 304 kcal.
+	if err := service.SetConfig(db, service.ConfigStepsCalories, service.StepsCaloriesExercise); err != nil {
+		t.Fatalf("set steps mode: %v", err)
+	}
+	items, err := service.ListDailySteps(db, "2026-06-01", "2026-06-30")
+	if err != nil {
+		t.Fatalf("list steps: %v", err)
+	}
+	if len(items) != 1 || items[0].Calories != 304 || items[0].StrideSource != "default" || items[0].DistanceKm != 7.6 || items[0].CountsAs != service.StepsCaloriesExercise {
+		t.Fatalf("unexpected steps estimate %+v", items)
+	}
+	report, err = service.AnalyticsRange(db, day, day, 0.10)
+	if err != nil {
+		t.Fatalf("analytics in exercise mode: %v", err)
+	}
+	// Step calories stay separate from logged exercise but come off net.
+	if report.Days[0].StepCalories != 304 || report.Days[0].ExerciseCalories != 300 || report.Days[0].NetCalories != -604 || report.TotalExerciseCalories != 300 || report.TotalNetCalories != -604 {
+		t.Fatalf("expected step calories counted against net, got %+v", report.Days[0])
+	}
+
+	// Baseline keeps the estimate out of exercise so it is not counted twice.
+	if err := service.SetConfig(db, service.ConfigStepsCalories, service.StepsCaloriesBaseline); err != nil {
+		t.Fatalf("set steps mode: %v", err)
+	}
+	status, err := service.TodaySummary(db, day)
+	if err != nil {
+		t.Fatalf("today summary: %v", err)
+	}
+	if status.ExerciseCalories != 300 || status.NetCalories != -300 || status.Steps == nil || status.Steps.Calories != 304 || status.Steps.CountsAs != service.StepsCaloriesBaseline {
+		t.Fatalf("expected baseline step estimate outside exercise, got %+v steps=%+v", status, status.Steps)
+	}
+
+	if err := service.SetConfig(db, service.ConfigStepsCalories, "sometimes"); err != nil {
+		t.Fatalf("set invalid mode: %v", err)
+	}
+	if _, err := service.ListDailySteps(db, "", ""); err == nil {
+		t.Fatalf("expected invalid steps mode config error")
+	}
+}
+
+func TestDailyStepsStreaksAndPerTypeEatBack(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	from := time.Date(2026, 6, 1, 0, 0, 0, 0, time.Local)
+	for i, steps := range []int{12000, 4000, 11000, 10500} {
+		if err := service.SetDailySteps(db, from.AddDate(0, 0, i).Format("2006-01-02"), steps); err != nil {
+			t.Fatalf("set steps: %v", err)
+		}
+	}
+	if err := service.SetConfig(db, service.ConfigStepsGoal, "10000"); err != nil {
+		t.Fatalf("set steps goal: %v", err)
+	}
+	report, err := service.AnalyticsInsightsRange(db, from, from.AddDate(0, 0, 3), 0.10, service.InsightsGranularityDay)
+	if err != nil {
+		t.Fatalf("insights: %v", err)
+	}
+	if report.Streaks.Steps.Current != 2 || report.Streaks.Steps.Longest != 2 || report.Streaks.StepsGoal != 10000 {
+		t.Fatalf("unexpected steps streak %+v", report.Streaks)
+	}
+
+	// Step calories are credited under the "steps" type for per-type eat-back.
+	if _, err := service.AddBodyMeasurement(db, service.BodyMeasurementInput{Weight: 80, Unit: "kg", MeasuredAt: from}); err != nil {
+		t.Fatalf("add measurement: %v", err)
+	}
+	if err := service.SetGoal(db, service.SetGoalInput{Calories: 2000, ProteinG: 150, CarbsG: 200, FatG: 60, EffectiveDate: "2026-06-01"}); err != nil {
+		t.Fatalf("set goal: %v", err)
+	}
+	for key, value := range map[string]string{
+		service.ConfigStepsCalories:                                         service.StepsCaloriesExercise,
+		service.ConfigExerciseEatBackTypePrefix + service.StepsExerciseType: "50%",
+	} {
+		if err := service.SetConfig(db, key, value); err != nil {
+			t.Fatalf("set config %s: %v", key, err)
+		}
+	}
+	status, err := service.TodaySummary(db, from)
+	if err != nil {
+		t.Fatalf("today summary: %v", err)
+	}
+	// 12000 x 76 cm = 9.12 km x 80 kg x 0.5 = 365 kcal, half eaten back.
+	if status.ExerciseCalories != 0 || status.NetCalories != -365 || status.EatBackCalories != 183 {
+		t.Fatalf("expected half of step calories eaten back, got exercise=%d net=%d eat-back=%d", status.ExerciseCalories, status.NetCalories, status.EatBackCalories)
+	}
+
+	// Step calories never count toward the exercise streak.
+	report, err = service.AnalyticsInsightsRange(db, from, from.AddDate(0, 0, 3), 0.10, service.InsightsGranularityDay)
+	if err != nil {
+		t.Fatalf("insights in exercise mode: %v", err)
+	}
+	if report.Streaks.Exercise.Longest != 0 || report.Current.TotalExerciseCalories != 0 {
+		t.Fatalf("expected no exercise streak from steps alone, got %+v", report.Streaks)
+	}
+}
+
+func TestImportStepsCSV(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	if err := service.SetDailySteps(db, "2026-06-02", 5000); err != nil {
+		t.Fatalf("set steps: %v", err)
+	}
+	csv := `Start Date;Step Count
+2026-06-01 08:00;"1,200"
+2026-06-01 18:00;800
+2026-06-02;5000
+2026-06-03;7000
+oops;10
+`
+	report, err := service.ImportStepsCSV(db, strings.NewReader(csv), service.StepsImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("dry-run import: %v", err)
+	}
+	if report.Rows != 5 || report.Days != 3 || report.Inserted != 2 || report.Unchanged != 1 || report.Skipped != 1 {
+		t.Fatalf("unexpected dry-run report %+v", report)
+	}
+	if items, _ := service.ListDailySteps(db, "", ""); len(items) != 1 {
+		t.Fatalf("expected dry run to write nothing, got %+v", items)
+	}
+
+	csv = strings.Replace(csv, "2026-06-02;5000", "2026-06-02;5200", 1)
+	report, err = service.ImportStepsCSV(db, strings.NewReader(csv), service.StepsImportOptions{})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if report.Inserted != 2 || report.Updated != 1 || report.FirstDate != "2026-06-01" || report.LastDate != "2026-06-03" {
+		t.Fatalf("unexpected import report %+v", report)
+	}
+	items, err := service.ListDailySteps(db, "", "")
+	if err != nil {
+		t.Fatalf("list steps: %v", err)
+	}
+	if len(items) != 3 || items[0].Steps != 2000 || items[0].Source != "import" || items[1].Steps != 5200 {
+		t.Fatalf("expected per-day totals from the csv, got %+v", items)
+	}
+
+	if _, err := service.ImportStepsCSV(db, strings.NewReader("when,how many\n2026-06-01,10\n"), service.StepsImportOptions{}); err == nil {
+		t.Fatalf("expected missing column error")
+	}
+	if _, err := service.ImportStepsCSV(db, strings.NewReader("when,how many\n2026-06-01,10\n"), service.StepsImportOptions{DateColumn: "when", StepsColumn: "how many"}); err != nil {
+		t.Fatalf("import with explicit columns: %v", err)
+	}
+}
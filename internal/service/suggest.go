@@ -0,0 +1,283 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/model"
+)
+
+const (
+	suggestHistoryDays     = 180
+	suggestHourWindow      = 2
+	suggestRecencyHalfLife = 14.0
+	suggestFrecencyWeight  = 0.5
+	suggestTimeOfDayWeight = 0.3
+	suggestCategoryWeight  = 0.2
+)
+
+type SuggestOptions struct {
+	At       time.Time
+	Category string
+	Query    string
+	Limit    int
+}
+
+type SuggestionScore struct {
+	Score     float64 `json:"score"`
+	Frecency  float64 `json:"frecency"`
+	TimeOfDay float64 `json:"time_of_day"`
+	Category  float64 `json:"category"`
+}
+
+type SavedFoodSuggestion struct {
+	Food model.SavedFood `json:"food"`
+	SuggestionScore
+}
+
+type SavedMealSuggestion struct {
+	Meal model.SavedMeal `json:"meal"`
+	SuggestionScore
+}
+
+type suggestHistory struct {
+	total      int
+	nearHour   int
+	categories map[string]int
+}
+
+type suggestCandidate struct {
+	id              int64
+	usageCount      int
+	lastUsedAt      *time.Time
+	defaultCategory string
+}
+
+// SuggestSavedFoods ranks active saved foods by how likely they are to be
+// logged at opts.At, mixing frecency with time-of-day and category history.
+func SuggestSavedFoods(db *sql.DB, opts SuggestOptions) ([]SavedFoodSuggestion, error) {
+	opts = normalizeSuggestOptions(opts)
+	foods, err := ListSavedFoods(db, ListSavedFoodsFilter{Query: opts.Query, Limit: math.MaxInt32})
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]suggestCandidate, 0, len(foods))
+	for _, f := range foods {
+		candidates = append(candidates, suggestCandidate{id: f.ID, usageCount: f.UsageCount, lastUsedAt: f.LastUsedAt, defaultCategory: f.DefaultCategory})
+	}
+	scores, err := scoreSuggestions(db, "saved_food", candidates, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]SavedFoodSuggestion, 0, len(foods))
+	for i, f := range foods {
+		out = append(out, SavedFoodSuggestion{Food: f, SuggestionScore: scores[i]})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		return out[i].Food.Name < out[j].Food.Name
+	})
+	if len(out) > opts.Limit {
+		out = out[:opts.Limit]
+	}
+	return out, nil
+}
+
+// SuggestSavedMeals is the saved meal counterpart of SuggestSavedFoods.
+func SuggestSavedMeals(db *sql.DB, opts SuggestOptions) ([]SavedMealSuggestion, error) {
+	opts = normalizeSuggestOptions(opts)
+	meals, err := ListSavedMeals(db, ListSavedMealsFilter{Query: opts.Query, Limit: math.MaxInt32})
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]suggestCandidate, 0, len(meals))
+	for _, m := range meals {
+		candidates = append(candidates, suggestCandidate{id: m.ID, usageCount: m.UsageCount, lastUsedAt: m.LastUsedAt, defaultCategory: m.DefaultCategory})
+	}
+	scores, err := scoreSuggestions(db, "saved_meal", candidates, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]SavedMealSuggestion, 0, len(meals))
+	for i, m := range meals {
+		out = append(out, SavedMealSuggestion{Meal: m, SuggestionScore: scores[i]})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		return out[i].Meal.Name < out[j].Meal.Name
+	})
+	if len(out) > opts.Limit {
+		out = out[:opts.Limit]
+	}
+	return out, nil
+}
+
+func normalizeSuggestOptions(opts SuggestOptions) SuggestOptions {
+	if opts.At.IsZero() {
+		opts.At = time.Now()
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = 20
+	}
+	opts.Category = normalizeName(opts.Category)
+	return opts
+}
+
+func scoreSuggestions(db *sql.DB, sourceType string, candidates []suggestCandidate, opts SuggestOptions) ([]SuggestionScore, error) {
+	history, err := loadSuggestHistory(db, sourceType, opts.At)
+	if err != nil {
+		return nil, err
+	}
+	category := opts.Category
+	if category == "" {
+		category, err = inferCategoryAt(db, opts.At)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	raw := make([]float64, len(candidates))
+	maxFrecency := 0.0
+	for i, c := range candidates {
+		raw[i] = frecency(c.usageCount, c.lastUsedAt, opts.At)
+		if raw[i] > maxFrecency {
+			maxFrecency = raw[i]
+		}
+	}
+
+	out := make([]SuggestionScore, len(candidates))
+	for i, c := range candidates {
+		s := SuggestionScore{}
+		if maxFrecency > 0 {
+			s.Frecency = raw[i] / maxFrecency
+		}
+		h := history[c.id]
+		if h != nil && h.total > 0 {
+			s.TimeOfDay = float64(h.nearHour) / float64(h.total)
+			if category != "" {
+				s.Category = float64(h.categories[category]) / float64(h.total)
+			}
+		} else if category != "" && c.defaultCategory == category {
+			s.Category = 1
+		}
+		s.Score = suggestFrecencyWeight*s.Frecency + suggestTimeOfDayWeight*s.TimeOfDay + suggestCategoryWeight*s.Category
+		s.Score = math.Round(s.Score*1000) / 1000
+		out[i] = s
+	}
+	return out, nil
+}
+
+// frecency decays log-scaled usage by the time since last use.
+func frecency(usageCount int, lastUsedAt *time.Time, at time.Time) float64 {
+	if usageCount <= 0 {
+		return 0
+	}
+	weight := 0.25
+	if lastUsedAt != nil {
+		days := at.Sub(*lastUsedAt).Hours() / 24
+		if days < 0 {
+			days = 0
+		}
+		weight = math.Pow(0.5, days/suggestRecencyHalfLife)
+	}
+	return math.Log1p(float64(usageCount)) * weight
+}
+
+func loadSuggestHistory(db *sql.DB, sourceType string, at time.Time) (map[int64]*suggestHistory, error) {
+	since := at.AddDate(0, 0, -suggestHistoryDays)
+	rows, err := db.Query(`
+SELECT e.source_id, c.name, e.consumed_at
+FROM entries e
+JOIN categories c ON c.id = e.category_id
+WHERE e.source_type = ? AND e.source_id IS NOT NULL AND e.consumed_at >= ?
+`, sourceType, since.Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("load suggestion history: %w", err)
+	}
+	defer rows.Close()
+
+	out := map[int64]*suggestHistory{}
+	for rows.Next() {
+		var id int64
+		var category, consumedRaw string
+		if err := rows.Scan(&id, &category, &consumedRaw); err != nil {
+			return nil, fmt.Errorf("scan suggestion history: %w", err)
+		}
+		consumed, err := time.Parse(time.RFC3339, consumedRaw)
+		if err != nil {
+			continue
+		}
+		h := out[id]
+		if h == nil {
+			h = &suggestHistory{categories: map[string]int{}}
+			out[id] = h
+		}
+		h.total++
+		h.categories[category]++
+		if hourDistance(consumed.Hour(), at.Hour()) <= suggestHourWindow {
+			h.nearHour++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate suggestion history: %w", err)
+	}
+	return out, nil
+}
+
+// inferCategoryAt returns the category most often logged around at's hour.
+func inferCategoryAt(db *sql.DB, at time.Time) (string, error) {
+	since := at.AddDate(0, 0, -suggestHistoryDays)
+	rows, err := db.Query(`
+SELECT c.name, e.consumed_at
+FROM entries e
+JOIN categories c ON c.id = e.category_id
+WHERE e.consumed_at >= ?
+`, since.Format(time.RFC3339))
+	if err != nil {
+		return "", fmt.Errorf("load category history: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var category, consumedRaw string
+		if err := rows.Scan(&category, &consumedRaw); err != nil {
+			return "", fmt.Errorf("scan category history: %w", err)
+		}
+		consumed, err := time.Parse(time.RFC3339, consumedRaw)
+		if err != nil {
+			continue
+		}
+		if hourDistance(consumed.Hour(), at.Hour()) <= suggestHourWindow {
+			counts[category]++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("iterate category history: %w", err)
+	}
+	best := ""
+	for name, n := range counts {
+		if best == "" || n > counts[best] || (n == counts[best] && name < best) {
+			best = name
+		}
+	}
+	return best, nil
+}
+
+func hourDistance(a, b int) int {
+	d := a - b
+	if d < 0 {
+		d = -d
+	}
+	if d > 12 {
+		d = 24 - d
+	}
+	return d
+}
@@ -0,0 +1,51 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/service"
+)
+
+func TestSuggestSavedFoodsUsesTimeOfDayAndCategory(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	for _, name := range []string{"Oatmeal", "Chicken Rice", "Almonds"} {
+		if _, err := service.CreateSavedFood(db, service.CreateSavedFoodInput{Name: name, Calories: 300}); err != nil {
+			t.Fatalf("create saved food: %v", err)
+		}
+	}
+	day := time.Date(2026, 2, 20, 0, 0, 0, 0, time.Local)
+	for i := 0; i < 3; i++ {
+		if _, err := service.LogSavedFood(db, service.LogSavedFoodInput{Identifier: "Oatmeal", Servings: 1, Category: "breakfast", ConsumedAt: day.AddDate(0, 0, -i).Add(8 * time.Hour)}); err != nil {
+			t.Fatalf("log saved food: %v", err)
+		}
+	}
+	if _, err := service.LogSavedFood(db, service.LogSavedFoodInput{Identifier: "Chicken Rice", Servings: 1, Category: "dinner", ConsumedAt: day.Add(19 * time.Hour)}); err != nil {
+		t.Fatalf("log saved food: %v", err)
+	}
+
+	morning, err := service.SuggestSavedFoods(db, service.SuggestOptions{At: day.AddDate(0, 0, 1).Add(8*time.Hour + 30*time.Minute)})
+	if err != nil {
+		t.Fatalf("suggest saved foods: %v", err)
+	}
+	if len(morning) != 3 || morning[0].Food.Name != "Oatmeal" {
+		t.Fatalf("expected Oatmeal first in the morning, got %+v", morning)
+	}
+	if morning[0].Category != 1 || morning[0].TimeOfDay != 1 {
+		t.Fatalf("expected full time-of-day and category match, got %+v", morning[0].SuggestionScore)
+	}
+
+	evening, err := service.SuggestSavedFoods(db, service.SuggestOptions{At: day.AddDate(0, 0, 1).Add(19 * time.Hour)})
+	if err != nil {
+		t.Fatalf("suggest saved foods: %v", err)
+	}
+	if evening[0].Food.Name != "Chicken Rice" {
+		t.Fatalf("expected Chicken Rice first in the evening, got %+v", evening)
+	}
+	if evening[len(evening)-1].Food.Name != "Almonds" {
+		t.Fatalf("expected unused food last, got %+v", evening)
+	}
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// KcalPerKgBodyWeight approximates the energy stored in one kilogram of
+	// body-weight change (mixed fat and lean tissue).
+	KcalPerKgBodyWeight = 7700
+
+	DefaultTDEEWindowDays = 28
+
+	TDEEConfidenceHigh   = "high"
+	TDEEConfidenceMedium = "medium"
+	TDEEConfidenceLow    = "low"
+)
+
+type TDEEEstimateOptions struct {
+	// End is the last day included; zero means yesterday, since today's log is
+	// usually incomplete.
+	End        time.Time
+	WindowDays int
+}
+
+// TDEEEstimate is an energy-balance estimate of daily expenditure: average
+// intake minus the energy stored or released by the weight trend.
+type TDEEEstimate struct {
+	FromDate              string  `json:"from_date"`
+	ToDate                string  `json:"to_date"`
+	WindowDays            int     `json:"window_days"`
+	LoggedDays            int     `json:"logged_days"`
+	LoggingCompleteness   float64 `json:"logging_completeness"`
+	AvgIntakeCalories     float64 `json:"avg_intake_calories"`
+	WeighIns              int     `json:"weigh_ins"`
+	StartTrendWeightKg    float64 `json:"start_trend_weight_kg"`
+	EndTrendWeightKg      float64 `json:"end_trend_weight_kg"`
+	WeightChangeKg        float64 `json:"weight_change_kg"`
+	WeightChangeKgPerWeek float64 `json:"weight_change_kg_per_week"`
+	EstimatedTDEE         int     `json:"estimated_tdee"`
+	Low                   int     `json:"low"`
+	High                  int     `json:"high"`
+	Confidence            string  `json:"confidence"`
+}
+
+// ParseWindowDays accepts a day count with an optional unit: "28", "28d", or
+// "4w".
+func ParseWindowDays(raw string) (int, error) {
+	value := strings.ToLower(strings.TrimSpace(raw))
+	if value == "" {
+		return 0, fmt.Errorf("window is required")
+	}
+	multiplier := 1
+	switch {
+	case strings.HasSuffix(value, "w"):
+		multiplier = 7
+		value = strings.TrimSuffix(value, "w")
+	case strings.HasSuffix(value, "d"):
+		value = strings.TrimSuffix(value, "d")
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid window %q (expected e.g. 28d or 4w)", raw)
+	}
+	return n * multiplier, nil
+}
+
+// EstimateTDEE derives expenditure over the window from logged intake and a
+// least-squares weight trend. The band is a 95% interval combining the
+// uncertainty of the weight slope and of the average intake.
+func EstimateTDEE(db *sql.DB, opts TDEEEstimateOptions) (*TDEEEstimate, error) {
+	if opts.WindowDays == 0 {
+		opts.WindowDays = DefaultTDEEWindowDays
+	}
+	if opts.WindowDays < 7 {
+		return nil, fmt.Errorf("window must be at least 7 days")
+	}
+	end := opts.End
+	if end.IsZero() {
+		end = time.Now().AddDate(0, 0, -1)
+	}
+	end = beginningOfDay(end)
+	from := end.AddDate(0, 0, -(opts.WindowDays - 1))
+
+	out := &TDEEEstimate{
+		FromDate:   from.Format("2006-01-02"),
+		ToDate:     end.Format("2006-01-02"),
+		WindowDays: opts.WindowDays,
+	}
+
+	intakeByDay, err := loadIntakeDaySummaries(db, from, end)
+	if err != nil {
+		return nil, err
+	}
+	if len(intakeByDay) == 0 {
+		return nil, fmt.Errorf("no food logged between %s and %s", out.FromDate, out.ToDate)
+	}
+	intake := make([]float64, 0, len(intakeByDay))
+	for _, d := range intakeByDay {
+		intake = append(intake, float64(d.IntakeCalories))
+	}
+	out.LoggedDays = len(intake)
+	out.LoggingCompleteness = math.Round(float64(out.LoggedDays)/float64(opts.WindowDays)*100) / 100
+	out.AvgIntakeCalories = math.Round(avg(intake)*10) / 10
+
+	days, weights, err := loadDailyWeights(db, from, end)
+	if err != nil {
+		return nil, err
+	}
+	out.WeighIns = len(weights)
+	if out.WeighIns < 2 {
+		return nil, fmt.Errorf("need at least 2 weigh-ins between %s and %s (found %d)", out.FromDate, out.ToDate, out.WeighIns)
+	}
+	slope, intercept, slopeSE := weightTrend(days, weights)
+	lastDay := float64(opts.WindowDays - 1)
+	out.StartTrendWeightKg = math.Round(intercept*100) / 100
+	out.EndTrendWeightKg = math.Round((intercept+slope*lastDay)*100) / 100
+	out.WeightChangeKg = math.Round(slope*lastDay*100) / 100
+	out.WeightChangeKgPerWeek = math.Round(slope*7*100) / 100
+
+	tdee := out.AvgIntakeCalories - slope*KcalPerKgBodyWeight
+	intakeSE := 0.0
+	if len(intake) > 1 {
+		intakeSE = stdDev(intake) / math.Sqrt(float64(len(intake)))
+	}
+	margin := 1.96 * math.Hypot(slopeSE*KcalPerKgBodyWeight, intakeSE)
+	out.EstimatedTDEE = int(math.Round(tdee))
+	out.Low = int(math.Round(tdee - margin))
+	out.High = int(math.Round(tdee + margin))
+
+	switch {
+	case out.LoggingCompleteness >= 0.85 && out.WeighIns >= 8:
+		out.Confidence = TDEEConfidenceHigh
+	case out.LoggingCompleteness >= 0.6 && out.WeighIns >= 4:
+		out.Confidence = TDEEConfidenceMedium
+	default:
+		out.Confidence = TDEEConfidenceLow
+	}
+	return out, nil
+}
+
+// loadDailyWeights returns each weigh-in day as an offset from from together
+// with that day's average weight.
+func loadDailyWeights(db *sql.DB, from, to time.Time) ([]float64, []float64, error) {
+	rows, err := db.Query(`
+SELECT substr(measured_at, 1, 10) as day, AVG(weight_kg)
+FROM body_measurements
+WHERE measured_at >= ? AND measured_at < ?
+GROUP BY day
+ORDER BY day ASC
+`, from.Format(time.RFC3339), to.Add(24*time.Hour).Format(time.RFC3339))
+	if err != nil {
+		return nil, nil, fmt.Errorf("query daily weights: %w", err)
+	}
+	defer rows.Close()
+
+	days := make([]float64, 0)
+	weights := make([]float64, 0)
+	for rows.Next() {
+		var day string
+		var weight float64
+		if err := rows.Scan(&day, &weight); err != nil {
+			return nil, nil, fmt.Errorf("scan daily weight: %w", err)
+		}
+		t, err := time.ParseInLocation("2006-01-02", day, time.Local)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parse weigh-in day %q: %w", day, err)
+		}
+		days = append(days, math.Round(t.Sub(from).Hours()/24))
+		weights = append(weights, weight)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("iterate daily weights: %w", err)
+	}
+	return days, weights, nil
+}
+
+// weightTrend fits weight = intercept + slope*day and returns the standard
+// error of the slope (zero when there are too few points to estimate it).
+func weightTrend(days, weights []float64) (slope, intercept, slopeSE float64) {
+	n := float64(len(days))
+	meanX := avg(days)
+	meanY := avg(weights)
+	var sxx, sxy float64
+	for i := range days {
+		sxx += (days[i] - meanX) * (days[i] - meanX)
+		sxy += (days[i] - meanX) * (weights[i] - meanY)
+	}
+	if sxx == 0 {
+		return 0, meanY, 0
+	}
+	slope = sxy / sxx
+	intercept = meanY - slope*meanX
+	if n > 2 {
+		var ssr float64
+		for i := range days {
+			r := weights[i] - (intercept + slope*days[i])
+			ssr += r * r
+		}
+		slopeSE = math.Sqrt(ssr / (n - 2) / sxx)
+	}
+	return slope, intercept, slopeSE
+}
+
+func stdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	mean := avg(values)
+	var ss float64
+	for _, v := range values {
+		ss += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(ss / float64(len(values)-1))
+}
@@ -0,0 +1,57 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/service"
+)
+
+func TestEstimateTDEEFromIntakeAndWeightTrend(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)
+	for i := 0; i < 28; i++ {
+		day := start.AddDate(0, 0, i)
+		if i == 10 || i == 20 {
+			continue
+		}
+		if _, err := service.CreateEntry(db, service.CreateEntryInput{Name: "Day", Calories: 2200, ProteinG: 150, CarbsG: 220, FatG: 75, Category: "dinner", Consumed: day.Add(19 * time.Hour), SourceType: "manual"}); err != nil {
+			t.Fatalf("create entry day %d: %v", i, err)
+		}
+		if i%2 == 0 {
+			if _, err := service.AddBodyMeasurement(db, service.BodyMeasurementInput{Weight: 80 - 0.05*float64(i), Unit: "kg", MeasuredAt: day.Add(7 * time.Hour)}); err != nil {
+				t.Fatalf("add weight day %d: %v", i, err)
+			}
+		}
+	}
+
+	est, err := service.EstimateTDEE(db, service.TDEEEstimateOptions{End: start.AddDate(0, 0, 27), WindowDays: 28})
+	if err != nil {
+		t.Fatalf("estimate tdee: %v", err)
+	}
+	if est.EstimatedTDEE != 2585 {
+		t.Fatalf("expected 2200 kcal intake + 0.05 kg/day loss to give 2585 kcal, got %+v", est)
+	}
+	if est.LoggedDays != 26 || est.LoggingCompleteness != 0.93 || est.WeighIns != 12 {
+		t.Fatalf("unexpected logging completeness %+v", est)
+	}
+	if est.Confidence != service.TDEEConfidenceHigh || est.Low > est.EstimatedTDEE || est.High < est.EstimatedTDEE {
+		t.Fatalf("unexpected confidence band %+v", est)
+	}
+	if est.WeightChangeKgPerWeek != -0.35 {
+		t.Fatalf("expected -0.35 kg/week trend, got %.2f", est.WeightChangeKgPerWeek)
+	}
+
+	if _, err := service.EstimateTDEE(db, service.TDEEEstimateOptions{End: start.AddDate(0, 0, 60), WindowDays: 28}); err == nil {
+		t.Fatalf("expected empty window to fail")
+	}
+	for raw, want := range map[string]int{"28d": 28, "4w": 28, "14": 14} {
+		got, err := service.ParseWindowDays(raw)
+		if err != nil || got != want {
+			t.Fatalf("parse window %q: expected %d, got %d (%v)", raw, want, got, err)
+		}
+	}
+}
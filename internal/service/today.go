@@ -6,22 +6,32 @@ import (
 )
 
 type TodayStatus struct {
-	Date              string  `json:"date"`
-	IntakeCalories    int     `json:"intake_calories"`
-	ExerciseCalories  int     `json:"exercise_calories"`
-	NetCalories       int     `json:"net_calories"`
-	ProteinG          float64 `json:"protein_g"`
-	CarbsG            float64 `json:"carbs_g"`
-	FatG              float64 `json:"fat_g"`
-	GoalCalories      int     `json:"goal_calories,omitempty"`
-	GoalProteinG      float64 `json:"goal_protein_g,omitempty"`
-	GoalCarbsG        float64 `json:"goal_carbs_g,omitempty"`
-	GoalFatG          float64 `json:"goal_fat_g,omitempty"`
-	RemainingCalories int     `json:"remaining_calories,omitempty"`
-	RemainingProteinG float64 `json:"remaining_protein_g,omitempty"`
-	RemainingCarbsG   float64 `json:"remaining_carbs_g,omitempty"`
-	RemainingFatG     float64 `json:"remaining_fat_g,omitempty"`
-	HasGoal           bool    `json:"has_goal"`
+	Date              string    `json:"date"`
+	IntakeCalories    int       `json:"intake_calories"`
+	ExerciseCalories  int       `json:"exercise_calories"`
+	NetCalories       int       `json:"net_calories"`
+	ProteinG          float64   `json:"protein_g"`
+	CarbsG            float64   `json:"carbs_g"`
+	FatG              float64   `json:"fat_g"`
+	GoalCalories      int       `json:"goal_calories,omitempty"`
+	GoalProteinG      float64   `json:"goal_protein_g,omitempty"`
+	GoalCarbsG        float64   `json:"goal_carbs_g,omitempty"`
+	GoalFatG          float64   `json:"goal_fat_g,omitempty"`
+	RemainingCalories int       `json:"remaining_calories,omitempty"`
+	RemainingProteinG float64   `json:"remaining_protein_g,omitempty"`
+	RemainingCarbsG   float64   `json:"remaining_carbs_g,omitempty"`
+	RemainingFatG     float64   `json:"remaining_fat_g,omitempty"`
+	HasGoal           bool      `json:"has_goal"`
+	GoalSchedule      string    `json:"goal_schedule,omitempty"`
+	DayType           string    `json:"day_type,omitempty"`
+	EatBackCalories   int       `json:"eat_back_calories"`
+	EatBackPolicy     string    `json:"eat_back_policy"`
+	Steps             *StepsDay `json:"steps,omitempty"`
+	StepsGoal         int       `json:"steps_goal,omitempty"`
+
+	NutrientTargets []NutrientTargetStatus `json:"nutrient_targets,omitempty"`
+	WeeklyBudget    *WeeklyBudgetStatus    `json:"weekly_budget,omitempty"`
+	Phase           *PhaseStatus           `json:"phase,omitempty"`
 }
 
 func TodaySummary(db *sql.DB, date time.Time) (*TodayStatus, error) {
@@ -33,25 +43,72 @@ func TodaySummary(db *sql.DB, date time.Time) (*TodayStatus, error) {
 	status := &TodayStatus{Date: start.Format("2006-01-02")}
 	status.IntakeCalories = report.TotalIntakeCalories
 	status.ExerciseCalories = report.TotalExerciseCalories
-	status.NetCalories = report.TotalNetCalories
 	status.ProteinG = report.TotalProtein
 	status.CarbsG = report.TotalCarbs
 	status.FatG = report.TotalFat
+	status.Phase = report.Phase
 
-	goal, err := CurrentGoal(db, status.Date)
+	steps, err := loadStepsByDay(db, start, start)
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := steps[status.Date]; ok {
+		status.Steps = &s
+	}
+	stepCalories := steps[status.Date].exerciseCalories()
+	status.NetCalories = status.IntakeCalories - status.ExerciseCalories - stepCalories
+	stepsSettings, err := LoadStepsSettings(db)
+	if err != nil {
+		return nil, err
+	}
+	status.StepsGoal = stepsSettings.Goal
+
+	goal, err := ResolveGoalForDate(db, status.Date)
 	if err != nil {
 		return nil, err
 	}
 	if goal != nil {
 		status.HasGoal = true
+		status.GoalSchedule = goal.Schedule
+		status.DayType = goal.DayType
 		status.GoalCalories = goal.Calories
 		status.GoalProteinG = goal.ProteinG
 		status.GoalCarbsG = goal.CarbsG
 		status.GoalFatG = goal.FatG
-		status.RemainingCalories = goal.Calories - status.NetCalories
-		status.RemainingProteinG = goal.ProteinG - status.ProteinG
-		status.RemainingCarbsG = goal.CarbsG - status.CarbsG
-		status.RemainingFatG = goal.FatG - status.FatG
+		eatBack, err := LoadEatBackSettings(db)
+		if err != nil {
+			return nil, err
+		}
+		status.EatBackPolicy = eatBack.String()
+		status.EatBackCalories, err = eatBack.eatBackCredit(db, status.Date, status.ExerciseCalories, stepCalories)
+		if err != nil {
+			return nil, err
+		}
+		calories, protein, carbs, fat := effectiveGoalTargets(goal.Goal, status.EatBackCalories)
+		status.RemainingCalories = calories - status.IntakeCalories
+		status.RemainingProteinG = protein - status.ProteinG
+		status.RemainingCarbsG = carbs - status.CarbsG
+		status.RemainingFatG = fat - status.FatG
+
+		targets, err := ParseNutrientTargetsJSON(goal.NutrientTargets)
+		if err != nil {
+			return nil, err
+		}
+		status.NutrientTargets, err = EvaluateNutrientTargets(db, status.Date, targets)
+		if err != nil {
+			return nil, err
+		}
+
+		weekly, err := WeeklyBudgetEnabled(db)
+		if err != nil {
+			return nil, err
+		}
+		if weekly {
+			status.WeeklyBudget, err = WeeklyBudget(db, start)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 	return status, nil
 }
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const ConfigWeeklyBudget = "weekly_budget"
+
+// WeeklyBudgetStatus tracks a Monday-to-Sunday calorie budget built from each
+// day's goal. Calories left unused on earlier days are banked and spread over
+// the days left in the week; overeating borrows from them.
+type WeeklyBudgetStatus struct {
+	WeekStart         string `json:"week_start"`
+	WeekEnd           string `json:"week_end"`
+	BudgetCalories    int    `json:"budget_calories"`
+	ConsumedCalories  int    `json:"consumed_calories"`
+	RemainingCalories int    `json:"remaining_calories"`
+	BankedCalories    int    `json:"banked_calories"`
+	DaysLeft          int    `json:"days_left"`
+	DailyAllowance    int    `json:"daily_allowance"`
+	RemainingToday    int    `json:"remaining_today"`
+}
+
+type WeekBudgetSummary struct {
+	WeekStart       string `json:"week_start"`
+	WeekEnd         string `json:"week_end"`
+	EvaluatedDays   int    `json:"evaluated_days"`
+	BudgetCalories  int    `json:"budget_calories"`
+	IntakeCalories  int    `json:"intake_calories"`
+	BalanceCalories int    `json:"balance_calories"`
+	WithinBudget    bool   `json:"within_budget"`
+}
+
+// WeeklyAdherenceSummary evaluates calories per week instead of per day: a
+// week is within budget when its evaluated days' intake fits their combined
+// effective goals.
+type WeeklyAdherenceSummary struct {
+	EvaluatedWeeks    int                 `json:"evaluated_weeks"`
+	WithinBudgetWeeks int                 `json:"within_budget_weeks"`
+	PercentWithin     float64             `json:"percent_within_budget"`
+	Weeks             []WeekBudgetSummary `json:"weeks"`
+}
+
+func WeeklyBudgetEnabled(db *sql.DB) (bool, error) {
+	value, ok, err := GetConfig(db, ConfigWeeklyBudget)
+	if err != nil || !ok || strings.TrimSpace(value) == "" {
+		return false, err
+	}
+	enabled, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		return false, fmt.Errorf("invalid config %s=%q (expected true|false)", ConfigWeeklyBudget, value)
+	}
+	return enabled, nil
+}
+
+// WeeklyBudget returns the budget for the week containing date, or nil when no
+// goal applies on date. Days without a goal are left out of the budget.
+func WeeklyBudget(db *sql.DB, date time.Time) (*WeeklyBudgetStatus, error) {
+	day := beginningOfDay(date)
+	weekStart := beginningOfWeekLocal(day)
+	weekEnd := weekStart.AddDate(0, 0, 6)
+	out := &WeeklyBudgetStatus{
+		WeekStart: weekStart.Format("2006-01-02"),
+		WeekEnd:   weekEnd.Format("2006-01-02"),
+	}
+
+	intakeByDay, err := loadIntakeDaySummaries(db, weekStart, day)
+	if err != nil {
+		return nil, err
+	}
+	exerciseByDay, err := loadExerciseCaloriesByDay(db, weekStart, day)
+	if err != nil {
+		return nil, err
+	}
+	steps, err := loadStepsByDay(db, weekStart, day)
+	if err != nil {
+		return nil, err
+	}
+	eatBack, err := LoadEatBackSettings(db)
+	if err != nil {
+		return nil, err
+	}
+
+	today := day.Format("2006-01-02")
+	todayAllowance := 0
+	todayIntake := 0
+	for d := weekStart; !d.After(weekEnd); d = d.AddDate(0, 0, 1) {
+		key := d.Format("2006-01-02")
+		goal, err := ResolveGoalForDate(db, key)
+		if err != nil {
+			return nil, err
+		}
+		if goal == nil {
+			if key == today {
+				return nil, nil
+			}
+			continue
+		}
+		dayBudget := goal.Calories
+		if !d.After(day) {
+			credit, err := eatBack.eatBackCredit(db, key, exerciseByDay[key], steps[key].exerciseCalories())
+			if err != nil {
+				return nil, err
+			}
+			dayBudget += credit
+		}
+		out.BudgetCalories += dayBudget
+		intake := intakeByDay[key].IntakeCalories
+		switch {
+		case d.Before(day):
+			out.BankedCalories += dayBudget - intake
+			out.ConsumedCalories += intake
+		case key == today:
+			todayAllowance = dayBudget
+			todayIntake = intake
+			out.ConsumedCalories += intake
+			out.DaysLeft++
+		default:
+			out.DaysLeft++
+		}
+	}
+	out.RemainingCalories = out.BudgetCalories - out.ConsumedCalories
+	out.DailyAllowance = todayAllowance + int(math.Round(float64(out.BankedCalories)/float64(out.DaysLeft)))
+	out.RemainingToday = out.DailyAllowance - todayIntake
+	return out, nil
+}
+
+// calculateWeeklyAdherence groups days that had a goal into Monday-based weeks
+// and compares total intake with total effective goal calories.
+func calculateWeeklyAdherence(days []DaySummary) (WeeklyAdherenceSummary, error) {
+	out := WeeklyAdherenceSummary{Weeks: make([]WeekBudgetSummary, 0)}
+	index := map[string]int{}
+	for _, d := range days {
+		if d.EffectiveGoalCalories <= 0 {
+			continue
+		}
+		date, err := time.ParseInLocation("2006-01-02", d.Date, time.Local)
+		if err != nil {
+			return out, fmt.Errorf("parse day %q: %w", d.Date, err)
+		}
+		start := beginningOfWeekLocal(date)
+		key := start.Format("2006-01-02")
+		i, ok := index[key]
+		if !ok {
+			out.Weeks = append(out.Weeks, WeekBudgetSummary{WeekStart: key, WeekEnd: start.AddDate(0, 0, 6).Format("2006-01-02")})
+			i = len(out.Weeks) - 1
+			index[key] = i
+		}
+		w := &out.Weeks[i]
+		w.EvaluatedDays++
+		w.BudgetCalories += d.EffectiveGoalCalories
+		w.IntakeCalories += d.IntakeCalories
+	}
+	for i := range out.Weeks {
+		w := &out.Weeks[i]
+		w.BalanceCalories = w.BudgetCalories - w.IntakeCalories
+		w.WithinBudget = w.BalanceCalories >= 0
+		out.EvaluatedWeeks++
+		if w.WithinBudget {
+			out.WithinBudgetWeeks++
+		}
+	}
+	if out.EvaluatedWeeks > 0 {
+		out.PercentWithin = float64(out.WithinBudgetWeeks) / float64(out.EvaluatedWeeks) * 100
+	}
+	return out, nil
+}
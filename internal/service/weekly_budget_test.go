@@ -0,0 +1,61 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/service"
+)
+
+func TestWeeklyBudgetBanksAndBorrows(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	if err := service.SetGoal(db, service.SetGoalInput{Calories: 2000, ProteinG: 150, CarbsG: 200, FatG: 70, EffectiveDate: "2026-02-01"}); err != nil {
+		t.Fatalf("set goal: %v", err)
+	}
+	monday := time.Date(2026, 2, 9, 0, 0, 0, 0, time.Local)
+	for i, kcal := range []int{1500, 2300, 800} {
+		if _, err := service.CreateEntry(db, service.CreateEntryInput{Name: "Meal", Calories: kcal, ProteinG: 100, CarbsG: 150, FatG: 50, Category: "dinner", Consumed: monday.AddDate(0, 0, i).Add(19 * time.Hour), SourceType: "manual"}); err != nil {
+			t.Fatalf("create entry day %d: %v", i, err)
+		}
+	}
+
+	status, err := service.TodaySummary(db, monday.AddDate(0, 0, 2))
+	if err != nil {
+		t.Fatalf("today summary: %v", err)
+	}
+	if status.WeeklyBudget != nil {
+		t.Fatalf("expected no weekly budget until enabled, got %+v", status.WeeklyBudget)
+	}
+	if err := service.SetConfig(db, service.ConfigWeeklyBudget, "true"); err != nil {
+		t.Fatalf("enable weekly budget: %v", err)
+	}
+	status, err = service.TodaySummary(db, monday.AddDate(0, 0, 2))
+	if err != nil {
+		t.Fatalf("today summary with weekly budget: %v", err)
+	}
+	w := status.WeeklyBudget
+	if w == nil || w.WeekStart != "2026-02-09" || w.BudgetCalories != 14000 || w.RemainingCalories != 9400 {
+		t.Fatalf("unexpected weekly budget %+v", w)
+	}
+	if w.BankedCalories != 200 || w.DaysLeft != 5 || w.DailyAllowance != 2040 || w.RemainingToday != 1240 {
+		t.Fatalf("expected 200 kcal banked across 5 days, got %+v", w)
+	}
+	if status.RemainingCalories != 1200 {
+		t.Fatalf("expected daily remaining to stay on the day's goal, got %d", status.RemainingCalories)
+	}
+
+	report, err := service.AnalyticsRange(db, monday, monday.AddDate(0, 0, 6), 0.10)
+	if err != nil {
+		t.Fatalf("analytics: %v", err)
+	}
+	if report.Adherence.WithinGoalDays != 0 {
+		t.Fatalf("expected no day within macro tolerance, got %+v", report.Adherence)
+	}
+	weekly := report.WeeklyAdherence
+	if weekly.EvaluatedWeeks != 1 || weekly.WithinBudgetWeeks != 1 || weekly.Weeks[0].BalanceCalories != 1400 {
+		t.Fatalf("expected one week within budget with 1400 kcal to spare, got %+v", weekly)
+	}
+}
@@ -0,0 +1,531 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/model"
+)
+
+const (
+	// DefaultWorkoutType is the exercise type of logs created by AddWorkout.
+	DefaultWorkoutType = "strength"
+	MuscleGroupOther   = "other"
+
+	maxSetsPerSpec = 50
+)
+
+// defaultLiftMuscleGroups maps common lifts to the muscle group their volume
+// counts toward. Other lifts count as "other" unless a group is given.
+var defaultLiftMuscleGroups = map[string]string{
+	"bench press":         "chest",
+	"incline bench press": "chest",
+	"dumbbell press":      "chest",
+	"chest fly":           "chest",
+	"dip":                 "chest",
+	"push-up":             "chest",
+	"squat":               "legs",
+	"front squat":         "legs",
+	"leg press":           "legs",
+	"lunge":               "legs",
+	"leg extension":       "legs",
+	"leg curl":            "legs",
+	"romanian deadlift":   "legs",
+	"calf raise":          "legs",
+	"hip thrust":          "glutes",
+	"deadlift":            "back",
+	"barbell row":         "back",
+	"row":                 "back",
+	"pull-up":             "back",
+	"chin-up":             "back",
+	"lat pulldown":        "back",
+	"overhead press":      "shoulders",
+	"lateral raise":       "shoulders",
+	"face pull":           "shoulders",
+	"curl":                "arms",
+	"bicep curl":          "arms",
+	"tricep extension":    "arms",
+	"skull crusher":       "arms",
+	"plank":               "core",
+	"crunch":              "core",
+	"hanging leg raise":   "core",
+}
+
+// WorkoutSetInput describes Sets identical sets of one lift.
+type WorkoutSetInput struct {
+	Lift        string
+	MuscleGroup string
+	Sets        int
+	Reps        int
+	WeightKg    float64
+	RPE         *float64
+}
+
+// WorkoutInput adds sets to ExerciseLogID, or to a new exercise log built from
+// Log when ExerciseLogID is zero.
+type WorkoutInput struct {
+	ExerciseLogID int64
+	Log           ExerciseLogInput
+	Sets          []WorkoutSetInput
+}
+
+type WorkoutLift struct {
+	Lift        string             `json:"lift"`
+	MuscleGroup string             `json:"muscle_group"`
+	Sets        []model.WorkoutSet `json:"sets"`
+	Reps        int                `json:"reps"`
+	VolumeKg    float64            `json:"volume_kg"`
+	BestE1RMKg  float64            `json:"best_e1rm_kg,omitempty"`
+}
+
+type Workout struct {
+	ExerciseLog model.ExerciseLog `json:"exercise_log"`
+	Lifts       []WorkoutLift     `json:"lifts"`
+	Sets        int               `json:"sets"`
+	Reps        int               `json:"reps"`
+	VolumeKg    float64           `json:"volume_kg"`
+}
+
+type WorkoutSummary struct {
+	ExerciseLogID  int64     `json:"exercise_log_id"`
+	PerformedAt    time.Time `json:"performed_at"`
+	ExerciseType   string    `json:"exercise_type"`
+	CaloriesBurned int       `json:"calories_burned"`
+	Lifts          int       `json:"lifts"`
+	Sets           int       `json:"sets"`
+	Reps           int       `json:"reps"`
+	VolumeKg       float64   `json:"volume_kg"`
+}
+
+type WorkoutFilter struct {
+	FromDate string
+	ToDate   string
+	Limit    int
+}
+
+// ParseWorkoutSet parses LIFT=[SETSx]REPS[@WEIGHT][ rpeN], for example
+// "bench press=3x8@80 rpe8". A weight without a kg or lb suffix is in unit
+// (default kg); no weight means a bodyweight set.
+func ParseWorkoutSet(spec, unit string) (WorkoutSetInput, error) {
+	lift, value, ok := strings.Cut(spec, "=")
+	lift = normalizeName(lift)
+	if !ok || lift == "" {
+		return WorkoutSetInput{}, fmt.Errorf("invalid set %q (use LIFT=SETSxREPS@WEIGHT)", spec)
+	}
+	fields := strings.Fields(strings.ToLower(value))
+	if len(fields) == 0 || len(fields) > 2 {
+		return WorkoutSetInput{}, fmt.Errorf("invalid set %q (use LIFT=SETSxREPS@WEIGHT [rpeN])", spec)
+	}
+	out := WorkoutSetInput{Lift: lift, Sets: 1}
+	if len(fields) == 2 {
+		raw, ok := strings.CutPrefix(fields[1], "rpe")
+		if !ok {
+			return WorkoutSetInput{}, fmt.Errorf("invalid set %q: expected rpeN after the load", spec)
+		}
+		rpe, err := strconv.ParseFloat(strings.TrimPrefix(raw, "="), 64)
+		if err != nil {
+			return WorkoutSetInput{}, fmt.Errorf("invalid RPE in set %q", spec)
+		}
+		out.RPE = &rpe
+	}
+
+	volume, weight, hasWeight := strings.Cut(fields[0], "@")
+	repsRaw := volume
+	if setsRaw, reps, ok := strings.Cut(volume, "x"); ok {
+		sets, err := strconv.Atoi(setsRaw)
+		if err != nil {
+			return WorkoutSetInput{}, fmt.Errorf("invalid set count in %q", spec)
+		}
+		out.Sets, repsRaw = sets, reps
+	}
+	reps, err := strconv.Atoi(repsRaw)
+	if err != nil {
+		return WorkoutSetInput{}, fmt.Errorf("invalid reps in %q", spec)
+	}
+	out.Reps = reps
+	if hasWeight {
+		kg, err := parseLiftWeight(weight, unit)
+		if err != nil {
+			return WorkoutSetInput{}, fmt.Errorf("invalid weight in %q: %w", spec, err)
+		}
+		out.WeightKg = kg
+	}
+	return out, validateWorkoutSet(out)
+}
+
+func parseLiftWeight(raw, unit string) (float64, error) {
+	for _, suffix := range []string{"kg", "lbs", "lb"} {
+		if v, ok := strings.CutSuffix(raw, suffix); ok {
+			raw, unit = v, suffix
+			break
+		}
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", raw)
+	}
+	return convertWeightToKg(v, unit)
+}
+
+func validateWorkoutSet(in WorkoutSetInput) error {
+	if normalizeName(in.Lift) == "" {
+		return fmt.Errorf("lift name is required")
+	}
+	if in.Sets <= 0 || in.Sets > maxSetsPerSpec {
+		return fmt.Errorf("set count for %s must be between 1 and %d", in.Lift, maxSetsPerSpec)
+	}
+	if in.Reps <= 0 {
+		return fmt.Errorf("reps for %s must be > 0", in.Lift)
+	}
+	if in.WeightKg < 0 {
+		return fmt.Errorf("weight for %s must be >= 0", in.Lift)
+	}
+	if in.RPE != nil && (*in.RPE < 1 || *in.RPE > 10) {
+		return fmt.Errorf("RPE for %s must be between 1 and 10", in.Lift)
+	}
+	return nil
+}
+
+// LiftMuscleGroup returns the default muscle group for a lift.
+func LiftMuscleGroup(lift string) string {
+	if group, ok := defaultLiftMuscleGroups[normalizeName(lift)]; ok {
+		return group
+	}
+	return MuscleGroupOther
+}
+
+// EstimatedOneRepMax applies the Epley formula. With an RPE below 10 the reps
+// left in reserve count toward the set, so 5 reps at RPE 8 estimate like 7.
+func EstimatedOneRepMax(weightKg float64, reps int, rpe *float64) float64 {
+	if weightKg <= 0 || reps <= 0 {
+		return 0
+	}
+	effective := float64(reps)
+	if rpe != nil && *rpe < 10 {
+		effective += 10 - *rpe
+	}
+	if effective <= 1 {
+		return roundTo(weightKg, 1)
+	}
+	return roundTo(weightKg*(1+effective/30), 1)
+}
+
+// AddWorkout records sets in one transaction and returns the exercise log id.
+// Each set input expands to one row per set.
+func AddWorkout(db *sql.DB, in WorkoutInput) (int64, error) {
+	if len(in.Sets) == 0 {
+		return 0, fmt.Errorf("at least one set is required")
+	}
+	for _, set := range in.Sets {
+		if err := validateWorkoutSet(set); err != nil {
+			return 0, err
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin workout tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	logID := in.ExerciseLogID
+	if logID > 0 {
+		var exists int
+		if err := tx.QueryRow(`SELECT 1 FROM exercise_logs WHERE id = ?`, logID).Scan(&exists); err != nil {
+			if err == sql.ErrNoRows {
+				return 0, fmt.Errorf("exercise log %d not found", logID)
+			}
+			return 0, fmt.Errorf("check exercise log %d: %w", logID, err)
+		}
+	} else {
+		if strings.TrimSpace(in.Log.ExerciseType) == "" {
+			in.Log.ExerciseType = DefaultWorkoutType
+		}
+		normalized, err := normalizeExerciseInput(in.Log, false)
+		if err != nil {
+			return 0, err
+		}
+		if logID, err = insertExerciseLog(tx, normalized); err != nil {
+			return 0, err
+		}
+	}
+
+	var position int
+	if err := tx.QueryRow(`SELECT IFNULL(MAX(position), 0) FROM workout_sets WHERE exercise_log_id = ?`, logID).Scan(&position); err != nil {
+		return 0, fmt.Errorf("read workout set position: %w", err)
+	}
+	for _, set := range in.Sets {
+		lift := normalizeName(set.Lift)
+		group := normalizeName(set.MuscleGroup)
+		if group == "" {
+			group = LiftMuscleGroup(lift)
+		}
+		for range set.Sets {
+			position++
+			if _, err := tx.Exec(`
+INSERT INTO workout_sets(exercise_log_id, position, lift, muscle_group, reps, weight_kg, rpe)
+VALUES(?, ?, ?, ?, ?, ?, ?)
+`, logID, position, lift, group, set.Reps, set.WeightKg, set.RPE); err != nil {
+				return 0, fmt.Errorf("add workout set for %s: %w", lift, err)
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit workout: %w", err)
+	}
+	return logID, nil
+}
+
+// GetWorkout returns an exercise log with its sets grouped by lift in the
+// order each lift was first performed.
+func GetWorkout(db *sql.DB, exerciseLogID int64) (*Workout, error) {
+	log, err := GetExerciseLog(db, exerciseLogID)
+	if err != nil {
+		return nil, err
+	}
+	sets, err := listWorkoutSets(db, `WHERE s.exercise_log_id = ?`, exerciseLogID)
+	if err != nil {
+		return nil, err
+	}
+	if len(sets) == 0 {
+		return nil, fmt.Errorf("exercise log %d has no workout sets", exerciseLogID)
+	}
+
+	out := &Workout{ExerciseLog: log}
+	index := map[string]int{}
+	for _, s := range sets {
+		i, ok := index[s.Lift]
+		if !ok {
+			i = len(out.Lifts)
+			index[s.Lift] = i
+			out.Lifts = append(out.Lifts, WorkoutLift{Lift: s.Lift, MuscleGroup: s.MuscleGroup})
+		}
+		lift := &out.Lifts[i]
+		volume := float64(s.Reps) * s.WeightKg
+		lift.Sets = append(lift.Sets, s.WorkoutSet)
+		lift.Reps += s.Reps
+		lift.VolumeKg = roundTo(lift.VolumeKg+volume, 2)
+		lift.BestE1RMKg = max(lift.BestE1RMKg, EstimatedOneRepMax(s.WeightKg, s.Reps, s.RPE))
+		out.Sets++
+		out.Reps += s.Reps
+		out.VolumeKg = roundTo(out.VolumeKg+volume, 2)
+	}
+	return out, nil
+}
+
+// ListWorkouts summarizes exercise logs that have workout sets, newest first.
+func ListWorkouts(db *sql.DB, f WorkoutFilter) ([]WorkoutSummary, error) {
+	query := `
+SELECT e.id, e.performed_at, e.exercise_type, e.calories_burned, COUNT(DISTINCT s.lift), COUNT(s.id), SUM(s.reps), SUM(s.reps * s.weight_kg)
+FROM exercise_logs e
+JOIN workout_sets s ON s.exercise_log_id = e.id
+WHERE 1=1`
+	args := make([]any, 0)
+	if strings.TrimSpace(f.FromDate) != "" {
+		from, err := parseDateStart(f.FromDate)
+		if err != nil {
+			return nil, err
+		}
+		query += ` AND e.performed_at >= ?`
+		args = append(args, from)
+	}
+	if strings.TrimSpace(f.ToDate) != "" {
+		to, err := parseDateEndExclusive(f.ToDate)
+		if err != nil {
+			return nil, err
+		}
+		query += ` AND e.performed_at < ?`
+		args = append(args, to)
+	}
+	if f.Limit <= 0 {
+		f.Limit = 50
+	}
+	query += ` GROUP BY e.id ORDER BY e.performed_at DESC LIMIT ?`
+	args = append(args, f.Limit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list workouts: %w", err)
+	}
+	defer rows.Close()
+	items := make([]WorkoutSummary, 0)
+	for rows.Next() {
+		var item WorkoutSummary
+		var performedAtRaw string
+		if err := rows.Scan(&item.ExerciseLogID, &performedAtRaw, &item.ExerciseType, &item.CaloriesBurned, &item.Lifts, &item.Sets, &item.Reps, &item.VolumeKg); err != nil {
+			return nil, fmt.Errorf("scan workout: %w", err)
+		}
+		performedAt, err := time.Parse(time.RFC3339, performedAtRaw)
+		if err != nil {
+			return nil, fmt.Errorf("parse performed_at: %w", err)
+		}
+		item.PerformedAt = performedAt
+		item.VolumeKg = roundTo(item.VolumeKg, 2)
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate workouts: %w", err)
+	}
+	return items, nil
+}
+
+// workoutSetRow is a set with the time of its exercise log.
+type workoutSetRow struct {
+	model.WorkoutSet
+	PerformedAt time.Time
+}
+
+func listWorkoutSets(db *sql.DB, where string, args ...any) ([]workoutSetRow, error) {
+	rows, err := db.Query(`
+SELECT s.id, s.exercise_log_id, s.position, s.lift, s.muscle_group, s.reps, s.weight_kg, s.rpe, s.created_at, e.performed_at
+FROM workout_sets s
+JOIN exercise_logs e ON e.id = s.exercise_log_id
+`+where+`
+ORDER BY e.performed_at ASC, s.exercise_log_id ASC, s.position ASC
+`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list workout sets: %w", err)
+	}
+	defer rows.Close()
+	items := make([]workoutSetRow, 0)
+	for rows.Next() {
+		var item workoutSetRow
+		var rpe sql.NullFloat64
+		var performedAtRaw string
+		if err := rows.Scan(&item.ID, &item.ExerciseLogID, &item.Position, &item.Lift, &item.MuscleGroup, &item.Reps, &item.WeightKg, &rpe, &item.CreatedAt, &performedAtRaw); err != nil {
+			return nil, fmt.Errorf("scan workout set: %w", err)
+		}
+		if rpe.Valid {
+			v := rpe.Float64
+			item.RPE = &v
+		}
+		performedAt, err := time.Parse(time.RFC3339, performedAtRaw)
+		if err != nil {
+			return nil, fmt.Errorf("parse performed_at: %w", err)
+		}
+		item.PerformedAt = performedAt
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate workout sets: %w", err)
+	}
+	return items, nil
+}
+
+// StrengthSummary reports training volume and estimated 1RM progress for a
+// date range.
+type StrengthSummary struct {
+	Sessions     int                 `json:"sessions"`
+	Sets         int                 `json:"sets"`
+	Reps         int                 `json:"reps"`
+	VolumeKg     float64             `json:"volume_kg"`
+	MuscleGroups []MuscleGroupVolume `json:"muscle_groups,omitempty"`
+	Lifts        []LiftProgress      `json:"lifts,omitempty"`
+}
+
+type MuscleGroupVolume struct {
+	MuscleGroup string  `json:"muscle_group"`
+	Sets        int     `json:"sets"`
+	Reps        int     `json:"reps"`
+	VolumeKg    float64 `json:"volume_kg"`
+	WeeklySets  float64 `json:"weekly_sets"`
+}
+
+// LiftProgress compares the best estimated 1RM of a lift's first and last
+// sessions in the range. Bodyweight-only lifts have no e1RM.
+type LiftProgress struct {
+	Lift        string  `json:"lift"`
+	MuscleGroup string  `json:"muscle_group"`
+	Sessions    int     `json:"sessions"`
+	StartE1RMKg float64 `json:"start_e1rm_kg"`
+	EndE1RMKg   float64 `json:"end_e1rm_kg"`
+	BestE1RMKg  float64 `json:"best_e1rm_kg"`
+	ChangeKg    float64 `json:"change_kg"`
+}
+
+func calculateStrengthSummary(db *sql.DB, from, to time.Time) (StrengthSummary, error) {
+	sets, err := listWorkoutSets(db, `WHERE e.performed_at >= ? AND e.performed_at < ?`, from.Format(time.RFC3339), to.Add(24*time.Hour).Format(time.RFC3339))
+	if err != nil {
+		return StrengthSummary{}, err
+	}
+	summary := StrengthSummary{}
+	if len(sets) == 0 {
+		return summary, nil
+	}
+
+	sessions := map[int64]bool{}
+	groups := map[string]*MuscleGroupVolume{}
+	lifts := map[string]*LiftProgress{}
+	// Best e1RM per lift and session, in session order.
+	type sessionBest struct {
+		logID int64
+		e1rm  float64
+	}
+	bests := map[string][]sessionBest{}
+	for _, s := range sets {
+		volume := float64(s.Reps) * s.WeightKg
+		sessions[s.ExerciseLogID] = true
+		summary.Sets++
+		summary.Reps += s.Reps
+		summary.VolumeKg += volume
+
+		g, ok := groups[s.MuscleGroup]
+		if !ok {
+			g = &MuscleGroupVolume{MuscleGroup: s.MuscleGroup}
+			groups[s.MuscleGroup] = g
+		}
+		g.Sets++
+		g.Reps += s.Reps
+		g.VolumeKg += volume
+
+		e1rm := EstimatedOneRepMax(s.WeightKg, s.Reps, s.RPE)
+		if e1rm == 0 {
+			continue
+		}
+		if _, ok := lifts[s.Lift]; !ok {
+			lifts[s.Lift] = &LiftProgress{Lift: s.Lift, MuscleGroup: s.MuscleGroup}
+		}
+		history := bests[s.Lift]
+		if n := len(history); n > 0 && history[n-1].logID == s.ExerciseLogID {
+			history[n-1].e1rm = max(history[n-1].e1rm, e1rm)
+		} else {
+			history = append(history, sessionBest{logID: s.ExerciseLogID, e1rm: e1rm})
+		}
+		bests[s.Lift] = history
+	}
+
+	summary.Sessions = len(sessions)
+	summary.VolumeKg = roundTo(summary.VolumeKg, 2)
+	weeks := max(to.Sub(from).Hours()/24+1, 7) / 7
+	for _, g := range groups {
+		g.VolumeKg = roundTo(g.VolumeKg, 2)
+		g.WeeklySets = roundTo(float64(g.Sets)/weeks, 1)
+		summary.MuscleGroups = append(summary.MuscleGroups, *g)
+	}
+	slices.SortFunc(summary.MuscleGroups, func(a, b MuscleGroupVolume) int {
+		if a.VolumeKg != b.VolumeKg {
+			if a.VolumeKg > b.VolumeKg {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.MuscleGroup, b.MuscleGroup)
+	})
+	for name, p := range lifts {
+		history := bests[name]
+		p.Sessions = len(history)
+		p.StartE1RMKg = history[0].e1rm
+		p.EndE1RMKg = history[len(history)-1].e1rm
+		for _, h := range history {
+			p.BestE1RMKg = max(p.BestE1RMKg, h.e1rm)
+		}
+		p.ChangeKg = roundTo(p.EndE1RMKg-p.StartE1RMKg, 1)
+		summary.Lifts = append(summary.Lifts, *p)
+	}
+	slices.SortFunc(summary.Lifts, func(a, b LiftProgress) int { return strings.Compare(a.Lift, b.Lift) })
+	return summary, nil
+}
@@ -0,0 +1,125 @@
+package service_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/saadjs/kcal-cli/internal/service"
+)
+
+func TestParseWorkoutSet(t *testing.T) {
+	t.Parallel()
+
+	set, err := service.ParseWorkoutSet("Bench Press=3x8@80 rpe8", "kg")
+	if err != nil {
+		t.Fatalf("parse set: %v", err)
+	}
+	if set.Lift != "bench press" || set.Sets != 3 || set.Reps != 8 || set.WeightKg != 80 || set.RPE == nil || *set.RPE != 8 {
+		t.Fatalf("unexpected set %+v", set)
+	}
+	set, err = service.ParseWorkoutSet("squat=5@225lb", "kg")
+	if err != nil {
+		t.Fatalf("parse single lb set: %v", err)
+	}
+	if set.Sets != 1 || math.Abs(set.WeightKg-102.0582833) > 1e-6 {
+		t.Fatalf("expected one set of 225 lb, got %+v", set)
+	}
+	set, err = service.ParseWorkoutSet("pull-up=3x10", "lb")
+	if err != nil || set.WeightKg != 0 {
+		t.Fatalf("expected bodyweight set, got %+v err=%v", set, err)
+	}
+	for _, spec := range []string{"squat", "squat=5x5@100 hard", "squat=0x5", "squat=5x5@100 rpe11", "=3x5"} {
+		if _, err := service.ParseWorkoutSet(spec, "kg"); err == nil {
+			t.Fatalf("expected error for %q", spec)
+		}
+	}
+
+	rpe := 8.0
+	// Epley with two reps in reserve: 100 * (1 + 7/30).
+	if got := service.EstimatedOneRepMax(100, 5, &rpe); got != 123.3 {
+		t.Fatalf("expected e1RM 123.3, got %.1f", got)
+	}
+	if got := service.EstimatedOneRepMax(140, 1, nil); got != 140 {
+		t.Fatalf("expected single at RPE 10 to be its own e1RM, got %.1f", got)
+	}
+}
+
+func TestWorkoutsAndStrengthAnalytics(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+
+	day := time.Date(2026, 6, 1, 18, 0, 0, 0, time.Local)
+	first, err := service.AddWorkout(db, service.WorkoutInput{
+		Log: service.ExerciseLogInput{CaloriesBurned: 250, PerformedAt: day},
+		Sets: []service.WorkoutSetInput{
+			{Lift: "squat", Sets: 3, Reps: 5, WeightKg: 100},
+			{Lift: "pull-up", Sets: 2, Reps: 8},
+		},
+	})
+	if err != nil {
+		t.Fatalf("add workout: %v", err)
+	}
+	// Extra sets on an existing log continue its positions.
+	if _, err := service.AddWorkout(db, service.WorkoutInput{ExerciseLogID: first, Sets: []service.WorkoutSetInput{{Lift: "farmer carry", MuscleGroup: "Grip", Sets: 1, Reps: 1, WeightKg: 40}}}); err != nil {
+		t.Fatalf("add sets to existing log: %v", err)
+	}
+	if _, err := service.AddWorkout(db, service.WorkoutInput{
+		Log:  service.ExerciseLogInput{CaloriesBurned: 260, PerformedAt: day.AddDate(0, 0, 7)},
+		Sets: []service.WorkoutSetInput{{Lift: "squat", Sets: 3, Reps: 5, WeightKg: 110}},
+	}); err != nil {
+		t.Fatalf("add second workout: %v", err)
+	}
+	if _, err := service.AddWorkout(db, service.WorkoutInput{ExerciseLogID: 999, Sets: []service.WorkoutSetInput{{Lift: "squat", Sets: 1, Reps: 1}}}); err == nil {
+		t.Fatalf("expected missing exercise log error")
+	}
+
+	w, err := service.GetWorkout(db, first)
+	if err != nil {
+		t.Fatalf("get workout: %v", err)
+	}
+	if w.ExerciseLog.ExerciseType != service.DefaultWorkoutType || len(w.Lifts) != 3 || w.Sets != 6 || w.VolumeKg != 1540 {
+		t.Fatalf("unexpected workout %+v", w)
+	}
+	if w.Lifts[0].MuscleGroup != "legs" || w.Lifts[1].MuscleGroup != "back" || w.Lifts[2].MuscleGroup != "grip" || w.Lifts[2].Sets[0].Position != 6 {
+		t.Fatalf("unexpected lifts %+v", w.Lifts)
+	}
+
+	items, err := service.ListWorkouts(db, service.WorkoutFilter{FromDate: "2026-06-01", ToDate: "2026-06-07"})
+	if err != nil {
+		t.Fatalf("list workouts: %v", err)
+	}
+	if len(items) != 1 || items[0].ExerciseLogID != first || items[0].Lifts != 3 || items[0].Reps != 32 {
+		t.Fatalf("unexpected workout list %+v", items)
+	}
+
+	report, err := service.AnalyticsRange(db, day, day.AddDate(0, 0, 13), 0.10)
+	if err != nil {
+		t.Fatalf("analytics: %v", err)
+	}
+	s := report.Strength
+	if s.Sessions != 2 || s.Sets != 9 || s.VolumeKg != 3190 {
+		t.Fatalf("unexpected strength totals %+v", s)
+	}
+	if len(s.MuscleGroups) != 3 || s.MuscleGroups[0].MuscleGroup != "legs" || s.MuscleGroups[0].VolumeKg != 3150 || s.MuscleGroups[0].WeeklySets != 3 {
+		t.Fatalf("expected legs first with 3150 kg over two weeks, got %+v", s.MuscleGroups)
+	}
+	var squat *service.LiftProgress
+	for i := range s.Lifts {
+		if s.Lifts[i].Lift == "squat" {
+			squat = &s.Lifts[i]
+		}
+	}
+	// Bodyweight pull-ups have no e1RM.
+	if len(s.Lifts) != 2 || squat == nil || squat.StartE1RMKg != 116.7 || squat.EndE1RMKg != 128.3 || squat.ChangeKg != 11.6 || squat.Sessions != 2 {
+		t.Fatalf("unexpected lift progress %+v", s.Lifts)
+	}
+
+	if err := service.DeleteExerciseLog(db, first); err != nil {
+		t.Fatalf("delete exercise log: %v", err)
+	}
+	if _, err := service.GetWorkout(db, first); err == nil {
+		t.Fatalf("expected workout sets to be deleted with the exercise log")
+	}
+}
@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -98,3 +99,69 @@ func TestCLISavedFoodAndSavedMealFlow(t *testing.T) {
 		t.Fatalf("expected archived saved food in include-archived list, got:\n%s", stdout)
 	}
 }
+
+func TestCLISavedFoodSuggestAndCompletion(t *testing.T) {
+	binPath := buildKcalBinary(t)
+	dbPath := filepath.Join(t.TempDir(), "kcal.db")
+	initDB(t, binPath, dbPath)
+
+	for _, name := range []string{"Oatmeal", "Trail Mix"} {
+		_, stderr, exit := runKcal(t, binPath, dbPath, "saved-food", "add", "--name", name, "--calories", "250")
+		if exit != 0 {
+			t.Fatalf("saved-food add failed: exit=%d stderr=%s", exit, stderr)
+		}
+	}
+	_, stderr, exit := runKcal(t, binPath, dbPath, "saved-food", "log", "Trail Mix", "--category", "snacks")
+	if exit != 0 {
+		t.Fatalf("saved-food log failed: exit=%d stderr=%s", exit, stderr)
+	}
+
+	stdout, stderr, exit := runKcal(t, binPath, dbPath, "saved-food", "list", "--suggest")
+	if exit != 0 {
+		t.Fatalf("saved-food list --suggest failed: exit=%d stderr=%s", exit, stderr)
+	}
+	if !strings.Contains(stdout, "SCORE") || strings.Index(stdout, "Trail Mix") > strings.Index(stdout, "Oatmeal") {
+		t.Fatalf("expected Trail Mix ranked first, got:\n%s", stdout)
+	}
+
+	_, _, exit = runKcal(t, binPath, dbPath, "saved-food", "list", "--suggest", "--include-archived")
+	if exit == 0 {
+		t.Fatalf("expected --suggest with --include-archived to fail")
+	}
+
+	stdout, stderr, exit = runKcal(t, binPath, dbPath, "__complete", "saved-food", "log", "")
+	if exit != 0 {
+		t.Fatalf("completion failed: exit=%d stderr=%s", exit, stderr)
+	}
+	if !strings.Contains(stdout, "Trail Mix") || strings.Index(stdout, "Trail Mix") > strings.Index(stdout, "Oatmeal") {
+		t.Fatalf("expected ranked saved food completions, got:\n%s", stdout)
+	}
+}
+
+func TestCLISavedFoodAddFromLabel(t *testing.T) {
+	binPath := buildKcalBinary(t)
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "kcal.db")
+	initDB(t, binPath, dbPath)
+
+	labelPath := filepath.Join(dir, "label.txt")
+	label := "Nutrition declaration per 100 g\nEnergy 1850 kJ / 441 kcal\nFat 17 g\nCarbohydrate 63 g\nProtein 8,2 g\nSalt 0,5 g\n"
+	if err := os.WriteFile(labelPath, []byte(label), 0o644); err != nil {
+		t.Fatalf("write label: %v", err)
+	}
+	stdout, stderr, exit := runKcal(t, binPath, dbPath, "saved-food", "add-from-label", "--in", labelPath, "--name", "Cereal")
+	if exit != 0 {
+		t.Fatalf("saved-food add-from-label failed: exit=%d stderr=%s", exit, stderr)
+	}
+	if !strings.Contains(stdout, "441 kcal") || !strings.Contains(stdout, "sodium 200 mg") {
+		t.Fatalf("unexpected add-from-label output:\n%s", stdout)
+	}
+
+	stdout, stderr, exit = runKcal(t, binPath, dbPath, "saved-food", "show", "Cereal")
+	if exit != 0 {
+		t.Fatalf("saved-food show failed: exit=%d stderr=%s", exit, stderr)
+	}
+	if !strings.Contains(stdout, "Sodium: 200.0") || !strings.Contains(stdout, "Serving: 100.00 g") {
+		t.Fatalf("unexpected saved food from label:\n%s", stdout)
+	}
+}